@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// Exit codes of run --foreground besides 0 for a finished workflow.
+const (
+	exitFailed        = 1
+	exitBlocked       = 2
+	exitMaxIterations = 3
+	exitInterrupted   = 130
+)
+
+// interruptGrace is how long an interrupted foreground run waits for the
+// orchestrator to stop the current agent before the session is marked stopped.
+const interruptGrace = 10 * time.Second
+
+// exitCodeError makes Execute exit with code instead of 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// runForeground runs the workflow in this process with the same session
+// bookkeeping as a detached run: metadata, logs, routes and blocked state.
+// The returned error carries the exit code for the outcome.
+func runForeground(cfg *config.Config, cfgPath string, opts orchestrator.RunOptions, launchOpts launch.Options) error {
+	meta, err := launch.Foreground(cfg, cfgPath, launchOpts)
+	if err != nil {
+		return err
+	}
+	// Agents see the same environment and working directory as in a
+	// detached session.
+	if err := os.Setenv("SKILL_LOOP_SESSION_ID", meta.ID); err != nil {
+		return err
+	}
+	if err := os.Setenv("SKILL_LOOP_SESSION_REPO_ROOT", meta.RepoRoot); err != nil {
+		return err
+	}
+	if err := os.Chdir(meta.WorkingDir); err != nil {
+		return fmt.Errorf("enter working directory: %w", err)
+	}
+
+	logs, err := session.OpenLogs(meta.StdoutPath, meta.StderrPath)
+	if err != nil {
+		return err
+	}
+	stdout := io.MultiWriter(os.Stdout, logs.Stdout)
+	stderr := io.MultiWriter(os.Stderr, logs.Stderr)
+	fmt.Fprintf(stdout, "==> Session %s (logs: %s)\n", meta.ID, meta.StdoutPath)
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stopSignals()
+
+	opts.Executor = &defaultExecutor{}
+	opts.Observer = newSessionRunObserver(meta.RepoRoot, meta.ID)
+	opts.Context = ctx
+	opts.Output = stdout
+	opts.ErrOutput = stderr
+	done := make(chan error, 1)
+	go func() {
+		done <- runRecorded(cfg, cfgPath, launchOpts.Record, opts, launchOpts.Vars)
+	}()
+
+	var runErr error
+	interrupted := false
+	select {
+	case runErr = <-done:
+	case <-ctx.Done():
+		interrupted = true
+		fmt.Fprintln(stderr, "==> Interrupted; stopping the current agent.")
+		select {
+		case <-done:
+		case <-time.After(interruptGrace):
+		}
+	}
+
+	code, outcome := foregroundOutcome(meta, runErr, interrupted)
+	if err := finishForegroundSession(meta, runErr, interrupted, code); err != nil {
+		fmt.Fprintf(stderr, "[skill-loop] %v\n", err)
+	}
+	if err := logs.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "[skill-loop] close logs: %v\n", err)
+	}
+	if outcome == nil {
+		return nil
+	}
+	return &exitCodeError{code: code, err: outcome}
+}
+
+// foregroundOutcome maps the result of a foreground run to its exit code and
+// the error reported to the user.
+func foregroundOutcome(meta *session.Metadata, runErr error, interrupted bool) (int, error) {
+	var blocked *orchestrator.BlockedError
+	var maxErr *orchestrator.MaxIterationsError
+	switch {
+	case interrupted:
+		return exitInterrupted, fmt.Errorf("run interrupted")
+	case runErr == nil:
+		return 0, nil
+	case errors.As(runErr, &blocked):
+		return exitBlocked, fmt.Errorf("%w; resume with: skill-loop sessions resume %s", blocked, meta.ID)
+	case errors.As(runErr, &maxErr):
+		return exitMaxIterations, runErr
+	default:
+		return exitFailed, runErr
+	}
+}
+
+// finishForegroundSession records the final status and exit code of a
+// foreground session.
+func finishForegroundSession(meta *session.Metadata, runErr error, interrupted bool, code int) error {
+	var blocked *orchestrator.BlockedError
+	if !interrupted && errors.As(runErr, &blocked) {
+		if err := persistBlockedSession(blocked); err != nil {
+			return err
+		}
+	} else {
+		current, err := session.LoadByID(meta.RepoRoot, meta.ID)
+		if err != nil {
+			return fmt.Errorf("persist foreground session: %w", err)
+		}
+		now := time.Now().UTC()
+		current.EndedAt = &now
+		switch {
+		case interrupted:
+			current.Status = session.StatusStopped
+			current.LastError = "interrupted"
+		case runErr != nil:
+			current.Status = session.StatusFailed
+			current.LastError = runErr.Error()
+		default:
+			current.Status = session.StatusDone
+			current.LastError = ""
+		}
+		if err := session.Save(current); err != nil {
+			return fmt.Errorf("persist foreground session: %w", err)
+		}
+	}
+	if err := os.WriteFile(meta.ExitCodePath, []byte(strconv.Itoa(code)+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write exit code: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+func TestForegroundOutcome(t *testing.T) {
+	meta := &session.Metadata{ID: "20260101T000000Z-abcd1234"}
+	tests := []struct {
+		name        string
+		runErr      error
+		interrupted bool
+		wantCode    int
+		wantErr     string
+	}{
+		{name: "done", wantCode: 0},
+		{name: "failed", runErr: errors.New(`skill "impl" failed`), wantCode: exitFailed, wantErr: `skill "impl" failed`},
+		{
+			name:     "blocked",
+			runErr:   fmt.Errorf("run: %w", &orchestrator.BlockedError{RouteID: "ask", Reason: "needs approval"}),
+			wantCode: exitBlocked,
+			wantErr:  `workflow blocked on route "ask": needs approval; resume with: skill-loop sessions resume 20260101T000000Z-abcd1234`,
+		},
+		{name: "max iterations", runErr: &orchestrator.MaxIterationsError{MaxIterations: 5}, wantCode: exitMaxIterations, wantErr: "max iterations (5) reached"},
+		{name: "interrupted", runErr: errors.New("context canceled"), interrupted: true, wantCode: exitInterrupted, wantErr: "run interrupted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := foregroundOutcome(meta, tt.runErr, tt.interrupted)
+			if code != tt.wantCode {
+				t.Fatalf("foregroundOutcome() code = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("foregroundOutcome() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("foregroundOutcome() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
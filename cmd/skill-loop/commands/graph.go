@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/graph"
+)
+
+func NewGraphCmd() *cobra.Command {
+	var format string
+	var entrypoint string
+	var sessionID string
+
+	cmd := &cobra.Command{
+		Use:   "graph [config.yml]",
+		Short: "Render the skill/route graph of a workflow",
+		Long: `Render the skills and routes of a workflow as a Mermaid flowchart, a Graphviz
+DOT digraph or plain text. Done routes lead to a done node, blocked routes are
+drawn dashed and route criteria label the edges.
+
+With --session the graph highlights the skill the session is running and the
+routes it has taken, using the session's config unless one is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var highlight *graph.Highlight
+			var cfgPath string
+			if sessionID != "" {
+				meta, err := loadRunSessionByID(sessionID)
+				if err != nil {
+					return err
+				}
+				h := graph.SessionHighlight(meta)
+				highlight = &h
+				cfgPath = meta.ConfigPath
+			}
+			if len(args) > 0 || cfgPath == "" {
+				var err error
+				if cfgPath, err = resolveConfigPath(args); err != nil {
+					return err
+				}
+			}
+
+			cfg, err := config.Load(cfgPath)
+			if err != nil {
+				return err
+			}
+			if entrypoint != "" {
+				if err := cfg.ValidateEntrypoint(entrypoint); err != nil {
+					return err
+				}
+			}
+
+			g := graph.Build(cfg, cfg.EffectiveName(cfgPath), entrypoint)
+			if highlight != nil {
+				g.Apply(*highlight)
+			}
+			out, err := graph.Render(g, strings.ToLower(strings.TrimSpace(format)))
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", graph.FormatASCII, "Output format: "+strings.Join(graph.Formats, ", "))
+	cmd.Flags().StringVarP(&entrypoint, "entrypoint", "e", "", "Start the graph at this skill instead of default_entrypoint")
+	cmd.Flags().StringVar(&sessionID, "session", "", "Highlight the current skill and traversed routes of this session")
+
+	return cmd
+}
@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// logFollowInterval is how often followed logs are checked for new output.
+var logFollowInterval = 500 * time.Millisecond
+
+// followedLog is one log file being followed. offset counts the bytes read so
+// far and partial holds a trailing line that has not been terminated yet.
+type followedLog struct {
+	label   string
+	path    string
+	offset  int64
+	partial []byte
+}
+
+// logFollower prints new output from one or both session logs as it is
+// written, with a marker line wherever an orchestrator iteration started.
+// Iteration markers are placed using the first followed log.
+type logFollower struct {
+	w          io.Writer
+	logs       []*followedLog
+	marksPath  string
+	nextMark   int
+	labelLines bool
+}
+
+func newLogFollower(w io.Writer, stdoutPath string, stderrPath string, stdout bool, stderr bool, since time.Time, tail int) (*logFollower, error) {
+	marks, err := session.LoadIterations(stdoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &logFollower{w: w, marksPath: stdoutPath, labelLines: stdout && stderr}
+	if stdout {
+		f.logs = append(f.logs, &followedLog{label: "stdout", path: stdoutPath})
+	}
+	if stderr {
+		f.logs = append(f.logs, &followedLog{label: "stderr", path: stderrPath})
+	}
+
+	for _, log := range f.logs {
+		from, err := logStartOffset(log.path, marks, log.label, since)
+		if err != nil {
+			return nil, err
+		}
+		if log.offset, err = tailStartOffset(log.path, from, tail); err != nil {
+			return nil, err
+		}
+	}
+
+	// Markers for iterations that began before the starting point were
+	// already passed.
+	primary := f.logs[0]
+	for f.nextMark < len(marks) && markOffset(marks[f.nextMark], primary.label) < primary.offset {
+		f.nextMark++
+	}
+	return f, nil
+}
+
+// follow prints new output until ctx is cancelled or finished reports true,
+// then prints whatever was written in the meantime and returns.
+func (f *logFollower) follow(ctx context.Context, finished func() (bool, error)) error {
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := finished()
+		if err != nil {
+			return err
+		}
+		if err := f.poll(done); err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll prints the complete lines written since the previous poll. The final
+// poll also prints unterminated lines and any remaining iteration markers.
+func (f *logFollower) poll(final bool) error {
+	marks, err := session.LoadIterations(f.marksPath)
+	if err != nil {
+		return err
+	}
+
+	for i, log := range f.logs {
+		lineStart := log.offset - int64(len(log.partial))
+		data, truncated, err := log.read()
+		if err != nil {
+			return err
+		}
+		if truncated {
+			lineStart = 0
+		}
+
+		data = append(log.partial, data...)
+		log.partial = nil
+		for len(data) > 0 {
+			end := bytes.IndexByte(data, '\n')
+			if end < 0 {
+				if !final {
+					log.partial = data
+					break
+				}
+				end = len(data) - 1
+			}
+			if i == 0 {
+				if err := f.printMarks(marks, log.label, lineStart); err != nil {
+					return err
+				}
+			}
+			if err := f.printLine(log.label, data[:end+1]); err != nil {
+				return err
+			}
+			lineStart += int64(end + 1)
+			data = data[end+1:]
+		}
+	}
+
+	if final && len(f.logs) > 0 {
+		return f.printMarks(marks, f.logs[0].label, f.logs[0].offset)
+	}
+	return nil
+}
+
+// read returns the bytes appended to the log since the previous read. A log
+// that shrank was truncated or replaced and is read again from the start,
+// which read reports so that partial output is discarded.
+func (l *followedLog) read() ([]byte, bool, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, false, err
+	}
+	truncated := size < l.offset
+	if truncated {
+		l.offset = 0
+		l.partial = nil
+	}
+	if size == l.offset {
+		return nil, truncated, nil
+	}
+	if _, err := file.Seek(l.offset, io.SeekStart); err != nil {
+		return nil, truncated, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, size-l.offset))
+	if err != nil {
+		return nil, truncated, err
+	}
+	l.offset += int64(len(data))
+	return data, truncated, nil
+}
+
+func (f *logFollower) printMarks(marks []session.IterationMark, label string, offset int64) error {
+	for f.nextMark < len(marks) && markOffset(marks[f.nextMark], label) <= offset {
+		if _, err := fmt.Fprintln(f.w, formatIterationMark(marks[f.nextMark])); err != nil {
+			return err
+		}
+		f.nextMark++
+	}
+	return nil
+}
+
+func (f *logFollower) printLine(label string, line []byte) error {
+	if f.labelLines {
+		if _, err := fmt.Fprintf(f.w, "[%s] ", label); err != nil {
+			return err
+		}
+	}
+	if _, err := f.w.Write(line); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		_, err := fmt.Fprintln(f.w)
+		return err
+	}
+	return nil
+}
+
+func formatIterationMark(mark session.IterationMark) string {
+	iteration := fmt.Sprintf("%d", mark.Iteration)
+	if mark.MaxIterations > 0 {
+		iteration = fmt.Sprintf("%d/%d", mark.Iteration, mark.MaxIterations)
+	}
+	return fmt.Sprintf("--- iteration %s: %s (started %s) ---", iteration, mark.Skill, mark.StartedAt.Local().Format(time.DateTime))
+}
+
+func markOffset(mark session.IterationMark, label string) int64 {
+	if label == "stderr" {
+		return mark.StderrOffset
+	}
+	return mark.StdoutOffset
+}
+
+// logStartOffset returns where reading a log begins for --since. Log lines
+// carry no timestamps, so the iteration marks decide: reading starts at the
+// iteration that was running at that time, or at the end of a log that has
+// not been written to since.
+func logStartOffset(path string, marks []session.IterationMark, label string, since time.Time) (int64, error) {
+	if since.IsZero() {
+		return 0, nil
+	}
+
+	if len(marks) > 0 {
+		var from int64
+		for _, mark := range marks {
+			if mark.StartedAt.After(since) {
+				break
+			}
+			from = markOffset(mark, label)
+		}
+		return from, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if info.ModTime().Before(since) {
+		return info.Size(), nil
+	}
+	return 0, nil
+}
+
+// tailStartOffset returns where the last tail lines after from begin. A tail
+// of zero keeps from. Only the end of the log is read.
+func tailStartOffset(path string, from int64, tail int) (int64, error) {
+	if tail <= 0 {
+		return from, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return from, nil
+		}
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	return session.TailOffset(file, from, tail)
+}
+
+// readLogRange returns the contents of path from the given byte offset on,
+// without reading what comes before it. A missing file reads as empty.
+func readLogRange(path string, from int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.Seek(from, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(file)
+}
+
+// parseSince accepts a duration before now, such as 15m, an RFC 3339
+// timestamp or a date.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	t, err := session.ParseTime(value, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: %w", value, err)
+	}
+	return t, nil
+}
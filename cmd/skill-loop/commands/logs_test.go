@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+func TestLogFollowerPrintsNewLinesWithIterationMarks(t *testing.T) {
+	dir := t.TempDir()
+	stdoutPath := filepath.Join(dir, "stdout.log")
+	stderrPath := filepath.Join(dir, "stderr.log")
+	writeLog(t, stdoutPath, "setup\n")
+
+	var out bytes.Buffer
+	follower, err := newLogFollower(&out, stdoutPath, stderrPath, true, false, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("newLogFollower() error: %v", err)
+	}
+
+	if err := session.RecordIteration(stdoutPath, stderrPath, 1, 3, "impl"); err != nil {
+		t.Fatalf("RecordIteration() error: %v", err)
+	}
+	appendLog(t, stdoutPath, "working\npartial")
+	if err := follower.poll(false); err != nil {
+		t.Fatalf("poll() error: %v", err)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 4 || lines[0] != "setup" || !strings.HasPrefix(lines[1], "--- iteration 1/3: impl (started ") || lines[2] != "working" || lines[3] != "" {
+		t.Fatalf("output = %q, want setup, iteration marker, working", out.String())
+	}
+
+	out.Reset()
+	appendLog(t, stdoutPath, " line\n")
+	if err := follower.poll(true); err != nil {
+		t.Fatalf("poll() error: %v", err)
+	}
+	if got := out.String(); got != "partial line\n" {
+		t.Fatalf("output = %q, want completed partial line", got)
+	}
+}
+
+func TestLogFollowerLabelsBothStreams(t *testing.T) {
+	dir := t.TempDir()
+	stdoutPath := filepath.Join(dir, "stdout.log")
+	stderrPath := filepath.Join(dir, "stderr.log")
+	writeLog(t, stdoutPath, "one\ntwo\nthree\n")
+	writeLog(t, stderrPath, "oops")
+
+	var out bytes.Buffer
+	follower, err := newLogFollower(&out, stdoutPath, stderrPath, true, true, time.Time{}, 1)
+	if err != nil {
+		t.Fatalf("newLogFollower() error: %v", err)
+	}
+	if err := follower.poll(true); err != nil {
+		t.Fatalf("poll() error: %v", err)
+	}
+
+	want := "[stdout] three\n[stderr] oops\n"
+	if got := out.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestLogStartOffsetUsesIterationMarks(t *testing.T) {
+	start := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	marks := []session.IterationMark{
+		{Iteration: 1, StartedAt: start, StdoutOffset: 0, StderrOffset: 0},
+		{Iteration: 2, StartedAt: start.Add(10 * time.Minute), StdoutOffset: 120, StderrOffset: 7},
+		{Iteration: 3, StartedAt: start.Add(20 * time.Minute), StdoutOffset: 300, StderrOffset: 9},
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		label string
+		want  int64
+	}{
+		{name: "before first iteration", since: start.Add(-time.Hour), label: "stdout", want: 0},
+		{name: "during second iteration", since: start.Add(15 * time.Minute), label: "stdout", want: 120},
+		{name: "stderr offset", since: start.Add(15 * time.Minute), label: "stderr", want: 7},
+		{name: "after last iteration", since: start.Add(time.Hour), label: "stdout", want: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logStartOffset("unused.log", marks, tt.label, tt.since)
+			if err != nil {
+				t.Fatalf("logStartOffset() error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("logStartOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+
+	got, err := parseSince("15m", now)
+	if err != nil || !got.Equal(now.Add(-15*time.Minute)) {
+		t.Fatalf("parseSince(15m) = %v, %v, want 15 minutes ago", got, err)
+	}
+	got, err = parseSince("2026-03-07T08:00:00Z", now)
+	if err != nil || !got.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("parseSince(RFC 3339) = %v, %v, want 08:00 UTC", got, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Fatal("parseSince(yesterday) error = nil, want error")
+	}
+}
+
+func writeLog(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+}
+
+func appendLog(t *testing.T, path string, content string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("append log: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("close log: %v", err)
+	}
+}
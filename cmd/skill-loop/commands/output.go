@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the sessions subcommands. JSON and YAML print the
+// sessionui schema, the one the dashboard API serves.
+const (
+	outputText = "text"
+	outputWide = "wide"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputOptions holds the --output and --format flags of a sessions
+// subcommand.
+type outputOptions struct {
+	output string
+	format string
+	tmpl   *template.Template
+}
+
+func addOutputFlags(cmd *cobra.Command, opts *outputOptions, wide bool, formatUsage string) {
+	usage := "Output format: text, json or yaml"
+	if wide {
+		usage = "Output format: text, wide, json or yaml"
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", outputText, usage)
+	cmd.Flags().StringVar(&opts.format, "format", "", formatUsage)
+}
+
+// validate normalizes the flags and parses the --format template. It runs
+// before the command does anything, so a typo never prunes half the sessions.
+func (o *outputOptions) validate(wide bool) error {
+	o.output = strings.ToLower(strings.TrimSpace(o.output))
+	switch o.output {
+	case "":
+		o.output = outputText
+	case outputText, outputJSON, outputYAML:
+	case outputWide:
+		if !wide {
+			return fmt.Errorf("output format %q is not supported by this command (want %s, %s or %s)", o.output, outputText, outputJSON, outputYAML)
+		}
+	default:
+		return fmt.Errorf("unknown output format %q (want %s, %s, %s or %s)", o.output, outputText, outputWide, outputJSON, outputYAML)
+	}
+
+	if o.format == "" {
+		return nil
+	}
+	if o.output != outputText {
+		return fmt.Errorf("--format cannot be combined with --output %s", o.output)
+	}
+	tmpl, err := template.New("format").Funcs(template.FuncMap{"json": templateJSON}).Parse(o.format)
+	if err != nil {
+		return fmt.Errorf("parse --format template: %w", err)
+	}
+	o.tmpl = tmpl
+	return nil
+}
+
+// structured reports whether the command prints JSON or YAML.
+func (o *outputOptions) structured() bool {
+	return o.output == outputJSON || o.output == outputYAML
+}
+
+// write prints value as JSON or YAML.
+func (o *outputOptions) write(w io.Writer, value any) error {
+	if o.output == outputYAML {
+		return writeYAML(w, value)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// execute prints each item with the --format template, one per line.
+func (o *outputOptions) execute(w io.Writer, item any) error {
+	if err := o.tmpl.Execute(w, item); err != nil {
+		return fmt.Errorf("execute --format template: %w", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// writeYAML prints value as YAML using the keys of its JSON encoding, so both
+// formats share one schema.
+func writeYAML(w io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow and quoting styles the JSON input carries, so the
+// encoder picks the usual YAML layout.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func templateJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
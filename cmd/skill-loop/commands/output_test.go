@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    outputOptions
+		wide    bool
+		want    string
+		wantErr string
+	}{
+		{name: "default text", want: outputText},
+		{name: "normalizes case", opts: outputOptions{output: " JSON "}, want: outputJSON},
+		{name: "wide", opts: outputOptions{output: "wide"}, wide: true, want: outputWide},
+		{name: "wide unsupported", opts: outputOptions{output: "wide"}, wantErr: "not supported"},
+		{name: "unknown", opts: outputOptions{output: "xml"}, wide: true, wantErr: "unknown output format"},
+		{name: "format with json", opts: outputOptions{output: "json", format: "{{.ID}}"}, wantErr: "cannot be combined"},
+		{name: "bad template", opts: outputOptions{format: "{{.ID"}, wantErr: "parse --format template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.validate(tt.wide)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validate() error: %v", err)
+			}
+			if opts.output != tt.want {
+				t.Fatalf("output = %q, want %q", opts.output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteYAMLUsesJSONKeys(t *testing.T) {
+	value := struct {
+		SessionID string   `json:"sessionId"`
+		Content   string   `json:"content"`
+		Command   []string `json:"command"`
+		Count     int      `json:"count,omitempty"`
+	}{
+		SessionID: "run-1",
+		Content:   "line one\nline two",
+		Command:   []string{"skill-loop", "run"},
+	}
+
+	var b strings.Builder
+	if err := writeYAML(&b, value); err != nil {
+		t.Fatalf("writeYAML() error: %v", err)
+	}
+
+	want := "sessionId: run-1\ncontent: |-\n  line one\n  line two\ncommand:\n  - skill-loop\n  - run\n"
+	if got := b.String(); got != want {
+		t.Fatalf("writeYAML() = %q, want %q", got, want)
+	}
+}
+
+func TestOutputOptionsExecuteJSONFunc(t *testing.T) {
+	opts := outputOptions{format: "{{.Name}} {{json .Tags}}"}
+	if err := opts.validate(false); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+
+	var b strings.Builder
+	item := struct {
+		Name string
+		Tags []string
+	}{Name: "a", Tags: []string{"x", "y"}}
+	if err := opts.execute(&b, item); err != nil {
+		t.Fatalf("execute() error: %v", err)
+	}
+	if got, want := b.String(), "a [\"x\",\"y\"]\n"; got != want {
+		t.Fatalf("execute() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -19,6 +20,11 @@ where each skill can delegate to the next based on its output.`,
 
 	cmd.AddCommand(NewRunCmd())
 	cmd.AddCommand(NewSessionsCmd())
+	cmd.AddCommand(NewScheduleCmd())
+	cmd.AddCommand(NewGraphCmd())
+	cmd.AddCommand(NewValidateCmd())
+	cmd.AddCommand(NewTestCmd())
+	cmd.AddCommand(NewServeTriggersCmd())
 	cmd.AddCommand(NewSchemaCmd())
 	cmd.AddCommand(NewVersionCmd())
 
@@ -28,6 +34,10 @@ where each skill can delegate to the next based on its output.`,
 func Execute() {
 	if err := NewRootCmd().Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
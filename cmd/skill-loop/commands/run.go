@@ -4,18 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 
+	"github.com/takumiyoshikawa/skill-loop/internal/cassette"
 	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/dryrun"
 	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
 	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
 	"github.com/takumiyoshikawa/skill-loop/internal/scheduler"
 	"github.com/takumiyoshikawa/skill-loop/internal/session"
@@ -28,6 +27,12 @@ func NewRunCmd() *cobra.Command {
 	var prompt string
 	var entrypoint string
 	var attach bool
+	var varAssignments []string
+	var dryRun bool
+	var fixturesPath string
+	var recordPath string
+	var foreground bool
+	var backend string
 
 	cmd := &cobra.Command{
 		Use:   "run [config.yml]",
@@ -35,7 +40,27 @@ func NewRunCmd() *cobra.Command {
 		Long: `Run a skill loop from a config file.
 
 By default, this starts the orchestrator in a detached tmux session and returns immediately.
-Use --attach to attach to that tmux session immediately.`,
+Use --attach to attach to that tmux session immediately. Where tmux is not
+installed, or with --backend process, the orchestrator runs as a plain
+detached process instead; follow it with "skill-loop sessions logs -f".
+
+Use --foreground to run the orchestrator in this process instead, for example
+in CI containers without tmux. The run is still recorded as a session with
+metadata and logs, and the exit code tells how it ended:
+
+  0    the workflow finished on a done route
+  1    the run failed
+  2    the workflow is blocked (resume it with skill-loop sessions resume)
+  3    max iterations were reached
+  130  the run was interrupted
+
+Use --dry-run to walk the workflow in the foreground without calling any agent.
+The prompts each skill and the router would receive are printed, and skill
+outputs and route choices come from --fixtures or are entered at the terminal.
+
+Use --record to save every agent prompt and response of the run, with the
+routes it selected and how it ended, into a cassette file that
+"skill-loop test" replays.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfgPath, err := resolveConfigPath(args)
@@ -43,11 +68,34 @@ Use --attach to attach to that tmux session immediately.`,
 				return err
 			}
 
-			cfg, err := config.Load(cfgPath)
+			vars, err := config.ParseVarAssignments(varAssignments)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadWithOptions(cfgPath, config.LoadOptions{Vars: vars})
 			if err != nil {
 				return err
 			}
 
+			if fixturesPath != "" && !dryRun {
+				return fmt.Errorf("--fixtures requires --dry-run")
+			}
+			if recordPath != "" {
+				if recordPath, err = filepath.Abs(recordPath); err != nil {
+					return fmt.Errorf("resolve cassette path: %w", err)
+				}
+			}
+			runOpts := orchestrator.RunOptions{MaxIterations: maxIterations, Prompt: prompt, Entrypoint: entrypoint}
+			if foreground && (attach || dryRun) {
+				return fmt.Errorf("--foreground cannot be combined with --attach or --dry-run")
+			}
+			if dryRun {
+				if attach {
+					return fmt.Errorf("--attach cannot be combined with --dry-run")
+				}
+				return runDryRun(cmd, cfg, cfgPath, fixturesPath, recordPath, runOpts, vars)
+			}
+
 			if os.Getenv("SKILL_LOOP_SCHEDULE_CHILD") == "1" {
 				sessionID := os.Getenv("SKILL_LOOP_SESSION_ID")
 				if sessionID == "" {
@@ -65,14 +113,9 @@ Use --attach to attach to that tmux session immediately.`,
 
 			// Child mode for detached orchestrator process.
 			if os.Getenv("SKILL_LOOP_RUN_CHILD") == "1" {
-				runErr := orchestrator.RunWithObserver(
-					cfg,
-					maxIterations,
-					prompt,
-					entrypoint,
-					&defaultExecutor{},
-					newSessionRunObserver(os.Getenv("SKILL_LOOP_SESSION_REPO_ROOT"), os.Getenv("SKILL_LOOP_SESSION_ID")),
-				)
+				runOpts.Executor = &defaultExecutor{}
+				runOpts.Observer = newSessionRunObserver(os.Getenv("SKILL_LOOP_SESSION_REPO_ROOT"), os.Getenv("SKILL_LOOP_SESSION_ID"))
+				runErr := runRecorded(cfg, cfgPath, recordPath, runOpts, vars)
 				var blocked *orchestrator.BlockedError
 				if errors.As(runErr, &blocked) {
 					if err := persistBlockedSession(blocked); err != nil {
@@ -83,20 +126,40 @@ Use --attach to attach to that tmux session immediately.`,
 				return runErr
 			}
 
-			childArgs := buildRunChildArgs(cfgPath, maxIterations, prompt, entrypoint)
-			workflowName := cfg.EffectiveName(cfgPath)
-			var meta *session.Metadata
-			if cfg.Schedule != "" {
-				meta, err = startDetachedScheduledRun(cfg, cfgPath, workflowName, childArgs, maxIterations, entrypoint)
-			} else {
-				meta, err = startDetachedRun(cfgPath, workflowName, childArgs)
+			launchOpts := launch.Options{
+				MaxIterations: maxIterations,
+				Prompt:        prompt,
+				Entrypoint:    entrypoint,
+				Vars:          vars,
+				Record:        recordPath,
 			}
+			if foreground {
+				if backend != "" {
+					return fmt.Errorf("--backend cannot be combined with --foreground")
+				}
+				if cfg.Schedule != "" || len(cfg.WatchPatterns()) > 0 {
+					return fmt.Errorf("--foreground is not supported for scheduled or watch-triggered workflows")
+				}
+				return runForeground(cfg, cfgPath, runOpts, launchOpts)
+			}
+
+			if launchOpts.Backend, err = session.ResolveBackend(backend); err != nil {
+				return err
+			}
+			if attach && launchOpts.Backend != session.BackendTmux {
+				return fmt.Errorf("--attach requires the %s backend", session.BackendTmux)
+			}
+			meta, err := launch.Detached(cfg, cfgPath, launchOpts)
 			if err != nil {
 				return err
 			}
 
 			fmt.Printf("Started in background. run_id=%s\n", meta.ID)
-			fmt.Printf("Attach: skill-loop sessions attach %s\n", meta.ID)
+			if launchOpts.Backend == session.BackendTmux {
+				fmt.Printf("Attach: skill-loop sessions attach %s\n", meta.ID)
+			} else {
+				fmt.Printf("Follow: skill-loop sessions logs %s -f\n", meta.ID)
+			}
 			fmt.Printf("Session: %s\n", filepath.Dir(meta.ScriptPath))
 			fmt.Printf("Stdout: %s\n", meta.StdoutPath)
 			fmt.Printf("Stderr: %s\n", meta.StderrPath)
@@ -112,141 +175,78 @@ Use --attach to attach to that tmux session immediately.`,
 	cmd.Flags().StringVarP(&prompt, "prompt", "p", "", "Initial prompt passed to the first skill")
 	cmd.Flags().StringVarP(&entrypoint, "entrypoint", "e", "", "Skill to start from (overrides config default_entrypoint)")
 	cmd.Flags().BoolVar(&attach, "attach", false, "Attach to the detached run session immediately")
+	cmd.Flags().BoolVar(&foreground, "foreground", false, "Run in this process instead of a detached tmux session")
+	cmd.Flags().StringVar(&backend, "backend", "", "Session backend of the detached run: tmux or process (default: tmux when installed)")
+	cmd.Flags().StringArrayVar(&varAssignments, "var", nil, "Override a config variable as key=value (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Simulate the workflow in the foreground without calling agents")
+	cmd.Flags().StringVar(&fixturesPath, "fixtures", "", "YAML file with skill outputs and route choices for --dry-run")
+	cmd.Flags().StringVar(&recordPath, "record", "", "Record agent prompts and responses into a cassette file for skill-loop test")
 
 	return cmd
 }
 
-func resolveConfigPath(args []string) (string, error) {
-	cfgPath := defaultConfigFile
-	if len(args) > 0 {
-		cfgPath = args[0]
-	} else if _, err := os.Stat(defaultConfigFile); err != nil {
-		return "", fmt.Errorf("no config file specified and %s not found in current directory", defaultConfigFile)
-	}
-	absPath, err := filepath.Abs(cfgPath)
-	if err != nil {
-		return "", fmt.Errorf("resolve config path: %w", err)
-	}
-	cfgPath = absPath
-	return cfgPath, nil
-}
-
-func buildRunChildArgs(cfgPath string, maxIterations int, prompt string, entrypoint string) []string {
-	args := []string{"run", cfgPath}
-	if maxIterations > 0 {
-		args = append(args, "--max-iterations", strconv.Itoa(maxIterations))
-	}
-	if prompt != "" {
-		args = append(args, "--prompt", prompt)
-	}
-	if entrypoint != "" {
-		args = append(args, "--entrypoint", entrypoint)
-	}
-	return args
-}
-
-func startDetachedRun(cfgPath string, workflowName string, childArgs []string) (*session.Metadata, error) {
-	return startDetachedSession(cfgPath, workflowName, childArgs, map[string]string{
-		"SKILL_LOOP_RUN_CHILD": "1",
-	})
-}
-
-func startDetachedScheduledRun(cfg *config.Config, cfgPath string, workflowName string, childArgs []string, maxIterations int, entrypoint string) (*session.Metadata, error) {
-	meta, err := startDetachedSession(cfgPath, workflowName, childArgs, map[string]string{
-		"SKILL_LOOP_SCHEDULE_CHILD": "1",
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	effectiveMaxIterations := maxIterations
-	if effectiveMaxIterations <= 0 {
-		effectiveMaxIterations = cfg.MaxIterations
-	}
-	if effectiveMaxIterations <= 0 {
-		effectiveMaxIterations = orchestrator.DefaultMaxIterations
-	}
-
-	schedule, err := cron.ParseStandard(cfg.Schedule)
-	if err != nil {
-		return nil, fmt.Errorf("parse schedule: %w", err)
-	}
-	nextRun := schedule.Next(time.Now())
-
-	meta.Schedule = cfg.Schedule
-	meta.Status = session.StatusScheduled
-	meta.NextRun = &nextRun
-	meta.CurrentIteration = 0
-	meta.MaxIterations = effectiveMaxIterations
-	meta.CurrentSkill = ""
-	meta.LastError = ""
-	meta.EndedAt = nil
-	meta.ConfigPath = cfgPath
-
-	if err := session.Save(meta); err != nil {
-		return nil, fmt.Errorf("persist scheduled session metadata: %w", err)
-	}
-
-	return meta, nil
-}
-
-func startDetachedSession(cfgPath string, workflowName string, childArgs []string, childEnv map[string]string) (*session.Metadata, error) {
-	configDir := filepath.Dir(cfgPath)
-	if configDir == "" || configDir == "." {
+// runDryRun walks the workflow with simulated agents. Tracker actions are
+// skipped so that a dry run never changes issues.
+func runDryRun(cmd *cobra.Command, cfg *config.Config, cfgPath string, fixturesPath string, recordPath string, opts orchestrator.RunOptions, vars map[string]string) error {
+	var fixtures *dryrun.Fixtures
+	if fixturesPath != "" {
 		var err error
-		configDir, err = os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("get working directory: %w", err)
+		if fixtures, err = dryrun.LoadFixtures(fixturesPath); err != nil {
+			return err
 		}
 	}
-
-	repoRoot, err := session.ResolveRepoRoot(configDir)
-	if err != nil {
-		return nil, err
+	if cfg.Tracker != nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "==> Dry run: tracker context and actions are skipped.")
+		cfg.Tracker = nil
 	}
 
-	exePath, err := os.Executable()
-	if err != nil {
-		return nil, fmt.Errorf("resolve executable path: %w", err)
-	}
-	exePath, err = resolveDetachedBinary(exePath)
-	if err != nil {
-		return nil, err
+	opts.Executor = dryrun.New(fixtures, cmd.InOrStdin(), cmd.OutOrStdout())
+	opts.Output = cmd.OutOrStdout()
+	err := runRecorded(cfg, cfgPath, recordPath, opts, vars)
+	var blocked *orchestrator.BlockedError
+	if errors.As(err, &blocked) {
+		fmt.Fprintf(cmd.OutOrStdout(), "==> Dry run stopped: %v; a real run would resume at skill %s.\n", blocked, blocked.Skill)
+		return nil
 	}
+	return err
+}
 
-	command := append([]string{"env"}, envAssignments(childEnv)...)
-	command = append(command, exePath)
-	command = append(command, childArgs...)
-	workingDir := configDir
-
-	meta, err := session.New(repoRoot, workingDir, workflowName, "orchestrator", "skill-loop", command, 0, 0)
+// runRecorded runs the workflow, recording it into the cassette at
+// recordPath when one is given.
+func runRecorded(cfg *config.Config, cfgPath string, recordPath string, opts orchestrator.RunOptions, vars map[string]string) error {
+	if recordPath == "" {
+		return orchestrator.RunWithOptions(cfg, opts)
+	}
+	recorder, err := cassette.NewRecorder(recordPath, opts.Executor, cfgPath, opts, vars)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	meta.ConfigPath = cfgPath
-
-	if err := session.Start(meta); err != nil {
-		cleanupErr := os.RemoveAll(filepath.Dir(meta.ScriptPath))
-		if cleanupErr != nil {
-			return nil, fmt.Errorf("start detached run: %w (cleanup failed: %v)", err, cleanupErr)
+	opts.Executor = recorder
+	opts.Observer = orchestrator.Observers(opts.Observer, recorder)
+	runErr := orchestrator.RunWithOptions(cfg, opts)
+	if err := recorder.Finish(runErr); err != nil {
+		stderr := opts.ErrOutput
+		if stderr == nil {
+			stderr = os.Stderr
 		}
-		return nil, err
+		fmt.Fprintf(stderr, "[skill-loop] %v\n", err)
 	}
-
-	return meta, nil
+	return runErr
 }
 
-func envAssignments(env map[string]string) []string {
-	if len(env) == 0 {
-		return nil
+func resolveConfigPath(args []string) (string, error) {
+	cfgPath := defaultConfigFile
+	if len(args) > 0 {
+		cfgPath = args[0]
+	} else if _, err := os.Stat(defaultConfigFile); err != nil {
+		return "", fmt.Errorf("no config file specified and %s not found in current directory", defaultConfigFile)
 	}
-
-	assignments := make([]string, 0, len(env))
-	for key, value := range env {
-		assignments = append(assignments, key+"="+value)
+	absPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve config path: %w", err)
 	}
-	sort.Strings(assignments)
-	return assignments
+	cfgPath = absPath
+	return cfgPath, nil
 }
 
 type defaultExecutor struct{}
@@ -277,6 +277,7 @@ func (o *sessionRunObserver) IterationStarted(iteration int, maxIterations int,
 		meta.CurrentIteration = iteration
 		meta.MaxIterations = maxIterations
 		meta.CurrentSkill = skill
+		_ = session.RecordIteration(meta.StdoutPath, meta.StderrPath, iteration, maxIterations, skill)
 	})
 }
 
@@ -290,6 +291,12 @@ func (o *sessionRunObserver) SkillCompleted(iteration int, maxIterations int, sk
 	})
 }
 
+func (o *sessionRunObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	_ = o.update(func(meta *session.Metadata) {
+		meta.Routes = append(meta.Routes, session.RouteStep{Skill: skill, Route: route.ID})
+	})
+}
+
 func (o *sessionRunObserver) update(apply func(*session.Metadata)) error {
 	meta, err := session.LoadByID(o.repoRoot, o.sessionID)
 	if err != nil {
@@ -299,27 +306,6 @@ func (o *sessionRunObserver) update(apply func(*session.Metadata)) error {
 	return session.Save(meta)
 }
 
-func resolveDetachedBinary(exePath string) (string, error) {
-	exePath, err := filepath.Abs(exePath)
-	if err != nil {
-		return "", fmt.Errorf("resolve absolute executable path: %w", err)
-	}
-
-	if !strings.Contains(exePath, string(filepath.Separator)+"go-build") {
-		return exePath, nil
-	}
-
-	installedPath, err := exec.LookPath("skill-loop")
-	if err != nil {
-		return "", fmt.Errorf("detached runs require an installed skill-loop binary on PATH when launched via go run: %w", err)
-	}
-	installedPath, err = filepath.Abs(installedPath)
-	if err != nil {
-		return "", fmt.Errorf("resolve installed skill-loop path: %w", err)
-	}
-	return installedPath, nil
-}
-
 func persistBlockedSession(blocked *orchestrator.BlockedError) error {
 	sessionID := os.Getenv("SKILL_LOOP_SESSION_ID")
 	if sessionID == "" {
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+func NewScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Inspect workflow schedules",
+	}
+
+	cmd.AddCommand(newSchedulePreviewCmd())
+
+	return cmd
+}
+
+func newSchedulePreviewCmd() *cobra.Command {
+	var count int
+	var from string
+
+	cmd := &cobra.Command{
+		Use:   "preview [config.yml]",
+		Short: "Print the next fire times of a scheduled workflow",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgPath, err := resolveConfigPath(args)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.Load(cfgPath)
+			if err != nil {
+				return err
+			}
+			if cfg.Schedule == "" {
+				return fmt.Errorf("%s does not define a schedule", cfgPath)
+			}
+
+			start := time.Now()
+			if from != "" {
+				start, err = time.Parse(time.RFC3339, from)
+				if err != nil {
+					return fmt.Errorf("invalid --from %q: expected RFC3339 timestamp", from)
+				}
+			}
+
+			return printSchedulePreview(cmd.OutOrStdout(), cfg, start, count)
+		},
+	}
+
+	cmd.Flags().IntVarP(&count, "count", "n", 5, "Number of upcoming fire times to print")
+	cmd.Flags().StringVar(&from, "from", "", "Compute fire times after this RFC3339 timestamp instead of now")
+
+	return cmd
+}
+
+func printSchedulePreview(w io.Writer, cfg *config.Config, start time.Time, count int) error {
+	schedule, err := cfg.CronSchedule()
+	if err != nil {
+		return fmt.Errorf("parse schedule: %w", err)
+	}
+	if count <= 0 {
+		count = 5
+	}
+
+	details := []string{}
+	if cfg.Timezone != "" {
+		details = append(details, "timezone: "+cfg.Timezone)
+	}
+	if cfg.ScheduleJitterSeconds > 0 {
+		details = append(details, fmt.Sprintf("offset: %s of up to %s", cfg.ScheduleOffset(), time.Duration(cfg.ScheduleJitterSeconds)*time.Second))
+	}
+	header := "Schedule: " + cfg.Schedule
+	if len(details) > 0 {
+		header += " (" + strings.Join(details, ", ") + ")"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	loc := cfg.ScheduleLocation()
+	next := start
+	for i := 1; i <= count; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			_, err := fmt.Fprintln(w, "(no further fire times)")
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%3d  %s  (local: %s)\n", i, next.In(loc).Format("2006-01-02 15:04:05 MST"), next.Local().Format(time.DateTime)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
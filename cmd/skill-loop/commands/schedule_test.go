@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+func TestPrintSchedulePreview(t *testing.T) {
+	cfg := &config.Config{Schedule: "0 9 * * *", Timezone: "Asia/Tokyo"}
+
+	var b strings.Builder
+	if err := printSchedulePreview(&b, cfg, time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), 2); err != nil {
+		t.Fatalf("printSchedulePreview() error: %v", err)
+	}
+
+	got := b.String()
+	for _, want := range []string{
+		"Schedule: 0 9 * * * (timezone: Asia/Tokyo)",
+		"1  2026-03-08 09:00:00 JST",
+		"2  2026-03-09 09:00:00 JST",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("printSchedulePreview() missing %q\nfull output:\n%s", want, got)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -21,16 +22,20 @@ import (
 func NewSessionsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "sessions",
-		Short: "Manage tmux-backed run sessions",
+		Short: "Manage run sessions",
 	}
 
 	cmd.AddCommand(newSessionsLsCmd())
 	cmd.AddCommand(newSessionsShowCmd())
 	cmd.AddCommand(newSessionsInspectCmd())
 	cmd.AddCommand(newSessionsLogsCmd())
+	cmd.AddCommand(newSessionsRunsCmd())
 	cmd.AddCommand(newSessionsAttachCmd())
 	cmd.AddCommand(newSessionsStopCmd())
 	cmd.AddCommand(newSessionsResumeCmd())
+	cmd.AddCommand(newSessionsTriggerCmd())
+	cmd.AddCommand(newSessionsPauseCmd())
+	cmd.AddCommand(newSessionsUnpauseCmd())
 	cmd.AddCommand(newSessionsPruneCmd())
 
 	return cmd
@@ -39,59 +44,126 @@ func NewSessionsCmd() *cobra.Command {
 func newSessionsLsCmd() *cobra.Command {
 	var limit int
 	var offset int
+	var output outputOptions
+	var filters sessionFilterFlags
+	var allRepos bool
 
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List recorded run sessions in the current repository",
+		Long: `List recorded run sessions in the current repository, newest first.
+
+Filters combine: a session is listed when it matches every filter given.
+--since and --until bound the start time and take a duration before now
+(24h), an RFC 3339 time or a date (2026-03-07). --search matches the last
+skill output and the last error, ignoring case. --all-repos lists the
+sessions of every repository under ~/.local/share/skill-loop.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			repoRoot, err := session.ResolveRepoRoot("")
-			if err != nil {
+			if err := output.validate(true); err != nil {
 				return err
 			}
-
-			metas, total, err := listRunSessions(repoRoot, offset, limit)
+			filter, err := filters.filter(time.Now())
 			if err != nil {
 				return err
 			}
 
-			if len(metas) == 0 {
-				fmt.Println("No sessions found.")
-				return nil
+			repoRoot := ""
+			if !allRepos {
+				repoRoot, err = session.ResolveRepoRoot("")
+				if err != nil {
+					return err
+				}
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			if _, err := fmt.Fprintln(w, "ID\tSTATUS\tDETAILS\tCONFIG\tSTARTED"); err != nil {
+			metas, total, err := listRunSessions(repoRoot, filter, offset, limit)
+			if err != nil {
 				return err
 			}
+
+			list := sessionui.SessionList{
+				RepoRoot:  repoRoot,
+				AllRepos:  allRepos,
+				UpdatedAt: time.Now().UTC(),
+				Total:     total,
+				Sessions:  make([]sessionui.Session, 0, len(metas)),
+			}
 			for _, meta := range metas {
 				_ = session.Reconcile(meta)
-				if _, err := fmt.Fprintf(
-					w,
-					"%s\t%s\t%s\t%s\t%s\n",
-					meta.ID,
-					meta.Status,
-					formatSessionDetail(meta),
-					sessionConfigName(meta),
-					meta.StartedAt.Format(time.RFC3339),
-				); err != nil {
-					return err
-				}
+				list.Sessions = append(list.Sessions, sessionui.NewSession(meta))
 			}
-			if err := w.Flush(); err != nil {
+
+			if err := writeSessionList(os.Stdout, &output, list); err != nil {
 				return err
 			}
-
-			fmt.Fprintf(os.Stderr, "\nShowing %d-%d of %d sessions\n", offset+1, offset+len(metas), total)
+			if len(metas) > 0 && !output.structured() && output.tmpl == nil {
+				fmt.Fprintf(os.Stderr, "\nShowing %d-%d of %d sessions\n", offset+1, offset+len(metas), total)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of sessions to display")
 	cmd.Flags().IntVar(&offset, "offset", 0, "Number of sessions to skip")
+	filters.register(cmd)
+	cmd.Flags().BoolVar(&allRepos, "all-repos", false, "List the sessions of every repository, not just the current one")
+	addOutputFlags(cmd, &output, true, "Print each session with a Go template, e.g. '{{.ID}} {{.Status}}'")
 
 	return cmd
 }
 
+// writeSessionList prints a page of sessions as a table, or in the format
+// selected by output.
+func writeSessionList(w io.Writer, output *outputOptions, list sessionui.SessionList) error {
+	switch {
+	case output.structured():
+		return output.write(w, list)
+	case output.tmpl != nil:
+		for _, s := range list.Sessions {
+			if err := output.execute(w, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case len(list.Sessions) == 0:
+		_, err := fmt.Fprintln(w, "No sessions found.")
+		return err
+	}
+
+	columns := []string{"ID", "STATUS", "DETAILS", "CONFIG"}
+	if list.AllRepos {
+		columns = append(columns, "REPO")
+	}
+	wide := output.output == outputWide
+	if wide {
+		columns = append(columns, "BACKEND", "ITER", "SKILL", "PID")
+	}
+	columns = append(columns, "STARTED")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(columns, "\t")); err != nil {
+		return err
+	}
+	for _, s := range list.Sessions {
+		row := []string{s.ID, string(s.Status), s.Detail, s.ConfigName}
+		if list.AllRepos {
+			row = append(row, filepath.Base(s.RepoRoot))
+		}
+		if wide {
+			row = append(row,
+				s.Backend,
+				formatIterations(s.CurrentIteration, s.MaxIterations),
+				orDash(s.CurrentSkill),
+				formatPID(s.PID),
+			)
+		}
+		row = append(row, s.StartedAt.Format(time.RFC3339))
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
 func newSessionsShowCmd() *cobra.Command {
 	var host string
 	var port int
@@ -148,11 +220,17 @@ func newSessionsShowCmd() *cobra.Command {
 }
 
 func newSessionsInspectCmd() *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+
+	cmd := &cobra.Command{
 		Use:   "inspect <session-id>",
 		Short: "Show session metadata including log paths and last error",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(true); err != nil {
+				return err
+			}
+
 			meta, err := loadRunSessionByID(args[0])
 			if err != nil {
 				return err
@@ -161,22 +239,46 @@ func newSessionsInspectCmd() *cobra.Command {
 				return err
 			}
 
-			fmt.Print(formatSessionDetails(meta))
+			switch {
+			case output.structured():
+				return output.write(os.Stdout, sessionui.NewSession(meta))
+			case output.tmpl != nil:
+				return output.execute(os.Stdout, sessionui.NewSession(meta))
+			case output.output == outputWide:
+				fmt.Print(formatSessionDetails(meta) + formatSessionExtras(meta))
+			default:
+				fmt.Print(formatSessionDetails(meta))
+			}
 			return nil
 		},
 	}
+
+	addOutputFlags(cmd, &output, true, "Print the session with a Go template, e.g. '{{.Status}} {{.LastError}}'")
+
+	return cmd
 }
 
 func newSessionsLogsCmd() *cobra.Command {
 	var stdout bool
 	var stderr bool
 	var tail int
+	var runID string
+	var follow bool
+	var since string
+	var output outputOptions
 
 	cmd := &cobra.Command{
 		Use:   "logs <session-id>",
 		Short: "Print captured stdout/stderr logs for a session",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(false); err != nil {
+				return err
+			}
+			if follow && (output.structured() || output.tmpl != nil) {
+				return fmt.Errorf("--follow prints plain text and cannot be combined with --output or --format")
+			}
+
 			meta, err := loadRunSessionByID(args[0])
 			if err != nil {
 				return err
@@ -185,30 +287,173 @@ func newSessionsLogsCmd() *cobra.Command {
 				return err
 			}
 
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
 			stdout, stderr = resolveLogSelection(stdout, stderr)
 
-			if stdout {
-				if err := printLogSection(os.Stdout, "stdout", meta.StdoutPath, tail); err != nil {
+			stdoutPath, stderrPath := meta.StdoutPath, meta.StderrPath
+			if runID != "" {
+				run, err := session.LoadRun(meta, runID)
+				if err != nil {
+					return fmt.Errorf("load run %s: %w", runID, err)
+				}
+				stdoutPath, stderrPath = run.StdoutPath, run.StderrPath
+			}
+
+			if follow {
+				follower, err := newLogFollower(os.Stdout, stdoutPath, stderrPath, stdout, stderr, sinceTime, tail)
+				if err != nil {
 					return err
 				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return follower.follow(ctx, func() (bool, error) {
+					return followFinished(meta, runID)
+				})
 			}
-			if stderr {
-				if stdout {
-					if _, err := fmt.Fprintln(os.Stdout); err != nil {
-						return err
-					}
+
+			marks, err := session.LoadIterations(stdoutPath)
+			if err != nil {
+				return err
+			}
+
+			var logs []sessionui.Log
+			for _, sel := range []struct {
+				stream string
+				path   string
+				want   bool
+			}{
+				{"stdout", stdoutPath, stdout},
+				{"stderr", stderrPath, stderr},
+			} {
+				if !sel.want {
+					continue
+				}
+				from, err := logStartOffset(sel.path, marks, sel.stream, sinceTime)
+				if err != nil {
+					return err
 				}
-				if err := printLogSection(os.Stdout, "stderr", meta.StderrPath, tail); err != nil {
+				content, err := readLogFile(sel.path, from, tail)
+				if err != nil {
 					return err
 				}
+				logs = append(logs, sessionui.Log{
+					SessionID: meta.ID,
+					RunID:     runID,
+					Stream:    sel.stream,
+					Path:      sel.path,
+					Content:   content,
+				})
 			}
-			return nil
+			return writeLogs(os.Stdout, &output, logs)
 		},
 	}
 
 	cmd.Flags().BoolVar(&stdout, "stdout", false, "Print stdout log only")
 	cmd.Flags().BoolVar(&stderr, "stderr", false, "Print stderr log only")
 	cmd.Flags().IntVar(&tail, "tail", 0, "Print only the last N lines from each selected log")
+	cmd.Flags().StringVar(&runID, "run", "", "Print logs of a single scheduled run instead of the whole session")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new output until the session (or --run) finishes")
+	cmd.Flags().StringVar(&since, "since", "", "Start from the iteration running at this time (duration like 15m, RFC 3339 time or date)")
+	addOutputFlags(cmd, &output, false, "Print each log with a Go template, e.g. '{{.Content}}'")
+
+	return cmd
+}
+
+// followFinished reports whether a followed session or run has stopped
+// producing output. Resident sessions are followed until they are stopped,
+// one-off sessions until their process exits.
+func followFinished(meta *session.Metadata, runID string) (bool, error) {
+	current, err := session.LoadByID(meta.RepoRoot, meta.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := session.Reconcile(current); err != nil {
+		return false, err
+	}
+
+	if runID != "" {
+		run, err := session.LoadRun(current, runID)
+		if err != nil {
+			return false, err
+		}
+		return run.Status != session.StatusRunning || session.IsTerminalStatus(current.Status), nil
+	}
+	if current.IsResident() {
+		return session.IsTerminalStatus(current.Status), nil
+	}
+	return current.Status != session.StatusRunning && current.Status != session.StatusPending, nil
+}
+
+func newSessionsRunsCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "runs <session-id> [run-id]",
+		Short: "List the recorded runs of a scheduled session, or show one run",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			meta, err := loadRunSessionByID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := session.Reconcile(meta); err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				run, err := session.LoadRun(meta, args[1])
+				if err != nil {
+					return fmt.Errorf("load run %s: %w", args[1], err)
+				}
+				fmt.Print(formatRunDetails(run))
+				return nil
+			}
+
+			runs, err := session.ListRuns(meta)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Println("No runs recorded.")
+				return nil
+			}
+			total := len(runs)
+			if limit > 0 && len(runs) > limit {
+				runs = runs[:limit]
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if _, err := fmt.Fprintln(w, "RUN ID\tTRIGGER\tSTATUS\tITER\tDURATION\tSTARTED"); err != nil {
+				return err
+			}
+			for _, run := range runs {
+				if _, err := fmt.Fprintf(
+					w,
+					"%s\t%s\t%s\t%s\t%s\t%s\n",
+					run.ID,
+					run.Trigger,
+					run.Status,
+					formatRunIterations(run),
+					formatRunDuration(run),
+					run.StartedAt.Format(time.RFC3339),
+				); err != nil {
+					return err
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "\nShowing %d of %d runs\n", len(runs), total)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of runs to display")
 
 	return cmd
 }
@@ -259,13 +504,22 @@ func newSessionsResumeCmd() *cobra.Command {
 			if err := session.Reconcile(meta); err != nil {
 				return err
 			}
-			if meta.Schedule != "" {
-				return fmt.Errorf("resume is not supported for scheduled sessions")
-			}
 			if meta.Status != session.StatusBlocked {
 				return fmt.Errorf("session %s is not blocked", meta.ID)
 			}
 
+			if meta.IsResident() {
+				if err := session.RequestResume(meta, prompt); err != nil {
+					return err
+				}
+				fmt.Printf("Resumed blocked run of scheduled session %s\n", meta.ID)
+				fmt.Printf("History: skill-loop sessions runs %s\n", meta.ID)
+				if attach {
+					return session.Attach(meta)
+				}
+				return nil
+			}
+
 			command, err := session.BuildResumeCommand(meta, prompt)
 			if err != nil {
 				return err
@@ -278,7 +532,11 @@ func newSessionsResumeCmd() *cobra.Command {
 			}
 
 			fmt.Printf("Resumed in background. run_id=%s\n", meta.ID)
-			fmt.Printf("Attach: skill-loop sessions attach %s\n", meta.ID)
+			if session.BackendName(meta) == session.BackendTmux {
+				fmt.Printf("Attach: skill-loop sessions attach %s\n", meta.ID)
+			} else {
+				fmt.Printf("Follow: skill-loop sessions logs %s -f\n", meta.ID)
+			}
 
 			if attach {
 				return session.Attach(meta)
@@ -293,9 +551,90 @@ func newSessionsResumeCmd() *cobra.Command {
 	return cmd
 }
 
+func newSessionsTriggerCmd() *cobra.Command {
+	var prompt string
+
+	cmd := &cobra.Command{
+		Use:   "trigger <session-id>",
+		Short: "Run a scheduled session immediately without waiting for its next fire time",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			meta, err := loadRunSessionByID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := session.Reconcile(meta); err != nil {
+				return err
+			}
+			if err := session.RequestTrigger(meta, prompt); err != nil {
+				return err
+			}
+
+			fmt.Printf("Triggered a run of scheduled session %s\n", meta.ID)
+			fmt.Printf("History: skill-loop sessions runs %s\n", meta.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&prompt, "prompt", "p", "", "Prompt for this run only, replacing the session's initial prompt")
+
+	return cmd
+}
+
+func newSessionsPauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <session-id>",
+		Short: "Skip the scheduled runs of a session until it is unpaused",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			meta, err := loadRunSessionByID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := session.Reconcile(meta); err != nil {
+				return err
+			}
+			if err := session.RequestPause(meta); err != nil {
+				return err
+			}
+
+			if meta.Status == session.StatusRunning {
+				fmt.Printf("Pausing scheduled session %s after the current execution finishes\n", meta.ID)
+			} else {
+				fmt.Printf("Paused scheduled session %s\n", meta.ID)
+			}
+			return nil
+		},
+	}
+}
+
+func newSessionsUnpauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpause <session-id>",
+		Short: "Resume the schedule of a paused session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			meta, err := loadRunSessionByID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := session.Reconcile(meta); err != nil {
+				return err
+			}
+			if err := session.RequestUnpause(meta); err != nil {
+				return err
+			}
+
+			fmt.Printf("Unpaused scheduled session %s\n", meta.ID)
+			return nil
+		},
+	}
+}
+
 func newSessionsPruneCmd() *cobra.Command {
 	var dryRun bool
 	var all bool
+	var output outputOptions
 
 	cmd := &cobra.Command{
 		Use:   "prune",
@@ -305,58 +644,62 @@ func newSessionsPruneCmd() *cobra.Command {
 By default, only terminal sessions (done, failed, stopped) are removed.
 Use --all to also remove non-running non-terminal sessions (pending/idle).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(true); err != nil {
+				return err
+			}
+
 			repoRoot, err := session.ResolveRepoRoot("")
 			if err != nil {
 				return err
 			}
 
-			metas, _, err := listRunSessions(repoRoot, 0, 0)
+			metas, _, err := listRunSessions(repoRoot, session.Filter{}, 0, 0)
 			if err != nil {
 				return err
 			}
 
-			pruned := 0
-			skippedRunning := 0
-			skippedNonTerminal := 0
-			failures := 0
+			result := sessionui.PruneResult{DryRun: dryRun, PrunedSessionIDs: []string{}}
+			var pruned []sessionui.Session
 
 			for _, meta := range metas {
 				if err := session.Reconcile(meta); err != nil {
 					fmt.Fprintf(os.Stderr, "warn: failed to reconcile %s: %v\n", meta.ID, err)
 				}
 
-				if meta.Status == session.StatusRunning {
-					skippedRunning++
+				if session.IsActiveStatus(meta.Status) {
+					result.SkippedRunning++
 					continue
 				}
-				if !all && !isTerminalStatus(meta.Status) {
-					skippedNonTerminal++
+				if !all && !session.IsTerminalStatus(meta.Status) {
+					result.SkippedNonTerminal++
 					continue
 				}
 
-				if dryRun {
-					fmt.Printf("would prune: %s (%s)\n", meta.ID, meta.Status)
-					pruned++
-					continue
-				}
-
-				if err := session.DeleteByID(repoRoot, meta.ID); err != nil {
-					failures++
-					fmt.Fprintf(os.Stderr, "warn: failed to prune %s: %v\n", meta.ID, err)
-					continue
+				if !dryRun {
+					if err := session.DeleteByID(repoRoot, meta.ID); err != nil {
+						result.FailedSessionIDs = append(result.FailedSessionIDs, meta.ID)
+						fmt.Fprintf(os.Stderr, "warn: failed to prune %s: %v\n", meta.ID, err)
+						continue
+					}
 				}
-				fmt.Printf("pruned: %s (%s)\n", meta.ID, meta.Status)
-				pruned++
+				result.Pruned++
+				result.PrunedSessionIDs = append(result.PrunedSessionIDs, meta.ID)
+				pruned = append(pruned, sessionui.NewSession(meta))
 			}
 
-			if dryRun {
-				fmt.Fprintf(os.Stderr, "Dry run complete. candidates=%d skipped_running=%d skipped_non_terminal=%d\n", pruned, skippedRunning, skippedNonTerminal)
-			} else {
-				fmt.Fprintf(os.Stderr, "Pruned=%d skipped_running=%d skipped_non_terminal=%d\n", pruned, skippedRunning, skippedNonTerminal)
+			if err := writePruneResult(os.Stdout, &output, result, pruned); err != nil {
+				return err
+			}
+			if !output.structured() && output.tmpl == nil {
+				if dryRun {
+					fmt.Fprintf(os.Stderr, "Dry run complete. candidates=%d skipped_running=%d skipped_non_terminal=%d\n", result.Pruned, result.SkippedRunning, result.SkippedNonTerminal)
+				} else {
+					fmt.Fprintf(os.Stderr, "Pruned=%d skipped_running=%d skipped_non_terminal=%d\n", result.Pruned, result.SkippedRunning, result.SkippedNonTerminal)
+				}
 			}
 
-			if failures > 0 {
-				return fmt.Errorf("failed to prune %d session(s)", failures)
+			if len(result.FailedSessionIDs) > 0 {
+				return fmt.Errorf("failed to prune %d session(s)", len(result.FailedSessionIDs))
 			}
 			return nil
 		},
@@ -364,10 +707,46 @@ Use --all to also remove non-running non-terminal sessions (pending/idle).`,
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print sessions that would be pruned without deleting")
 	cmd.Flags().BoolVar(&all, "all", false, "Also prune non-running non-terminal sessions (pending/idle)")
+	addOutputFlags(cmd, &output, true, "Print each pruned session with a Go template, e.g. '{{.ID}}'")
 
 	return cmd
 }
 
+// writePruneResult prints the pruned sessions, or the result in the format
+// selected by output.
+func writePruneResult(w io.Writer, output *outputOptions, result sessionui.PruneResult, pruned []sessionui.Session) error {
+	if output.structured() {
+		return output.write(w, result)
+	}
+
+	action := "pruned"
+	if result.DryRun {
+		action = "would prune"
+	}
+	wide := output.output == outputWide
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if wide && len(pruned) > 0 {
+		if _, err := fmt.Fprintln(tw, "ACTION\tID\tSTATUS\tDETAILS\tCONFIG\tSTARTED"); err != nil {
+			return err
+		}
+	}
+	for _, s := range pruned {
+		var err error
+		switch {
+		case output.tmpl != nil:
+			err = output.execute(w, s)
+		case wide:
+			_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", action, s.ID, s.Status, s.Detail, s.ConfigName, s.StartedAt.Format(time.RFC3339))
+		default:
+			_, err = fmt.Fprintf(w, "%s: %s (%s)\n", action, s.ID, s.Status)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
 func loadRunSessionByID(id string) (*session.Metadata, error) {
 	repoRoot, err := session.ResolveRepoRoot("")
 	if err != nil {
@@ -383,7 +762,55 @@ func loadRunSessionByID(id string) (*session.Metadata, error) {
 	return meta, nil
 }
 
-func listRunSessions(repoRoot string, offset, limit int) ([]*session.Metadata, int, error) {
+// sessionFilterFlags holds the filter flags of sessions ls.
+type sessionFilterFlags struct {
+	statuses []string
+	workflow string
+	config   string
+	skill    string
+	since    string
+	until    string
+	search   string
+}
+
+func (f *sessionFilterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.statuses, "status", nil, "Only list sessions in these statuses, e.g. running,blocked")
+	cmd.Flags().StringVar(&f.workflow, "workflow", "", "Only list sessions of this workflow name")
+	cmd.Flags().StringVar(&f.config, "config", "", "Only list sessions started from this config path, e.g. skill-loop.yml")
+	cmd.Flags().StringVar(&f.skill, "skill", "", "Only list sessions whose current or last skill is this one")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only list sessions started at or after this time")
+	cmd.Flags().StringVar(&f.until, "until", "", "Only list sessions started before this time")
+	cmd.Flags().StringVar(&f.search, "search", "", "Only list sessions whose last skill output or error contains this text")
+}
+
+func (f *sessionFilterFlags) filter(now time.Time) (session.Filter, error) {
+	statuses, err := session.ParseStatuses(f.statuses)
+	if err != nil {
+		return session.Filter{}, fmt.Errorf("invalid --status: %w", err)
+	}
+	since, err := session.ParseTime(f.since, now)
+	if err != nil {
+		return session.Filter{}, fmt.Errorf("invalid --since %q: %w", f.since, err)
+	}
+	until, err := session.ParseTime(f.until, now)
+	if err != nil {
+		return session.Filter{}, fmt.Errorf("invalid --until %q: %w", f.until, err)
+	}
+	return session.Filter{
+		Statuses: statuses,
+		Workflow: strings.TrimSpace(f.workflow),
+		Config:   strings.TrimSpace(f.config),
+		Skill:    strings.TrimSpace(f.skill),
+		Since:    since,
+		Until:    until,
+		Search:   strings.TrimSpace(f.search),
+	}, nil
+}
+
+// listRunSessions returns a page of the run sessions of repoRoot, or of every
+// repository when repoRoot is empty, that match filter. Filtered sessions are
+// reconciled first so that status filters see their current status.
+func listRunSessions(repoRoot string, filter session.Filter, offset, limit int) ([]*session.Metadata, int, error) {
 	all, err := session.List(repoRoot)
 	if err != nil {
 		return nil, 0, err
@@ -391,9 +818,16 @@ func listRunSessions(repoRoot string, offset, limit int) ([]*session.Metadata, i
 
 	runs := make([]*session.Metadata, 0, len(all))
 	for _, meta := range all {
-		if meta.Skill == "orchestrator" {
-			runs = append(runs, meta)
+		if meta.Skill != "orchestrator" {
+			continue
 		}
+		if !filter.IsZero() {
+			_ = session.Reconcile(meta)
+			if !filter.Match(meta) {
+				continue
+			}
+		}
+		runs = append(runs, meta)
 	}
 
 	total := len(runs)
@@ -412,10 +846,6 @@ func listRunSessions(repoRoot string, offset, limit int) ([]*session.Metadata, i
 	return runs[offset:end], total, nil
 }
 
-func isTerminalStatus(status session.Status) bool {
-	return status == session.StatusDone || status == session.StatusFailed || status == session.StatusStopped
-}
-
 func formatSessionDetails(meta *session.Metadata) string {
 	var b strings.Builder
 	sessionDir := filepath.Dir(meta.ScriptPath)
@@ -431,6 +861,9 @@ func formatSessionDetails(meta *session.Metadata) string {
 	fmt.Fprintf(&b, "Stdout: %s\n", meta.StdoutPath)
 	fmt.Fprintf(&b, "Stderr: %s\n", meta.StderrPath)
 	fmt.Fprintf(&b, "Working dir: %s\n", meta.WorkingDir)
+	if len(meta.Vars) > 0 {
+		fmt.Fprintf(&b, "Vars: %s\n", formatVars(meta.Vars))
+	}
 	if meta.BlockReason != "" {
 		fmt.Fprintf(&b, "Block reason: %s\n", meta.BlockReason)
 	}
@@ -444,122 +877,171 @@ func formatSessionDetails(meta *session.Metadata) string {
 	return b.String()
 }
 
-func resolveLogSelection(stdout bool, stderr bool) (bool, bool) {
-	if !stdout && !stderr {
-		return true, true
+// formatSessionExtras returns the inspect --output wide lines that follow
+// formatSessionDetails.
+func formatSessionExtras(meta *session.Metadata) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Config: %s\n", sessionui.ConfigName(meta))
+	if meta.ConfigPath != "" {
+		fmt.Fprintf(&b, "Config path: %s\n", meta.ConfigPath)
 	}
-	return stdout, stderr
+	fmt.Fprintf(&b, "Runtime: %s\n", orDash(meta.Runtime))
+	fmt.Fprintf(&b, "Backend: %s\n", session.BackendName(meta))
+	fmt.Fprintf(&b, "PID: %s\n", formatPID(meta.PID))
+	fmt.Fprintf(&b, "Iteration: %s\n", formatIterations(meta.CurrentIteration, meta.MaxIterations))
+	if meta.CurrentSkill != "" {
+		fmt.Fprintf(&b, "Current skill: %s\n", meta.CurrentSkill)
+	}
+	if meta.Schedule != "" {
+		fmt.Fprintf(&b, "Schedule: %s\n", meta.Schedule)
+	}
+	if meta.MaxRestarts > 0 {
+		fmt.Fprintf(&b, "Restarts: %d/%d\n", meta.RestartCount, meta.MaxRestarts)
+	}
+	if len(meta.Command) > 0 {
+		fmt.Fprintf(&b, "Command: %s\n", strings.Join(meta.Command, " "))
+	}
+
+	return b.String()
 }
 
-func printLogSection(w io.Writer, label string, path string, tail int) error {
-	content, err := readLogFile(path, tail)
-	if err != nil {
-		return err
+func formatVars(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	if _, err := fmt.Fprintf(w, "==> %s (%s)\n", label, path); err != nil {
-		return err
+	assignments := make([]string, 0, len(keys))
+	for _, key := range keys {
+		assignments = append(assignments, key+"="+vars[key])
 	}
-	if content == "" {
-		_, err := fmt.Fprintln(w, "(empty)")
-		return err
+	return strings.Join(assignments, " ")
+}
+
+func formatRunDetails(run *session.Run) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Run: %s\n", run.ID)
+	fmt.Fprintf(&b, "Session: %s\n", run.SessionID)
+	fmt.Fprintf(&b, "Trigger: %s\n", run.Trigger)
+	fmt.Fprintf(&b, "Status: %s\n", run.Status)
+	fmt.Fprintf(&b, "Started: %s\n", run.StartedAt.Format(time.RFC3339))
+	if run.EndedAt != nil {
+		fmt.Fprintf(&b, "Ended: %s\n", run.EndedAt.Format(time.RFC3339))
 	}
-	if _, err := io.WriteString(w, content); err != nil {
-		return err
+	fmt.Fprintf(&b, "Duration: %s\n", formatRunDuration(run))
+	fmt.Fprintf(&b, "Iterations: %s\n", formatRunIterations(run))
+	if run.CurrentSkill != "" {
+		fmt.Fprintf(&b, "Last skill: %s\n", run.CurrentSkill)
 	}
-	if !strings.HasSuffix(content, "\n") {
-		_, err := fmt.Fprintln(w)
-		return err
+	fmt.Fprintf(&b, "Stdout: %s\n", run.StdoutPath)
+	fmt.Fprintf(&b, "Stderr: %s\n", run.StderrPath)
+	if run.BlockReason != "" {
+		fmt.Fprintf(&b, "Block reason: %s\n", run.BlockReason)
 	}
-	return nil
+	if run.LastError != "" {
+		fmt.Fprintf(&b, "Last error: %s\n", run.LastError)
+	}
+	if strings.TrimSpace(run.LastSkillOutput) != "" {
+		fmt.Fprintf(&b, "\nOutput:\n%s\n", strings.TrimSpace(run.LastSkillOutput))
+	}
+
+	return b.String()
 }
 
-func readLogFile(path string, tail int) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return "", nil
-		}
-		return "", err
-	}
+func formatRunIterations(run *session.Run) string {
+	return formatIterations(run.CurrentIteration, run.MaxIterations)
+}
 
-	content := string(data)
-	if tail > 0 {
-		content = tailLines(content, tail)
+func formatIterations(current, maxIterations int) string {
+	if maxIterations <= 0 {
+		return fmt.Sprintf("%d", current)
 	}
-	return content, nil
+	return fmt.Sprintf("%d/%d", current, maxIterations)
 }
 
-func tailLines(s string, n int) string {
-	if n <= 0 {
-		return s
+func formatPID(pid int) string {
+	if pid <= 0 {
+		return "-"
 	}
+	return fmt.Sprintf("%d", pid)
+}
 
-	trimmedTrailingNewline := strings.HasSuffix(s, "\n")
-	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return ""
-	}
-	if len(lines) > n {
-		lines = lines[len(lines)-n:]
+func orDash(s string) string {
+	if s == "" {
+		return "-"
 	}
+	return s
+}
+
+func formatRunDuration(run *session.Run) string {
+	return run.Duration().Round(time.Second).String()
+}
 
-	result := strings.Join(lines, "\n")
-	if trimmedTrailingNewline && result != "" {
-		result += "\n"
+func resolveLogSelection(stdout bool, stderr bool) (bool, bool) {
+	if !stdout && !stderr {
+		return true, true
 	}
-	return result
+	return stdout, stderr
 }
 
-func formatSessionDetail(meta *session.Metadata) string {
-	switch meta.Status {
-	case session.StatusScheduled:
-		if meta.NextRun == nil {
-			if meta.LastError != "" {
-				return "next: n/a error: " + meta.LastError
+// writeLogs prints the selected logs as sections headed by their stream and
+// path, or in the format selected by output.
+func writeLogs(w io.Writer, output *outputOptions, logs []sessionui.Log) error {
+	if output.structured() {
+		return output.write(w, logs)
+	}
+	for i, log := range logs {
+		var err error
+		switch {
+		case output.tmpl != nil:
+			err = output.execute(w, log)
+		case i > 0:
+			if _, err = fmt.Fprintln(w); err == nil {
+				err = printLogSection(w, log)
 			}
-			return "next: n/a"
+		default:
+			err = printLogSection(w, log)
 		}
-		detail := "next: " + meta.NextRun.Local().Format(time.DateTime)
-		if meta.LastError != "" {
-			detail += " error: " + meta.LastError
+		if err != nil {
+			return err
 		}
-		return detail
-	case session.StatusRunning:
-		if meta.Schedule != "" && meta.MaxIterations > 0 {
-			return fmt.Sprintf("iter: %d/%d", meta.CurrentIteration, meta.MaxIterations)
-		}
-		return "last_output: " + meta.LastOutputAt.Local().Format(time.DateTime)
-	case session.StatusBlocked:
-		if meta.BlockReason != "" {
-			return "awaiting input: " + meta.BlockReason
-		}
-		if meta.ResumeSkill != "" {
-			return "awaiting input for " + meta.ResumeSkill
-		}
-		return "awaiting human input"
-	case session.StatusFailed, session.StatusStopped:
-		if meta.LastError != "" {
-			return meta.LastError
-		}
-		if meta.EndedAt != nil {
-			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
-		}
-		return string(meta.Status)
-	default:
-		if meta.EndedAt != nil {
-			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
-		}
-		return meta.LastOutputAt.Local().Format(time.DateTime)
 	}
+	return nil
 }
 
-func sessionConfigName(meta *session.Metadata) string {
-	if meta.WorkflowName != "" {
-		return meta.WorkflowName
+// printLogSection prints a log under a header naming its stream and path.
+func printLogSection(w io.Writer, log sessionui.Log) error {
+	content := log.Content
+	if _, err := fmt.Fprintf(w, "==> %s (%s)\n", log.Stream, log.Path); err != nil {
+		return err
 	}
-	if meta.ConfigPath == "" {
-		return "-"
+	if content == "" {
+		_, err := fmt.Fprintln(w, "(empty)")
+		return err
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(content, "\n") {
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+	return nil
+}
+
+// readLogFile returns the contents of path from the given byte offset on,
+// limited to the last tail lines when tail is positive.
+func readLogFile(path string, from int64, tail int) (string, error) {
+	start, err := tailStartOffset(path, from, tail)
+	if err != nil {
+		return "", err
+	}
+	data, err := readLogRange(path, start)
+	if err != nil {
+		return "", err
 	}
-	return filepath.Base(meta.ConfigPath)
+	return string(data), nil
 }
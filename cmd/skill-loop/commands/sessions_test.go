@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/takumiyoshikawa/skill-loop/internal/session"
+	"github.com/takumiyoshikawa/skill-loop/internal/sessionui"
 )
 
 func TestResolveLogSelection(t *testing.T) {
@@ -34,17 +35,39 @@ func TestResolveLogSelection(t *testing.T) {
 	}
 }
 
-func TestTailLines(t *testing.T) {
-	input := "one\ntwo\nthree\nfour\n"
-	got := tailLines(input, 2)
-	want := "three\nfour\n"
-	if got != want {
-		t.Fatalf("tailLines() = %q, want %q", got, want)
+func TestReadLogFileTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdout.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		from int64
+		tail int
+		want string
+	}{
+		{name: "last lines", tail: 2, want: "three\nfour\n"},
+		{name: "whole log", want: "one\ntwo\nthree\nfour\n"},
+		{name: "from offset", from: 4, want: "two\nthree\nfour\n"},
+		{name: "tail after offset", from: 8, tail: 5, want: "three\nfour\n"},
+		{name: "offset past the end", from: 100, tail: 2, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLogFile(path, tt.from, tt.tail)
+			if err != nil {
+				t.Fatalf("readLogFile() error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("readLogFile() = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
 func TestReadLogFileMissingReturnsEmpty(t *testing.T) {
-	got, err := readLogFile(filepath.Join(t.TempDir(), "missing.log"), 0)
+	got, err := readLogFile(filepath.Join(t.TempDir(), "missing.log"), 0, 0)
 	if err != nil {
 		t.Fatalf("readLogFile() error: %v", err)
 	}
@@ -88,14 +111,45 @@ func TestFormatSessionDetailsIncludesPathsAndError(t *testing.T) {
 	}
 }
 
+func TestFormatRunDetailsIncludesOutputAndDuration(t *testing.T) {
+	started := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	ended := started.Add(3 * time.Minute)
+	run := &session.Run{
+		ID:               "20260307T090000Z-ab",
+		SessionID:        "session-123",
+		Trigger:          session.TriggerSchedule,
+		Status:           session.StatusFailed,
+		StartedAt:        started,
+		EndedAt:          &ended,
+		CurrentIteration: 2,
+		MaxIterations:    10,
+		CurrentSkill:     "staleness-check",
+		LastSkillOutput:  "found 3 stale PRs",
+		LastError:        "max iterations (10) reached",
+	}
+
+	got := formatRunDetails(run)
+
+	for _, want := range []string{
+		"Run: 20260307T090000Z-ab",
+		"Trigger: schedule",
+		"Status: failed",
+		"Duration: 3m0s",
+		"Iterations: 2/10",
+		"Last error: max iterations (10) reached",
+		"Output:\nfound 3 stale PRs",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("formatRunDetails() missing %q\nfull output:\n%s", want, got)
+		}
+	}
+}
+
 func TestPrintLogSectionEmpty(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "stdout.log")
-	if err := os.WriteFile(path, []byte(""), 0o600); err != nil {
-		t.Fatalf("WriteFile() error: %v", err)
-	}
 
 	var b strings.Builder
-	if err := printLogSection(&b, "stdout", path, 0); err != nil {
+	if err := printLogSection(&b, sessionui.Log{Stream: "stdout", Path: path}); err != nil {
 		t.Fatalf("printLogSection() error: %v", err)
 	}
 
@@ -119,3 +173,99 @@ func TestSessionsShowArgs(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteSessionListOutputs(t *testing.T) {
+	started := time.Date(2026, 3, 7, 3, 4, 5, 0, time.UTC)
+	list := sessionui.SessionList{
+		RepoRoot: "/repo",
+		Total:    3,
+		Sessions: []sessionui.Session{{
+			ID:               "session-1",
+			Status:           session.StatusRunning,
+			Detail:           "iter: 2/5",
+			ConfigName:       "nightly",
+			Backend:          session.BackendProcess,
+			CurrentIteration: 2,
+			MaxIterations:    5,
+			CurrentSkill:     "review",
+			PID:              42,
+			StartedAt:        started,
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		output outputOptions
+		want   []string
+	}{
+		{name: "text", want: []string{"ID  ", "session-1", "nightly", "2026-03-07T03:04:05Z"}},
+		{name: "wide", output: outputOptions{output: "wide"}, want: []string{"BACKEND", "process", "2/5", "review", "42"}},
+		{name: "json", output: outputOptions{output: "json"}, want: []string{`"total": 3`, `"id": "session-1"`, `"configName": "nightly"`}},
+		{name: "yaml", output: outputOptions{output: "yaml"}, want: []string{"total: 3", "- id: session-1", "backend: process"}},
+		{name: "format", output: outputOptions{format: "{{.ID}}={{.Status}}"}, want: []string{"session-1=running\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.output
+			if err := output.validate(true); err != nil {
+				t.Fatalf("validate() error: %v", err)
+			}
+
+			var b strings.Builder
+			if err := writeSessionList(&b, &output, list); err != nil {
+				t.Fatalf("writeSessionList() error: %v", err)
+			}
+			got := b.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("writeSessionList() missing %q\nfull output:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWritePruneResultDryRun(t *testing.T) {
+	result := sessionui.PruneResult{DryRun: true, Pruned: 1, PrunedSessionIDs: []string{"session-1"}}
+	pruned := []sessionui.Session{{ID: "session-1", Status: session.StatusDone}}
+
+	var output outputOptions
+	if err := output.validate(true); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+	var b strings.Builder
+	if err := writePruneResult(&b, &output, result, pruned); err != nil {
+		t.Fatalf("writePruneResult() error: %v", err)
+	}
+	if got, want := b.String(), "would prune: session-1 (done)\n"; got != want {
+		t.Fatalf("writePruneResult() = %q, want %q", got, want)
+	}
+}
+
+func TestSessionFilterFlags(t *testing.T) {
+	now := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	flags := sessionFilterFlags{
+		statuses: []string{"running", "blocked"},
+		workflow: " nightly ",
+		since:    "2h",
+		search:   "timeout",
+	}
+
+	got, err := flags.filter(now)
+	if err != nil {
+		t.Fatalf("filter() error: %v", err)
+	}
+	if len(got.Statuses) != 2 || got.Workflow != "nightly" || !got.Since.Equal(now.Add(-2*time.Hour)) || got.Search != "timeout" {
+		t.Fatalf("filter() = %+v, want statuses, workflow, since and search", got)
+	}
+
+	for _, bad := range []sessionFilterFlags{
+		{statuses: []string{"finished"}},
+		{until: "tomorrow"},
+	} {
+		if _, err := bad.filter(now); err == nil {
+			t.Fatalf("filter(%+v) error = nil, want error", bad)
+		}
+	}
+}
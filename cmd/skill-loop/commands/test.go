@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/cassette"
+	"github.com/takumiyoshikawa/skill-loop/internal/testsuite"
+)
+
+func NewTestCmd() *cobra.Command {
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "test <file.yml>...",
+		Short: "Run workflow test suites and replay recorded runs",
+		Long: `Check workflow configs without calling any agent. Each file is either a
+test suite or a cassette.
+
+A test suite (a YAML file with a top-level tests key) lists cases that give
+the output of the skill run in every iteration and the route the router
+chooses after it, and expect the ids of the selected routes, the final status
+(done, blocked or failed) and the blocked reason.
+
+A cassette recorded with "skill-loop run --record" is replayed against the
+current config: skills and the router get the recorded responses in order,
+and the test fails when the selected routes or the final status differ from
+the recording, when the workflow asks for an interaction the cassette does
+not have, or when a prompt sent to a skill or the router differs from the
+recorded one.
+
+The issue tracker is not contacted: cassettes record prompts without the
+tracker context, and tracker actions are skipped in both kinds of test. Use
+-v to print the orchestrator output of each run.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			progress := io.Discard
+			if verbose {
+				progress = out
+			}
+
+			total, failed := 0, 0
+			report := func(name string, detail string, failures []string) {
+				total++
+				if len(failures) == 0 {
+					fmt.Fprintf(out, "PASS %s%s\n", name, detail)
+					return
+				}
+				failed++
+				fmt.Fprintf(out, "FAIL %s\n", name)
+				for _, failure := range failures {
+					fmt.Fprintf(out, "    %s\n", failure)
+				}
+			}
+
+			for _, path := range args {
+				isSuite, err := testsuite.IsSuite(path)
+				if err != nil {
+					report(path, "", []string{err.Error()})
+					continue
+				}
+				if isSuite {
+					results, err := testsuite.Run(path, progress)
+					if err != nil {
+						report(path, "", []string{err.Error()})
+						continue
+					}
+					for _, result := range results {
+						report(path+": "+result.Name, "", result.Failures)
+					}
+					continue
+				}
+
+				result, err := cassette.Replay(path, progress)
+				if err != nil {
+					report(path, "", []string{err.Error()})
+					continue
+				}
+				report(path, " ("+result.Got.Status+")", result.Failures)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d test(s) failed", failed, total)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print the orchestrator output of each run")
+
+	return cmd
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+	"github.com/takumiyoshikawa/skill-loop/internal/triggers"
+)
+
+const triggerTokenEnv = "SKILL_LOOP_TRIGGER_TOKEN"
+
+func NewServeTriggersCmd() *cobra.Command {
+	var host string
+	var port int
+	var token string
+
+	cmd := &cobra.Command{
+		Use:   "serve-triggers [config.yml...]",
+		Short: "Serve an authenticated HTTP endpoint that starts webhook-enabled workflows",
+		Long: `Serve an HTTP endpoint that starts workflows from authenticated POST requests.
+
+Only workflows that set trigger.webhook are served. When no config files are
+given, skill-loop.yml, skill-loop-*.yml and skill-loop.*.yml files anywhere in
+the repository are discovered, and discovered configs that fail to load are
+skipped with a warning.
+
+Requests must send "Authorization: Bearer <token>", where the token comes from
+--token or the ` + triggerTokenEnv + ` environment variable:
+
+  curl -X POST -H "Authorization: Bearer $TOKEN" -d '{"ref":"main"}' \
+    http://127.0.0.1:7318/workflows/<name>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" {
+				token = os.Getenv(triggerTokenEnv)
+			}
+			if token == "" {
+				return fmt.Errorf("a token is required: pass --token or set %s", triggerTokenEnv)
+			}
+
+			var workflows []*triggers.Workflow
+			if len(args) == 0 {
+				repoRoot, err := session.ResolveRepoRoot("")
+				if err != nil {
+					return err
+				}
+				if workflows, err = triggers.DiscoverWorkflows(repoRoot, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			} else {
+				configPaths := make([]string, 0, len(args))
+				for _, arg := range args {
+					absPath, err := filepath.Abs(arg)
+					if err != nil {
+						return fmt.Errorf("resolve config path: %w", err)
+					}
+					configPaths = append(configPaths, absPath)
+				}
+				var err error
+				if workflows, err = triggers.LoadWorkflows(configPaths); err != nil {
+					return err
+				}
+			}
+			if len(workflows) == 0 {
+				return fmt.Errorf("no workflow enables trigger.webhook")
+			}
+
+			handler, err := triggers.NewHandler(token, workflows)
+			if err != nil {
+				return err
+			}
+
+			addr := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			baseURL := fmt.Sprintf("http://%s", listener.Addr().String())
+
+			out := cmd.OutOrStdout()
+			if _, err := fmt.Fprintf(out, "Serving webhook triggers at %s\n", baseURL); err != nil {
+				return err
+			}
+			for _, workflow := range workflows {
+				if _, err := fmt.Fprintf(out, "  POST %s/workflows/%s  (%s)\n", baseURL, workflow.Name, workflow.ConfigPath); err != nil {
+					return err
+				}
+			}
+
+			server := &http.Server{
+				Addr:              addr,
+				Handler:           handler,
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+			return server.Serve(listener)
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "Host interface to bind the trigger server to")
+	cmd.Flags().IntVar(&port, "port", 7318, "Port for the trigger server")
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token required on every request (default: $"+triggerTokenEnv+")")
+
+	return cmd
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/validate"
+)
+
+const (
+	validateFormatText = "text"
+	validateFormatJSON = "json"
+)
+
+func NewValidateCmd() *cobra.Command {
+	var format string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "validate [config.yml...]",
+		Short: "Check workflows for structural and environment problems",
+		Long: `Load each workflow and check it beyond what run requires:
+
+  unreachable          skills that cannot be reached from default_entrypoint (warning)
+  no-done-path         skills with no path to a done route (error)
+  cycle-without-exit   cycles that no route leaves (error)
+  missing-skill-file   skills without .agents/skills/<name>/SKILL.md (error)
+  runtime-not-found    agent runtimes whose CLI is not on PATH (warning)
+
+Skill files are looked up next to the config and in its parent directories up
+to the repository root. A workflow that fails to load reports every problem
+with its file, line and column. The command exits non-zero when any workflow
+has errors, or warnings with --strict. Use --format json for CI.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format != validateFormatText && format != validateFormatJSON {
+				return fmt.Errorf("unknown format %q (want %s or %s)", format, validateFormatText, validateFormatJSON)
+			}
+
+			paths := args
+			if len(paths) == 0 {
+				if _, err := resolveConfigPath(nil); err != nil {
+					return err
+				}
+				paths = []string{defaultConfigFile}
+			}
+
+			reports := make([]*validate.Report, 0, len(paths))
+			for _, path := range paths {
+				report := validate.File(path, validate.Options{})
+				if strict && report.Warnings() > 0 {
+					report.Valid = false
+				}
+				reports = append(reports, report)
+			}
+
+			var err error
+			if format == validateFormatJSON {
+				err = writeValidateJSON(cmd.OutOrStdout(), reports)
+			} else {
+				err = writeValidateText(cmd.OutOrStdout(), reports)
+			}
+			if err != nil {
+				return err
+			}
+
+			invalid := 0
+			for _, report := range reports {
+				if !report.Valid {
+					invalid++
+				}
+			}
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d workflow(s) failed validation", invalid, len(reports))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", validateFormatText, "Output format: text or json")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Treat warnings as errors")
+
+	return cmd
+}
+
+func writeValidateJSON(w io.Writer, reports []*validate.Report) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func writeValidateText(w io.Writer, reports []*validate.Report) error {
+	for _, report := range reports {
+		if len(report.Findings) == 0 {
+			if _, err := fmt.Fprintf(w, "%s: ok\n", report.Config); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s, %s\n", report.Config, plural(report.Errors(), "error"), plural(report.Warnings(), "warning")); err != nil {
+			return err
+		}
+		for _, finding := range report.Findings {
+			message := finding.Message
+			if location := finding.Location(); location != "" {
+				message = location + ": " + message
+			}
+			if _, err := fmt.Fprintf(w, "  %-7s  %-18s  %s\n", finding.Severity, finding.Check, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,327 @@
+// Package cassette records the agent interactions of a workflow run and
+// replays them deterministically, so that workflow configs can be
+// regression-tested without calling agents.
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+)
+
+// Version is the cassette format written by Recorder.
+const Version = 1
+
+// Interaction kinds.
+const (
+	KindSkill  = "skill"
+	KindRouter = "router"
+)
+
+// Final statuses of a recorded run.
+const (
+	StatusDone    = "done"
+	StatusBlocked = "blocked"
+	StatusFailed  = "failed"
+)
+
+// Cassette is a recorded run: how it was started, every agent interaction in
+// order and the outcome that replaying it must reproduce.
+type Cassette struct {
+	Version int `yaml:"version"`
+	// Config is the workflow path, relative to the cassette file.
+	Config        string            `yaml:"config"`
+	Prompt        string            `yaml:"prompt,omitempty"`
+	Entrypoint    string            `yaml:"entrypoint,omitempty"`
+	MaxIterations int               `yaml:"max_iterations,omitempty"`
+	Vars          map[string]string `yaml:"vars,omitempty"`
+	Interactions  []Interaction     `yaml:"interactions"`
+	Expect        Expect            `yaml:"expect"`
+}
+
+// Interaction is one prompt sent to a skill or the router and its response.
+// Error is set instead of a response when the agent failed.
+type Interaction struct {
+	Kind   string `yaml:"kind"`
+	Skill  string `yaml:"skill"`
+	Prompt string `yaml:"prompt"`
+	Output string `yaml:"output,omitempty"`
+	Route  string `yaml:"route,omitempty"`
+	Reason string `yaml:"reason,omitempty"`
+	Error  string `yaml:"error,omitempty"`
+}
+
+// Step is a route selected after a skill.
+type Step struct {
+	Skill string `yaml:"skill"`
+	Route string `yaml:"route"`
+}
+
+// Expect is the outcome of a run: the routes it selected and how it ended.
+type Expect struct {
+	Routes []Step `yaml:"routes"`
+	Status string `yaml:"status"`
+	Reason string `yaml:"reason,omitempty"`
+	Error  string `yaml:"error,omitempty"`
+}
+
+// Load reads the cassette at path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var c Cassette
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d (want %d)", path, c.Version, Version)
+	}
+	if c.Config == "" {
+		return nil, fmt.Errorf("cassette %s: config is required", path)
+	}
+	return &c, nil
+}
+
+// Save writes c to path.
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// ConfigPath returns the workflow path of a cassette loaded from path.
+func (c *Cassette) ConfigPath(path string) string {
+	if filepath.IsAbs(c.Config) {
+		return c.Config
+	}
+	return filepath.Join(filepath.Dir(path), c.Config)
+}
+
+// Outcome returns the expectation a run with these routes that returned err
+// fulfils.
+func Outcome(routes []Step, err error) Expect {
+	expect := Expect{Routes: routes, Status: StatusDone}
+	if expect.Routes == nil {
+		expect.Routes = []Step{}
+	}
+	var blocked *orchestrator.BlockedError
+	switch {
+	case err == nil:
+	case errors.As(err, &blocked):
+		expect.Status = StatusBlocked
+		expect.Reason = blocked.Reason
+	default:
+		expect.Status = StatusFailed
+		expect.Error = err.Error()
+	}
+	return expect
+}
+
+// Recorder is an orchestrator.SkillExecutor that forwards to another executor
+// and records every interaction. It is also an orchestrator.RunObserver that
+// records the selected routes. The cassette is saved after every event so
+// that an interrupted run keeps what it recorded.
+type Recorder struct {
+	mu       sync.Mutex
+	next     orchestrator.SkillExecutor
+	path     string
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder that saves to path a cassette for the
+// workflow at cfgPath started with opts.
+func NewRecorder(path string, next orchestrator.SkillExecutor, cfgPath string, opts orchestrator.RunOptions, vars map[string]string) (*Recorder, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve cassette path: %w", err)
+	}
+	configRef, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve config path: %w", err)
+	}
+	if rel, err := filepath.Rel(filepath.Dir(absPath), configRef); err == nil {
+		configRef = filepath.ToSlash(rel)
+	}
+	r := &Recorder{
+		next: next,
+		path: absPath,
+		cassette: Cassette{
+			Version:       Version,
+			Config:        configRef,
+			Prompt:        opts.Prompt,
+			Entrypoint:    opts.Entrypoint,
+			MaxIterations: opts.MaxIterations,
+			Vars:          vars,
+			Interactions:  []Interaction{},
+			Expect:        Expect{Routes: []Step{}},
+		},
+	}
+	return r, r.save()
+}
+
+// ExecuteSkill runs the skill and records its prompt and output. The prompt
+// is recorded without the tracker context, which replays leave out.
+func (r *Recorder) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
+	result, err := r.next.ExecuteSkill(name, agent, input, opts)
+	handoff := input
+	if opts.TrackerContext != "" {
+		handoff = strings.TrimSuffix(strings.TrimSuffix(input, opts.TrackerContext), "\n\n")
+	}
+	interaction := Interaction{Kind: KindSkill, Skill: name, Prompt: executor.SkillPrompt(name, handoff)}
+	if err != nil {
+		interaction.Error = err.Error()
+	} else {
+		interaction.Output = result.Stdout
+	}
+	r.record(interaction)
+	return result, err
+}
+
+// RouteSkillOutput asks the router and records its prompt and decision.
+func (r *Recorder) RouteSkillOutput(skillName string, router config.Agent, output string, routes []config.Route, opts executor.ExecutionOptions) (*executor.RouterDecision, error) {
+	decision, err := r.next.RouteSkillOutput(skillName, router, output, routes, opts)
+	interaction := Interaction{Kind: KindRouter, Skill: skillName, Prompt: executor.RouterPrompt(skillName, output, routes)}
+	if err != nil {
+		interaction.Error = err.Error()
+	} else {
+		interaction.Route = decision.Route
+		interaction.Reason = decision.Reason
+	}
+	r.record(interaction)
+	return decision, err
+}
+
+// IterationStarted implements orchestrator.RunObserver.
+func (r *Recorder) IterationStarted(iteration int, maxIterations int, skill string) {}
+
+// SkillCompleted implements orchestrator.RunObserver.
+func (r *Recorder) SkillCompleted(iteration int, maxIterations int, skill string, stdout string) {}
+
+// RouteSelected records the route selected after a skill.
+func (r *Recorder) RouteSelected(iteration int, skill string, route config.Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Expect.Routes = append(r.cassette.Expect.Routes, Step{Skill: skill, Route: route.ID})
+	_ = r.save()
+}
+
+// Finish records how the run ended and saves the cassette.
+func (r *Recorder) Finish(runErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Expect = Outcome(r.cassette.Expect.Routes, runErr)
+	return r.save()
+}
+
+func (r *Recorder) record(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	_ = r.save()
+}
+
+func (r *Recorder) save() error {
+	return r.cassette.Save(r.path)
+}
+
+// Player is an orchestrator.SkillExecutor that serves the interactions of a
+// cassette in order. A call that does not match the next recorded
+// interaction fails the run. A call whose prompt differs from the recorded
+// one is still served, and the difference is reported by Drift.
+type Player struct {
+	interactions []Interaction
+	next         int
+	drift        []string
+}
+
+// NewPlayer returns a Player for c.
+func NewPlayer(c *Cassette) *Player {
+	return &Player{interactions: c.Interactions}
+}
+
+// ExecuteSkill returns the recorded output of the skill.
+func (p *Player) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
+	interaction, err := p.take(KindSkill, name, executor.SkillPrompt(name, input))
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	return &executor.SkillResult{Stdout: interaction.Output}, nil
+}
+
+// RouteSkillOutput returns the recorded router decision.
+func (p *Player) RouteSkillOutput(skillName string, router config.Agent, output string, routes []config.Route, opts executor.ExecutionOptions) (*executor.RouterDecision, error) {
+	interaction, err := p.take(KindRouter, skillName, executor.RouterPrompt(skillName, output, routes))
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	return &executor.RouterDecision{Route: interaction.Route, Reason: interaction.Reason}, nil
+}
+
+// Remaining returns the number of interactions not served yet.
+func (p *Player) Remaining() int {
+	return len(p.interactions) - p.next
+}
+
+// Drift returns a description of every served call whose prompt differed
+// from the recorded one.
+func (p *Player) Drift() []string {
+	return p.drift
+}
+
+func (p *Player) take(kind string, skill string, prompt string) (Interaction, error) {
+	if p.next >= len(p.interactions) {
+		return Interaction{}, fmt.Errorf("cassette exhausted: no recorded %s call for %q", kind, skill)
+	}
+	interaction := p.interactions[p.next]
+	if interaction.Kind != kind || interaction.Skill != skill {
+		return Interaction{}, fmt.Errorf("cassette mismatch at interaction %d: recorded %s call for %q, got %s call for %q", p.next+1, interaction.Kind, interaction.Skill, kind, skill)
+	}
+	if prompt != interaction.Prompt {
+		p.drift = append(p.drift, fmt.Sprintf("prompt of interaction %d (%s call for %q) differs: %s", p.next+1, kind, skill, promptDifference(interaction.Prompt, prompt)))
+	}
+	p.next++
+	return interaction, nil
+}
+
+// promptDifference describes the first line where got differs from want.
+func promptDifference(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		switch {
+		case i >= len(wantLines):
+			return fmt.Sprintf("line %d = %q, recorded prompt ends at line %d", i+1, gotLines[i], len(wantLines))
+		case i >= len(gotLines):
+			return fmt.Sprintf("prompt ends at line %d, recorded line %d = %q", len(gotLines), i+1, wantLines[i])
+		case wantLines[i] != gotLines[i]:
+			return fmt.Sprintf("line %d = %q, recorded %q", i+1, gotLines[i], wantLines[i])
+		}
+	}
+}
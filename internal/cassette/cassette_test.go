@@ -0,0 +1,223 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/dryrun"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+	"github.com/takumiyoshikawa/skill-loop/internal/tracker"
+)
+
+const reviewLoop = `default_entrypoint: impl
+router:
+  runtime: codex
+skills:
+  impl:
+    next:
+      - id: review
+        criteria: "Implementation is ready"
+        skill: review
+  review:
+    next:
+      - id: approve
+        criteria: "No blocking issues"
+        done: true
+      - id: rework
+        criteria: "Changes requested"
+        skill: impl
+`
+
+// record runs the workflow at cfgPath with scripted agents and records it
+// into a cassette next to the config.
+func record(t *testing.T, cfgPath string, fixtures *dryrun.Fixtures) string {
+	t.Helper()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	path := filepath.Join(filepath.Dir(cfgPath), "review.cassette.yml")
+	opts := orchestrator.RunOptions{MaxIterations: 10, Prompt: "Add a health check", Output: io.Discard}
+	recorder, err := NewRecorder(path, dryrun.New(fixtures, nil, io.Discard), cfgPath, opts, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder() error: %v", err)
+	}
+	opts.Executor, opts.Observer = recorder, recorder
+	runErr := orchestrator.RunWithOptions(cfg, opts)
+	if err := recorder.Finish(runErr); err != nil {
+		t.Fatalf("Finish() error: %v", err)
+	}
+	return path
+}
+
+func writeWorkflow(t *testing.T, dir string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "skill-loop.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	cfgPath := writeWorkflow(t, t.TempDir(), reviewLoop)
+	path := record(t, cfgPath, &dryrun.Fixtures{
+		Skills: map[string][]string{"impl": {"Added /healthz", "Added tests"}, "review": {"Missing tests", "LGTM"}},
+		Routes: map[string][]string{"review": {"rework", "approve"}},
+	})
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if c.Config != "skill-loop.yml" || c.Prompt != "Add a health check" {
+		t.Fatalf("Load() config = %q, prompt = %q, want the recorded run", c.Config, c.Prompt)
+	}
+	if len(c.Interactions) != 6 {
+		t.Fatalf("len(Interactions) = %d, want 6", len(c.Interactions))
+	}
+	if got := formatSteps(c.Expect.Routes); got != "impl/review -> review/rework -> impl/review -> review/approve" || c.Expect.Status != StatusDone {
+		t.Fatalf("Expect = %s (%s), want the recorded routes and done", got, c.Expect.Status)
+	}
+
+	result, err := Replay(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+	if !result.Passed() {
+		t.Fatalf("Replay() failures = %q, want none", result.Failures)
+	}
+}
+
+// fakeTracker serves one ready issue that stays ready.
+type fakeTracker struct{}
+
+func (fakeTracker) Ready(ctx context.Context, limit int) ([]tracker.Issue, error) {
+	return []tracker.Issue{{Number: 12, Title: "Add a health check", URL: "https://github.com/acme/api/issues/12"}}, nil
+}
+
+func (fakeTracker) Claimed(ctx context.Context) (*tracker.Issue, error) { return nil, nil }
+
+func (fakeTracker) Claim(ctx context.Context, number int) error { return nil }
+
+func (fakeTracker) Comment(ctx context.Context, number int, body string) error { return nil }
+
+func (fakeTracker) Close(ctx context.Context, number int) error { return nil }
+
+func TestRecordAndReplayWithTracker(t *testing.T) {
+	cfgPath := writeWorkflow(t, t.TempDir(), "tracker:\n  repo: acme/api\n"+reviewLoop)
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	path := filepath.Join(filepath.Dir(cfgPath), "review.cassette.yml")
+	opts := orchestrator.RunOptions{MaxIterations: 10, Prompt: "Add a health check", Output: io.Discard, ErrOutput: io.Discard, Tracker: fakeTracker{}}
+	fixtures := &dryrun.Fixtures{
+		Skills: map[string][]string{"impl": {"Added /healthz"}, "review": {"LGTM"}},
+		Routes: map[string][]string{"review": {"approve"}},
+	}
+	recorder, err := NewRecorder(path, dryrun.New(fixtures, nil, io.Discard), cfgPath, opts, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder() error: %v", err)
+	}
+	opts.Executor, opts.Observer = recorder, recorder
+	if err := recorder.Finish(orchestrator.RunWithOptions(cfg, opts)); err != nil {
+		t.Fatalf("Finish() error: %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := c.Interactions[0].Prompt; strings.Contains(got, "#12") {
+		t.Fatalf("recorded prompt = %q, want it without the tracker context", got)
+	}
+
+	result, err := Replay(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+	if !result.Passed() {
+		t.Fatalf("Replay() failures = %q, want none", result.Failures)
+	}
+}
+
+func TestReplayFailsWhenWorkflowChanges(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeWorkflow(t, dir, reviewLoop)
+	path := record(t, cfgPath, &dryrun.Fixtures{
+		Skills: map[string][]string{"impl": {"Added /healthz"}, "review": {"LGTM"}},
+		Routes: map[string][]string{"review": {"approve"}},
+	})
+
+	// Send approved changes to a new docs skill instead of finishing.
+	changed := strings.Replace(reviewLoop, "        done: true\n", "        skill: docs\n", 1) + `  docs:
+    next:
+      - id: done
+        criteria: "Docs are updated"
+        done: true
+`
+	writeWorkflow(t, dir, changed)
+
+	result, err := Replay(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+	if result.Passed() {
+		t.Fatal("Replay() passed, want a failure for the changed workflow")
+	}
+	failures := strings.Join(result.Failures, "\n")
+	for _, want := range []string{"status = failed (", "cassette exhausted", "want done"} {
+		if !strings.Contains(failures, want) {
+			t.Fatalf("Replay() failures = %q, want them to contain %q", failures, want)
+		}
+	}
+}
+
+func TestReplayReportsPromptDrift(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeWorkflow(t, dir, reviewLoop)
+	path := record(t, cfgPath, &dryrun.Fixtures{
+		Skills: map[string][]string{"impl": {"Added /healthz"}, "review": {"LGTM"}},
+		Routes: map[string][]string{"review": {"approve"}},
+	})
+
+	// Reword the criteria the router sees; the routes stay the same.
+	writeWorkflow(t, dir, strings.Replace(reviewLoop, `"No blocking issues"`, `"No blocking or major issues"`, 1))
+
+	result, err := Replay(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+	want := `prompt of interaction 3 (router call for "review") differs: line 10 = "- approve: No blocking or major issues (done)", recorded "- approve: No blocking issues (done)"`
+	if len(result.Failures) != 1 || result.Failures[0] != want {
+		t.Fatalf("Replay() failures = %q, want %q", result.Failures, want)
+	}
+}
+
+func TestReplayReportsBlockedReason(t *testing.T) {
+	want := Expect{Routes: []Step{{Skill: "review", Route: "escalate"}}, Status: StatusBlocked, Reason: "needs a human"}
+	got := Expect{Routes: []Step{{Skill: "review", Route: "escalate"}}, Status: StatusBlocked, Reason: "waiting for input"}
+
+	failures := Compare(want, got)
+	if len(failures) != 1 || failures[0] != `blocked reason = "waiting for input", want "needs a human"` {
+		t.Fatalf("Compare() = %q, want the blocked reason difference", failures)
+	}
+}
+
+func TestPlayerRejectsMismatchedCall(t *testing.T) {
+	player := NewPlayer(&Cassette{Interactions: []Interaction{{Kind: KindSkill, Skill: "impl", Output: "done"}}})
+
+	if _, err := player.ExecuteSkill("review", config.Agent{}, "", executor.ExecutionOptions{}); err == nil || !strings.Contains(err.Error(), `cassette mismatch at interaction 1: recorded skill call for "impl", got skill call for "review"`) {
+		t.Fatalf("ExecuteSkill() error = %v, want a mismatch", err)
+	}
+	if player.Remaining() != 1 {
+		t.Fatalf("Remaining() = %d, want 1", player.Remaining())
+	}
+}
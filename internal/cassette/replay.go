@@ -0,0 +1,103 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+)
+
+// Result is the outcome of replaying a cassette. Failures is empty when the
+// replay reproduced the recorded outcome.
+type Result struct {
+	Cassette string
+	Got      Expect
+	Failures []string
+}
+
+// Passed reports whether the replay reproduced the recorded outcome.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Replay runs the workflow of the cassette at path against its recorded
+// interactions and compares the selected routes, final status and prompts
+// with the recorded ones. The issue tracker is not contacted: recorded prompts
+// leave out the tracker context and tracker actions are skipped. Progress is
+// written to out.
+func Replay(path string, out io.Writer) (*Result, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadWithOptions(c.ConfigPath(path), config.LoadOptions{Vars: c.Vars})
+	if err != nil {
+		return nil, err
+	}
+	cfg.Tracker = nil
+
+	player := NewPlayer(c)
+	steps := &stepObserver{}
+	runErr := orchestrator.RunWithOptions(cfg, orchestrator.RunOptions{
+		MaxIterations: c.MaxIterations,
+		Prompt:        c.Prompt,
+		Entrypoint:    c.Entrypoint,
+		Executor:      player,
+		Observer:      steps,
+		Output:        out,
+	})
+
+	result := &Result{Cassette: path, Got: Outcome(steps.steps, runErr)}
+	result.Failures = append(Compare(c.Expect, result.Got), player.Drift()...)
+	if remaining := player.Remaining(); remaining > 0 && result.Passed() {
+		result.Failures = append(result.Failures, fmt.Sprintf("%d recorded interaction(s) were not replayed", remaining))
+	}
+	return result, nil
+}
+
+// Compare returns a description of every way got differs from want. Error
+// messages of failed runs are not compared, since they may contain paths.
+func Compare(want Expect, got Expect) []string {
+	var failures []string
+	if !slices.Equal(want.Routes, got.Routes) {
+		failures = append(failures, fmt.Sprintf("routes = %s, want %s", formatSteps(got.Routes), formatSteps(want.Routes)))
+	}
+	if want.Status != got.Status {
+		detail := ""
+		if got.Error != "" {
+			detail = " (" + got.Error + ")"
+		}
+		failures = append(failures, fmt.Sprintf("status = %s%s, want %s", got.Status, detail, want.Status))
+	} else if want.Status == StatusBlocked && want.Reason != got.Reason {
+		failures = append(failures, fmt.Sprintf("blocked reason = %q, want %q", got.Reason, want.Reason))
+	}
+	return failures
+}
+
+func formatSteps(steps []Step) string {
+	if len(steps) == 0 {
+		return "(none)"
+	}
+	formatted := make([]string, len(steps))
+	for i, step := range steps {
+		formatted[i] = step.Skill + "/" + step.Route
+	}
+	return strings.Join(formatted, " -> ")
+}
+
+// stepObserver collects the routes selected during a replay.
+type stepObserver struct {
+	steps []Step
+}
+
+func (o *stepObserver) IterationStarted(iteration int, maxIterations int, skill string) {}
+
+func (o *stepObserver) SkillCompleted(iteration int, maxIterations int, skill string, stdout string) {
+}
+
+func (o *stepObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	o.steps = append(o.steps, Step{Skill: skill, Route: route.ID})
+}
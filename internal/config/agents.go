@@ -0,0 +1,60 @@
+package config
+
+import "fmt"
+
+// resolveAgentProfiles replaces the agent of every skill and the router with
+// the profile it references, overlaid with the fields set next to the profile
+// reference. Args set there replace the profile's args.
+func resolveAgentProfiles(cfg *Config, p *problems) {
+	for _, name := range sortedKeys(cfg.Agents) {
+		profile := cfg.Agents[name]
+		label := "agent profile " + strconvQuote(name)
+		if profile.Profile != "" {
+			p.addf("agents."+name+".profile", "%s: profile cannot reference another profile", label)
+		}
+		if err := validateAgent(label, profile); err != nil {
+			p.add("agents."+name+".runtime", err)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Skills) {
+		skill := cfg.Skills[name]
+		agent, err := cfg.resolveAgent("skill "+strconvQuote(name), skill.Agent)
+		if err != nil {
+			p.add("skills."+name+".agent.profile", err)
+			continue
+		}
+		skill.Agent = agent
+		cfg.Skills[name] = skill
+	}
+
+	router, err := cfg.resolveAgent("router", cfg.Router)
+	if err != nil {
+		p.add("router.profile", err)
+		return
+	}
+	cfg.Router = router
+}
+
+func (c *Config) resolveAgent(label string, agent Agent) (Agent, error) {
+	if agent.Profile == "" {
+		return agent, nil
+	}
+	profile, ok := c.Agents[agent.Profile]
+	if !ok {
+		return Agent{}, fmt.Errorf("%s: unknown agent profile %q", label, agent.Profile)
+	}
+
+	resolved := profile
+	resolved.Profile = agent.Profile
+	if agent.Runtime != "" {
+		resolved.Runtime = agent.Runtime
+	}
+	if agent.Model != "" {
+		resolved.Model = agent.Model
+	}
+	if len(agent.Args) > 0 {
+		resolved.Args = agent.Args
+	}
+	return resolved, nil
+}
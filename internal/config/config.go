@@ -2,25 +2,23 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"unicode"
-
-	"github.com/robfig/cron/v3"
-	"gopkg.in/yaml.v3"
 )
 
 type Route struct {
-	ID         string `yaml:"id" jsonschema:"required,description=Stable route identifier returned by the router agent."`
-	Criteria   string `yaml:"criteria,omitempty" jsonschema:"description=Judgment criteria used by the router agent when deciding whether this route should be chosen."`
-	Skill      string `yaml:"skill,omitempty" jsonschema:"description=Target skill name to route to. Mutually exclusive with done."`
-	Done       bool   `yaml:"done,omitempty" jsonschema:"description=Terminate the workflow when this route is selected. Mutually exclusive with skill."`
-	Blocked    bool   `yaml:"blocked,omitempty" jsonschema:"description=Pause the workflow and mark the session blocked awaiting human input. Requires skill and is mutually exclusive with done."`
-	LegacyWhen string `yaml:"when,omitempty" json:"-" jsonschema:"-"`
+	ID         string         `yaml:"id" jsonschema:"required,description=Stable route identifier returned by the router agent."`
+	Criteria   string         `yaml:"criteria,omitempty" jsonschema:"description=Judgment criteria used by the router agent when deciding whether this route should be chosen."`
+	Skill      string         `yaml:"skill,omitempty" jsonschema:"description=Target skill name to route to. Mutually exclusive with done."`
+	Done       bool           `yaml:"done,omitempty" jsonschema:"description=Terminate the workflow when this route is selected. Mutually exclusive with skill."`
+	Blocked    bool           `yaml:"blocked,omitempty" jsonschema:"description=Pause the workflow and mark the session blocked awaiting human input. Requires skill and is mutually exclusive with done."`
+	Tracker    *TrackerAction `yaml:"tracker,omitempty" jsonschema:"description=Deterministic issue tracker actions run when this route is selected. Requires a top-level tracker."`
+	LegacyWhen string         `yaml:"when,omitempty" json:"-" jsonschema:"-"`
 }
 
 type Agent struct {
+	Profile string   `yaml:"profile,omitempty" jsonschema:"description=Name of an entry in the top-level agents map to start from. runtime and model and args set next to profile override the profile's values. Not allowed inside agents entries."`
 	Runtime string   `yaml:"runtime,omitempty" jsonschema:"description=Coding agent CLI runtime to execute. Supported values are claude or codex or cursor-cli or opencode. Defaults to claude." default:"claude"`
 	Model   string   `yaml:"model,omitempty" jsonschema:"description=Model ID to use for this skill (agent-specific)."`
 	Args    []string `yaml:"args,omitempty" jsonschema:"description=Additional CLI arguments to pass to the agent (e.g. --dangerously-skip-permissions for claude)."`
@@ -32,39 +30,64 @@ type Skill struct {
 }
 
 type Config struct {
-	Name               string           `yaml:"name,omitempty" jsonschema:"description=Workflow name used for grouping run sessions under ~/.local/share/skill-loop/<name>/. When omitted the config filename is used."`
-	Schedule           string           `yaml:"schedule,omitempty" jsonschema:"description=Optional cron schedule in standard 5-field crontab syntax. When set skill-loop stays resident and runs the workflow on each matching time."`
-	Router             Agent            `yaml:"router,omitempty" jsonschema:"description=Shared router agent configuration used to choose the next route when a skill has multiple next routes."`
-	DefaultEntrypoint  string           `yaml:"default_entrypoint" jsonschema:"required,description=Default skill to start the loop with. Must exist in the skills map."`
-	MaxIterations      int              `yaml:"max_iterations,omitempty" jsonschema:"description=Maximum number of loop iterations before stopping. Defaults to 100 if omitted." default:"100"`
-	IdleTimeoutSeconds int              `yaml:"idle_timeout_seconds,omitempty" jsonschema:"description=Idle timeout in seconds for each skill execution before restart. Defaults to 900 (15 minutes)." default:"900"`
-	MaxRestarts        *int             `yaml:"max_restarts,omitempty" jsonschema:"description=Maximum automatic restarts per skill execution when idle timeout is exceeded. Defaults to 2. Set 0 to disable automatic restarts." default:"2"`
-	Skills             map[string]Skill `yaml:"skills" jsonschema:"required,description=Map of skill names to their definitions."`
+	Extends               string            `yaml:"extends,omitempty" jsonschema:"description=Path to a base config (relative to this file) to inherit every field except name from. Fields set in this file override the base and skills replace base skills of the same name."`
+	Include               []string          `yaml:"include,omitempty" jsonschema:"description=Paths to YAML fragments (relative to this file) that contribute skills and agent profiles and vars and router settings. Fragments may only set skills and agents and vars and router and include. They override the extended base and are overridden by this file. Two fragments cannot define the same skill or agent profile or variable or the router."`
+	Vars                  map[string]string `yaml:"vars,omitempty" jsonschema:"description=Variables referenced as ${NAME} in string values. Values may reference the environment as ${env:NAME}. skill-loop run --var NAME=value overrides them."`
+	Name                  string            `yaml:"name,omitempty" jsonschema:"description=Workflow name used for grouping run sessions under ~/.local/share/skill-loop/<name>/. When omitted the config filename is used."`
+	Schedule              string            `yaml:"schedule,omitempty" jsonschema:"description=Optional cron schedule in standard 5-field crontab syntax or a descriptor such as @hourly or @every 30m. A CRON_TZ= prefix selects the timezone. When set skill-loop stays resident and runs the workflow on each matching time."`
+	Timezone              string            `yaml:"timezone,omitempty" jsonschema:"description=IANA timezone used to evaluate schedule (e.g. Asia/Tokyo). Defaults to the local timezone of the machine. Cannot be combined with a CRON_TZ= prefix."`
+	ScheduleJitterSeconds int               `yaml:"schedule_jitter_seconds,omitempty" jsonschema:"description=Upper bound in seconds of a stable offset added to every scheduled run to spread workflows that share a schedule. The offset is derived from the workflow name and schedule, so it is the same for every run rather than drawn per run. Defaults to 0 (no offset)."`
+	Trigger               *Trigger          `yaml:"trigger,omitempty" jsonschema:"description=Optional event triggers. With watch skill-loop stays resident and runs the workflow whenever a watched file changes; watch cannot be combined with schedule. With webhook skill-loop serve-triggers starts the workflow from an HTTP POST; webhook can be combined with schedule or watch."`
+	Agents                map[string]Agent  `yaml:"agents,omitempty" jsonschema:"description=Named agent profiles that skills and the router reference with agent.profile."`
+	Tracker               *Tracker          `yaml:"tracker,omitempty" jsonschema:"description=Optional issue tracker. Skills receive the claimed issue or the ready issues as context and routes can claim and comment on and close issues."`
+	Router                Agent             `yaml:"router,omitempty" jsonschema:"description=Shared router agent configuration used to choose the next route when a skill has multiple next routes."`
+	DefaultEntrypoint     string            `yaml:"default_entrypoint" jsonschema:"required,description=Default skill to start the loop with. Must exist in the skills map."`
+	MaxIterations         int               `yaml:"max_iterations,omitempty" jsonschema:"description=Maximum number of loop iterations before stopping. Defaults to 100 if omitted." default:"100"`
+	IdleTimeoutSeconds    int               `yaml:"idle_timeout_seconds,omitempty" jsonschema:"description=Idle timeout in seconds for each skill execution before restart. Defaults to 900 (15 minutes)." default:"900"`
+	MaxRestarts           *int              `yaml:"max_restarts,omitempty" jsonschema:"description=Maximum automatic restarts per skill execution when idle timeout is exceeded. Defaults to 2. Set 0 to disable automatic restarts." default:"2"`
+	Skills                map[string]Skill  `yaml:"skills" jsonschema:"required,description=Map of skill names to their definitions."`
+	// Env holds the environment variables referenced as ${env:NAME} and the
+	// values they had when the config was loaded, or nil when they were not
+	// set. Detached runs pin them, so the child resolves the same config.
+	Env map[string]*string `yaml:"-" json:"-" jsonschema:"-"`
 }
 
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+	return LoadWithOptions(path, LoadOptions{})
+}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// LoadWithOptions loads and validates the config at path like Load, applying
+// opts while building it. All problems found are returned together as Errors.
+func LoadWithOptions(path string, opts LoadOptions) (*Config, error) {
+	layer, err := loadLayer(path, nil, false)
+	if err != nil {
+		return nil, AsErrors(err, path)
 	}
+	cfg := layer.cfg
+	p := &problems{file: layer.file, positions: layer.positions, errs: layer.errs}
+	interpolateConfig(&cfg, opts.Vars, p)
 
 	if cfg.DefaultEntrypoint == "" {
-		return nil, fmt.Errorf("default_entrypoint is required")
+		p.addf("", "default_entrypoint is required")
 	}
 
 	if cfg.Schedule != "" {
-		if _, err := cron.ParseStandard(cfg.Schedule); err != nil {
-			return nil, fmt.Errorf("invalid schedule %q: %w", cfg.Schedule, err)
+		if _, err := cfg.CronSchedule(); err != nil {
+			p.addf("schedule", "invalid schedule %q: %w", cfg.Schedule, err)
 		}
+	} else if cfg.Timezone != "" || cfg.ScheduleJitterSeconds != 0 {
+		field := "timezone"
+		if cfg.Timezone == "" {
+			field = "schedule_jitter_seconds"
+		}
+		p.addf(field, "timezone and schedule_jitter_seconds require schedule")
 	}
 
+	validateTrigger(&cfg, p)
+	validateTracker(&cfg, p)
+
 	if len(cfg.Skills) == 0 {
-		return nil, fmt.Errorf("at least one skill is required")
+		p.addf("skills", "at least one skill is required")
 	}
 
 	if cfg.IdleTimeoutSeconds <= 0 {
@@ -75,21 +98,27 @@ func Load(path string) (*Config, error) {
 		defaultMaxRestarts := 2
 		cfg.MaxRestarts = &defaultMaxRestarts
 	} else if *cfg.MaxRestarts < 0 {
-		return nil, fmt.Errorf("max_restarts must be >= 0")
+		p.addf("max_restarts", "max_restarts must be >= 0")
 	}
 
-	if err := cfg.ValidateEntrypoint(cfg.DefaultEntrypoint); err != nil {
-		return nil, err
+	if cfg.DefaultEntrypoint != "" && len(cfg.Skills) > 0 {
+		if err := cfg.ValidateEntrypoint(cfg.DefaultEntrypoint); err != nil {
+			p.add("default_entrypoint", err)
+		}
 	}
 
+	resolveAgentProfiles(&cfg, p)
+
 	needsRouter := false
-	for name, skill := range cfg.Skills {
+	for _, name := range sortedKeys(cfg.Skills) {
+		skill := cfg.Skills[name]
+		skillPath := "skills." + name
 		if err := validateAgent("skill "+strconvQuote(name), skill.Agent); err != nil {
-			return nil, err
+			p.add(skillPath+".agent.runtime", err)
 		}
 
 		if len(skill.Next) == 0 {
-			return nil, fmt.Errorf("skill %q: at least one next route is required", name)
+			p.addf(skillPath, "skill %q: at least one next route is required", name)
 		}
 		if len(skill.Next) > 1 {
 			needsRouter = true
@@ -97,54 +126,63 @@ func Load(path string) (*Config, error) {
 
 		seenRouteIDs := make(map[string]struct{}, len(skill.Next))
 		for i, route := range skill.Next {
+			routePath := fmt.Sprintf("%s.next[%d]", skillPath, i)
 			if route.LegacyWhen != "" {
-				return nil, fmt.Errorf("skill %q: route[%d] uses deprecated when matcher; use id + criteria + router instead", name, i)
+				p.addf(routePath+".when", "skill %q: route[%d] uses deprecated when matcher; use id + criteria + router instead", name, i)
 			}
 			if strings.TrimSpace(route.ID) == "" {
-				return nil, fmt.Errorf("skill %q: route[%d] requires id", name, i)
-			}
-			if _, ok := seenRouteIDs[route.ID]; ok {
-				return nil, fmt.Errorf("skill %q: route[%d] reuses id %q", name, i, route.ID)
+				p.addf(routePath, "skill %q: route[%d] requires id", name, i)
+			} else if _, ok := seenRouteIDs[route.ID]; ok {
+				p.addf(routePath+".id", "skill %q: route[%d] reuses id %q", name, i, route.ID)
 			}
 			seenRouteIDs[route.ID] = struct{}{}
+			if route.Tracker != nil {
+				if err := validateTrackerAction(&cfg, route.Tracker); err != nil {
+					p.addf(routePath+".tracker", "skill %q: route[%d]: %w", name, i, err)
+				}
+			}
 			if route.Skill == "<DONE>" {
-				return nil, fmt.Errorf("skill %q: route[%d] uses deprecated <DONE>; use done: true instead", name, i)
+				p.addf(routePath+".skill", "skill %q: route[%d] uses deprecated <DONE>; use done: true instead", name, i)
+				continue
 			}
 			if len(skill.Next) > 1 && strings.TrimSpace(route.Criteria) == "" {
-				return nil, fmt.Errorf("skill %q: route[%d] requires criteria when multiple routes are present", name, i)
+				p.addf(routePath, "skill %q: route[%d] requires criteria when multiple routes are present", name, i)
 			}
 			if route.Done {
 				if route.Blocked {
-					return nil, fmt.Errorf("skill %q: route[%d] cannot set both done and blocked", name, i)
+					p.addf(routePath+".blocked", "skill %q: route[%d] cannot set both done and blocked", name, i)
 				}
 				if route.Skill != "" {
-					return nil, fmt.Errorf("skill %q: route[%d] cannot set both done and skill", name, i)
+					p.addf(routePath+".skill", "skill %q: route[%d] cannot set both done and skill", name, i)
 				}
 				continue
 			}
 			if route.Blocked && route.Skill == "" {
-				return nil, fmt.Errorf("skill %q: route[%d] must set skill when blocked is true", name, i)
+				p.addf(routePath+".blocked", "skill %q: route[%d] must set skill when blocked is true", name, i)
+				continue
 			}
 			if route.Skill == "" {
-				return nil, fmt.Errorf("skill %q: route[%d] must set either skill or done", name, i)
+				p.addf(routePath, "skill %q: route[%d] must set either skill or done", name, i)
+				continue
 			}
 			if _, ok := cfg.Skills[route.Skill]; !ok {
-				return nil, fmt.Errorf("skill %q: route[%d] references unknown skill %q", name, i, route.Skill)
+				p.addf(routePath+".skill", "skill %q: route[%d] references unknown skill %q", name, i, route.Skill)
 			}
 		}
 	}
 
-	if needsRouter {
-		if isZeroAgent(cfg.Router) {
-			return nil, fmt.Errorf("router is required when any skill defines multiple next routes")
-		}
+	if needsRouter && isZeroAgent(cfg.Router) {
+		p.addf("", "router is required when any skill defines multiple next routes")
 	}
 	if !isZeroAgent(cfg.Router) {
 		if err := validateAgent("router", cfg.Router); err != nil {
-			return nil, err
+			p.add("router.runtime", err)
 		}
 	}
 
+	if err := p.err(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -219,7 +257,7 @@ func validateAgent(label string, agent Agent) error {
 }
 
 func isZeroAgent(agent Agent) bool {
-	return agent.Runtime == "" && agent.Model == "" && len(agent.Args) == 0
+	return agent.Profile == "" && agent.Runtime == "" && agent.Model == "" && len(agent.Args) == 0
 }
 
 func strconvQuote(value string) string {
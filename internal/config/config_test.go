@@ -1,10 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadValidConfig(t *testing.T) {
@@ -339,6 +343,131 @@ skills:
 	}
 }
 
+func TestLoadScheduleTimezoneAndDescriptors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		timezone string
+		wantErr  string
+	}{
+		{name: "timezone field", schedule: "0 9 * * *", timezone: "Asia/Tokyo"},
+		{name: "CRON_TZ prefix", schedule: "CRON_TZ=Asia/Tokyo 0 9 * * *"},
+		{name: "hourly descriptor", schedule: "@hourly"},
+		{name: "every descriptor", schedule: "@every 30m"},
+		{name: "unknown timezone", schedule: "0 9 * * *", timezone: "Mars/Olympus", wantErr: "invalid timezone"},
+		{name: "timezone with prefix", schedule: "CRON_TZ=UTC 0 9 * * *", timezone: "Asia/Tokyo", wantErr: "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "schedule: \"" + tt.schedule + "\"\n"
+			if tt.timezone != "" {
+				content += "timezone: " + tt.timezone + "\n"
+			}
+			content += `default_entrypoint: check
+skills:
+  check:
+    next:
+      - id: finish
+        done: true
+`
+			cfg, err := Load(writeConfig(t, content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if _, err := cfg.CronSchedule(); err != nil {
+				t.Fatalf("CronSchedule() error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCronScheduleUsesTimezone(t *testing.T) {
+	cfg := &Config{Schedule: "0 9 * * *", Timezone: "Asia/Tokyo"}
+	schedule, err := cfg.CronSchedule()
+	if err != nil {
+		t.Fatalf("CronSchedule() error: %v", err)
+	}
+
+	next := schedule.Next(time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC))
+	want := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC) // 09:00 JST
+	if !next.Equal(want) {
+		t.Fatalf("Next() = %s, want %s", next.UTC(), want)
+	}
+	if got := cfg.ScheduleLocation().String(); got != "Asia/Tokyo" {
+		t.Fatalf("ScheduleLocation() = %q, want Asia/Tokyo", got)
+	}
+}
+
+func TestCronScheduleOffsetIsBoundedAndStable(t *testing.T) {
+	cfg := &Config{Name: "nightly", Schedule: "CRON_TZ=UTC 0 * * * *", ScheduleJitterSeconds: 600}
+	schedule, err := cfg.CronSchedule()
+	if err != nil {
+		t.Fatalf("CronSchedule() error: %v", err)
+	}
+
+	start := time.Date(2026, 3, 7, 0, 30, 0, 0, time.UTC)
+	base := time.Date(2026, 3, 7, 1, 0, 0, 0, time.UTC)
+	first := schedule.Next(start)
+	if first.Before(base) || !first.Before(base.Add(10*time.Minute)) {
+		t.Fatalf("Next() = %s, want within [%s, +10m)", first, base)
+	}
+	if want := base.Add(cfg.ScheduleOffset()); !first.Equal(want) {
+		t.Fatalf("Next() = %s, want the hour delayed by ScheduleOffset() to %s", first, want)
+	}
+	if again := schedule.Next(start); !again.Equal(first) {
+		t.Fatalf("Next() = %s on second call, want deterministic %s", again, first)
+	}
+	if second := schedule.Next(first); !second.After(base.Add(time.Hour).Add(-time.Nanosecond)) {
+		t.Fatalf("Next(first) = %s, want the following hour", second)
+	}
+	if pending := schedule.Next(first.Add(-time.Second)); !pending.Equal(first) {
+		t.Fatalf("Next() inside the offset = %s, want the pending %s", pending, first)
+	}
+}
+
+func TestCronScheduleOffsetKeepsEveryInterval(t *testing.T) {
+	cfg := &Config{Name: "poll", Schedule: "@every 1h", ScheduleJitterSeconds: 600}
+	schedule, err := cfg.CronSchedule()
+	if err != nil {
+		t.Fatalf("CronSchedule() error: %v", err)
+	}
+
+	start := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	first := schedule.Next(start)
+	if first.Before(start.Add(time.Hour)) || !first.Before(start.Add(time.Hour+10*time.Minute)) {
+		t.Fatalf("Next() = %s, want within [%s, +10m)", first, start.Add(time.Hour))
+	}
+	fire := first
+	for i := 0; i < 5; i++ {
+		next := schedule.Next(fire)
+		if got := next.Sub(fire); got != time.Hour {
+			t.Fatalf("Next(%s) = %s, %s later, want 1h", fire, next, got)
+		}
+		fire = next
+	}
+}
+
+func TestLoadRejectsTimezoneWithoutSchedule(t *testing.T) {
+	_, err := Load(writeConfig(t, `timezone: Asia/Tokyo
+default_entrypoint: check
+skills:
+  check:
+    next:
+      - id: finish
+        done: true
+`))
+	if err == nil || !strings.Contains(err.Error(), "require schedule") {
+		t.Fatalf("Load() error = %v, want require schedule", err)
+	}
+}
+
 func TestLoadNonexistentFile(t *testing.T) {
 	_, err := Load("/nonexistent/path/config.yml")
 	if err == nil {
@@ -469,6 +598,92 @@ skills:
 	}
 }
 
+func TestLoadWatchTrigger(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `default_entrypoint: impl
+trigger:
+  watch:
+    - docs/**/*.md
+    - go.mod
+  debounce: 2m
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := strings.Join(cfg.WatchPatterns(), ","); got != "docs/**/*.md,go.mod" {
+		t.Fatalf("WatchPatterns() = %q, want docs/**/*.md,go.mod", got)
+	}
+	debounce, err := cfg.WatchDebounce()
+	if err != nil {
+		t.Fatalf("WatchDebounce() error: %v", err)
+	}
+	if debounce != 2*time.Minute {
+		t.Fatalf("WatchDebounce() = %s, want 2m", debounce)
+	}
+
+	if got, _ := (&Config{Trigger: &Trigger{Watch: []string{"src"}}}).WatchDebounce(); got != DefaultWatchDebounce {
+		t.Fatalf("default WatchDebounce() = %s, want %s", got, DefaultWatchDebounce)
+	}
+}
+
+func TestLoadRejectsInvalidWatchTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger string
+		want    string
+	}{
+		{name: "no paths", trigger: "trigger:\n  debounce: 10s\n", want: "trigger requires watch or webhook"},
+		{name: "bad debounce", trigger: "trigger:\n  watch: [src]\n  debounce: soon\n", want: "invalid trigger.debounce"},
+		{name: "bad pattern", trigger: "trigger:\n  watch: [\"src/[\"]\n", want: "invalid pattern"},
+		{name: "bad webhook prompt", trigger: "trigger:\n  webhook:\n    prompt: \"{{ .Payload\"\n", want: "invalid trigger.webhook.prompt"},
+		{name: "combined with schedule", trigger: "schedule: \"@hourly\"\ntrigger:\n  watch: [src]\n", want: "schedule and trigger.watch cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, "default_entrypoint: impl\n"+tt.trigger+`skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWebhookTrigger(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `default_entrypoint: impl
+schedule: "@daily"
+trigger:
+  webhook:
+    prompt: "Investigate {{ .Payload.ref }}"
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !cfg.WebhookEnabled() {
+		t.Fatal("WebhookEnabled() = false, want true")
+	}
+	if len(cfg.WatchPatterns()) != 0 {
+		t.Fatalf("WatchPatterns() = %v, want none", cfg.WatchPatterns())
+	}
+	tmpl, err := cfg.WebhookPromptTemplate()
+	if err != nil || tmpl == nil {
+		t.Fatalf("WebhookPromptTemplate() = %v, %v, want parsed template", tmpl, err)
+	}
+}
+
 func writeConfig(t *testing.T, content string) string {
 	t.Helper()
 
@@ -478,3 +693,626 @@ func writeConfig(t *testing.T, content string) string {
 	}
 	return cfgFile
 }
+
+func TestLoadMergesExtendsAndIncludes(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.yml": `name: base
+default_entrypoint: impl
+max_iterations: 5
+router:
+  runtime: codex
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+  review:
+    next:
+      - id: finish
+        done: true
+`,
+		"shared/review.yml": `router:
+  runtime: claude
+  model: opus
+agents:
+  reviewer:
+    runtime: codex
+skills:
+  review:
+    agent:
+      profile: reviewer
+      model: shared-review
+    next:
+      - id: approve
+        criteria: Approved
+        done: true
+      - id: rework
+        criteria: Needs work
+        skill: impl
+`,
+		"skill-loop.yml": `extends: base.yml
+include:
+  - shared/review.yml
+max_iterations: 20
+skills:
+  impl:
+    agent:
+      model: local-impl
+    next:
+      - id: review
+        skill: review
+`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "skill-loop.yml"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Name != "" {
+		t.Errorf("Name = %q, want it not inherited", cfg.Name)
+	}
+	if cfg.DefaultEntrypoint != "impl" {
+		t.Errorf("DefaultEntrypoint = %q, want %q", cfg.DefaultEntrypoint, "impl")
+	}
+	if cfg.MaxIterations != 20 {
+		t.Errorf("MaxIterations = %d, want %d", cfg.MaxIterations, 20)
+	}
+	if cfg.Router.Model != "opus" {
+		t.Errorf("Router.Model = %q, want the included router", cfg.Router.Model)
+	}
+	if got := cfg.Skills["impl"].Agent.Model; got != "local-impl" {
+		t.Errorf("Skills[impl].Agent.Model = %q, want %q", got, "local-impl")
+	}
+	if got := cfg.Skills["review"].Agent; got.Model != "shared-review" || got.Runtime != "codex" {
+		t.Errorf("Skills[review].Agent = %+v, want the included reviewer profile", got)
+	}
+}
+
+func TestLoadDetectsIncludeCycles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"skill-loop.yml": "extends: a.yml\n",
+		"a.yml":          "include: [b.yml]\n",
+		"b.yml":          "include: [a.yml]\n",
+	})
+
+	_, err := Load(filepath.Join(dir, "skill-loop.yml"))
+	if err == nil || !strings.Contains(err.Error(), "config include cycle") {
+		t.Fatalf("Load() error = %v, want include cycle", err)
+	}
+	if !strings.Contains(err.Error(), "a.yml -> "+filepath.Join(dir, "b.yml")+" -> "+filepath.Join(dir, "a.yml")) {
+		t.Fatalf("Load() error = %v, want the cycle path", err)
+	}
+}
+
+func TestLoadRejectsConflictingIncludes(t *testing.T) {
+	fragment := `skills:
+  review:
+    next:
+      - id: finish
+        done: true
+`
+	dir := writeConfigFiles(t, map[string]string{
+		"one.yml":    fragment,
+		"two.yml":    fragment,
+		"common.yml": "include: [one.yml]\n",
+		"skill-loop.yml": `default_entrypoint: review
+include: [one.yml, two.yml]
+`,
+		"diamond.yml": `default_entrypoint: review
+include: [one.yml, common.yml]
+`,
+	})
+
+	_, err := Load(filepath.Join(dir, "skill-loop.yml"))
+	if err == nil || !strings.Contains(err.Error(), `skill "review" is included from both`) {
+		t.Fatalf("Load() error = %v, want conflicting skill", err)
+	}
+	if _, err := Load(filepath.Join(dir, "diamond.yml")); err != nil {
+		t.Fatalf("Load() error for a fragment included twice: %v", err)
+	}
+}
+
+func TestLoadRejectsWorkflowFieldsInIncludedFile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"fragment.yml": "skills: {}\ndefault_entrypoint: impl\n",
+		"skill-loop.yml": `include: [fragment.yml]
+default_entrypoint: impl
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`,
+	})
+
+	_, err := Load(filepath.Join(dir, "skill-loop.yml"))
+	if err == nil || !strings.Contains(err.Error(), `fragment.yml:2:1: default_entrypoint cannot be set in an included file`) {
+		t.Fatalf("Load() error = %v, want rejected key", err)
+	}
+}
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("failed to create test dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoadResolvesAgentProfiles(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `default_entrypoint: impl
+agents:
+  fast:
+    runtime: codex
+    model: gpt-5.4-mini
+    args: ["--full-auto"]
+  careful:
+    runtime: claude
+    model: claude-opus-4.6
+router:
+  profile: fast
+skills:
+  impl:
+    agent:
+      profile: careful
+      args: ["--dangerously-skip-permissions"]
+    next:
+      - id: review
+        criteria: Ready for review
+        skill: review
+      - id: finish
+        criteria: Nothing left to do
+        done: true
+  review:
+    agent:
+      profile: fast
+      model: gpt-5.4
+    next:
+      - id: finish
+        done: true
+`))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	impl := cfg.Skills["impl"].Agent
+	if impl.Runtime != "claude" || impl.Model != "claude-opus-4.6" || strings.Join(impl.Args, " ") != "--dangerously-skip-permissions" {
+		t.Errorf("Skills[impl].Agent = %+v, want careful profile with overridden args", impl)
+	}
+	review := cfg.Skills["review"].Agent
+	if review.Runtime != "codex" || review.Model != "gpt-5.4" || strings.Join(review.Args, " ") != "--full-auto" {
+		t.Errorf("Skills[review].Agent = %+v, want fast profile with overridden model", review)
+	}
+	if cfg.Router.Runtime != "codex" || cfg.Router.Model != "gpt-5.4-mini" {
+		t.Errorf("Router = %+v, want fast profile", cfg.Router)
+	}
+}
+
+func TestLoadRejectsInvalidAgentProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "unknown profile",
+			content: `default_entrypoint: impl
+skills:
+  impl:
+    agent:
+      profile: missing
+    next:
+      - id: finish
+        done: true
+`,
+			want: `skill "impl": unknown agent profile "missing"`,
+		},
+		{
+			name: "unsupported runtime",
+			content: `default_entrypoint: impl
+agents:
+  broken:
+    runtime: unknown
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`,
+			want: `agent profile "broken": unsupported agent runtime "unknown"`,
+		},
+		{
+			name: "nested profile",
+			content: `default_entrypoint: impl
+agents:
+  base:
+    runtime: codex
+  derived:
+    profile: base
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`,
+			want: `agent profile "derived": profile cannot reference another profile`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadInterpolatesVars(t *testing.T) {
+	t.Setenv("SKILL_LOOP_TEST_MODEL", "gpt-5.4")
+	t.Setenv("SKILL_LOOP_TEST_TOKEN", "secret")
+
+	path := writeConfig(t, `default_entrypoint: impl
+vars:
+  repo: acme/api
+  model: ${env:SKILL_LOOP_TEST_MODEL}
+  branch: ${env:SKILL_LOOP_TEST_UNSET:-main}
+router:
+  runtime: codex
+  model: ${model}
+skills:
+  impl:
+    agent:
+      args: ["--repo=${repo}", "--token=${env:SKILL_LOOP_TEST_TOKEN}", "--literal=$${HOME}"]
+    next:
+      - id: finish
+        criteria: All issues in ${repo} on ${branch} are resolved
+        done: true
+      - id: again
+        criteria: Work remains
+        skill: impl
+`)
+
+	cfg, err := LoadWithOptions(path, LoadOptions{Vars: map[string]string{"repo": "acme/web"}})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() error: %v", err)
+	}
+	if cfg.Router.Model != "gpt-5.4" {
+		t.Errorf("Router.Model = %q, want %q", cfg.Router.Model, "gpt-5.4")
+	}
+	if got, want := strings.Join(cfg.Skills["impl"].Agent.Args, " "), "--repo=acme/web --token=secret --literal=${HOME}"; got != want {
+		t.Errorf("Args = %q, want %q", got, want)
+	}
+	if got, want := cfg.Skills["impl"].Next[0].Criteria, "All issues in acme/web on main are resolved"; got != want {
+		t.Errorf("Criteria = %q, want %q", got, want)
+	}
+	if cfg.Vars["repo"] != "acme/web" {
+		t.Errorf("Vars[repo] = %q, want the override", cfg.Vars["repo"])
+	}
+	if value := cfg.Env["SKILL_LOOP_TEST_TOKEN"]; value == nil || *value != "secret" {
+		t.Errorf("Env[SKILL_LOOP_TEST_TOKEN] = %v, want %q", value, "secret")
+	}
+	if value, ok := cfg.Env["SKILL_LOOP_TEST_UNSET"]; !ok || value != nil {
+		t.Errorf("Env[SKILL_LOOP_TEST_UNSET] = %v, %v, want recorded as unset", value, ok)
+	}
+}
+
+func TestLoadRejectsInvalidVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		want    string
+	}{
+		{
+			name:    "undefined variable",
+			content: "default_entrypoint: impl\nskills:\n  impl:\n    agent:\n      model: ${model}\n    next:\n      - id: finish\n        done: true\n",
+			want:    `skills.impl.agent.model: undefined variable "model"`,
+		},
+		{
+			name:    "unset environment variable",
+			content: "default_entrypoint: impl\nvars:\n  token: ${env:SKILL_LOOP_TEST_UNSET}\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n",
+			want:    "vars.token: environment variable SKILL_LOOP_TEST_UNSET is not set",
+		},
+		{
+			name:    "invalid environment variable name",
+			content: "default_entrypoint: impl\nvars:\n  token: ${env:API TOKEN:-x}\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n",
+			want:    `vars.token: invalid environment variable name "API TOKEN" in ${env:API TOKEN:-x}`,
+		},
+		{
+			name:    "undeclared override",
+			content: "default_entrypoint: impl\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n",
+			vars:    map[string]string{"repo": "acme/api"},
+			want:    `variable "repo" is not declared in vars`,
+		},
+		{
+			name:    "var referencing var",
+			content: "default_entrypoint: impl\nvars:\n  a: x\n  b: ${a}\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n",
+			want:    "vars may only reference the environment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWithOptions(writeConfig(t, tt.content), LoadOptions{Vars: tt.vars})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadWithOptions() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVarAssignments(t *testing.T) {
+	vars, err := ParseVarAssignments([]string{"repo=acme/api", "filter=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("ParseVarAssignments() error: %v", err)
+	}
+	if vars["repo"] != "acme/api" || vars["filter"] != "a=b" || vars["empty"] != "" {
+		t.Fatalf("ParseVarAssignments() = %v", vars)
+	}
+	if _, err := ParseVarAssignments([]string{"novalue"}); err == nil {
+		t.Fatal("ParseVarAssignments() expected error for missing =")
+	}
+}
+
+func TestLoadTracker(t *testing.T) {
+	path := writeConfig(t, `default_entrypoint: issue-check
+tracker:
+  repo: acme/api
+  labels: [ready]
+skills:
+  issue-check:
+    next:
+      - id: start
+        skill: impl
+        tracker:
+          claim: true
+  impl:
+    next:
+      - id: ship
+        done: true
+        tracker:
+          comment: "Fixed in {{.Skill}}"
+          close: true
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Tracker == nil || cfg.Tracker.Repo != "acme/api" {
+		t.Fatalf("Tracker = %+v, want repo acme/api", cfg.Tracker)
+	}
+	if got := cfg.Tracker.EffectiveClaimLabel(); got != DefaultClaimLabel {
+		t.Fatalf("EffectiveClaimLabel() = %q, want %q", got, DefaultClaimLabel)
+	}
+	if got := cfg.Tracker.EffectiveReadyLimit(); got != DefaultReadyLimit {
+		t.Fatalf("EffectiveReadyLimit() = %d, want %d", got, DefaultReadyLimit)
+	}
+	if action := cfg.Skills["issue-check"].Next[0].Tracker; action == nil || !action.Claim {
+		t.Fatalf("issue-check route tracker = %+v, want claim", action)
+	}
+	if action := cfg.Skills["impl"].Next[0].Tracker; action == nil || !action.Close || action.Comment != "Fixed in {{.Skill}}" {
+		t.Fatalf("impl route tracker = %+v, want comment and close", action)
+	}
+}
+
+func TestLoadRejectsInvalidTracker(t *testing.T) {
+	route := "    next:\n      - id: finish\n        done: true\n"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unsupported provider",
+			content: "default_entrypoint: impl\ntracker:\n  provider: jira\n  repo: acme/api\nskills:\n  impl:\n" + route,
+			want:    `tracker: unsupported provider "jira"`,
+		},
+		{
+			name:    "repo without owner",
+			content: "default_entrypoint: impl\ntracker:\n  repo: api\nskills:\n  impl:\n" + route,
+			want:    `tracker.repo must be owner/repo, got "api"`,
+		},
+		{
+			name:    "action without tracker",
+			content: "default_entrypoint: impl\nskills:\n  impl:\n" + route + "        tracker:\n          claim: true\n",
+			want:    `skill "impl": route[0]: tracker actions require a top-level tracker`,
+		},
+		{
+			name:    "empty action",
+			content: "default_entrypoint: impl\ntracker:\n  repo: acme/api\nskills:\n  impl:\n" + route + "        tracker: {}\n",
+			want:    "tracker action must set claim, comment or close",
+		},
+		{
+			name:    "invalid comment template",
+			content: "default_entrypoint: impl\ntracker:\n  repo: acme/api\nskills:\n  impl:\n" + route + "        tracker:\n          comment: \"{{.Skill\"\n",
+			want:    "invalid tracker.comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	_, err := Load(writeConfig(t, `default_entrypoint: impl
+max_iteration: 5
+skills:
+  impl:
+    agent:
+      modle: claude-sonnet-4.6
+    next:
+      - id: finish
+        criterea: "Done"
+        done: true
+tracker:
+  repo: acme/api
+  lables: [ready]
+`))
+	if err == nil {
+		t.Fatal("Load() expected error for unknown keys")
+	}
+	for _, want := range []string{
+		`config.yml:2:1: unknown key "max_iteration" at the top level (did you mean "max_iterations"?)`,
+		`config.yml:6:7: unknown key "modle" in skills.impl.agent (did you mean "model"?)`,
+		`config.yml:9:9: unknown key "criterea" in skills.impl.next[0] (did you mean "criteria"?)`,
+		`config.yml:13:3: unknown key "lables" in tracker (did you mean "labels"?)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestLoadUnknownKeyWithoutSuggestion(t *testing.T) {
+	_, err := Load(writeConfig(t, "default_entrypoint: impl\nowner: platform-team\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n"))
+	want := `config.yml:2:1: unknown key "owner" at the top level`
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("Load() error = %v, want %q without a suggestion", err, want)
+	}
+}
+
+func TestLoadIgnoresExtensionKeys(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `x-agent: &agent
+  runtime: codex
+  model: gpt-5.4
+default_entrypoint: impl
+skills:
+  impl:
+    x-owner: platform-team
+    agent:
+      <<: *agent
+      args: ["--full-auto"]
+    next:
+      - id: finish
+        done: true
+        x-note: "reviewed by the router"
+`))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	agent := cfg.Skills["impl"].Agent
+	if agent.Runtime != "codex" || agent.Model != "gpt-5.4" || len(agent.Args) != 1 {
+		t.Fatalf("impl agent = %+v, want the merged anchor", agent)
+	}
+}
+
+func TestLoadReportsAllErrorsWithPositions(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"review.yml": `skills:
+  review:
+    agent:
+      runtime: gemini
+    next:
+      - id: approve
+        done: true
+`,
+		"skill-loop.yml": `include: [review.yml]
+default_entrypoint: impl
+max_restarts: -1
+skills:
+  impl:
+    next:
+      - id: review
+        criteria: "Ready"
+        skill: review
+      - id: review
+        criteria: "Again"
+        skill: reveiw
+`,
+	})
+
+	_, err := Load(filepath.Join(dir, "skill-loop.yml"))
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want Errors", err)
+	}
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, fmt.Sprintf("%s:%d:%d %s", filepath.Base(e.File), e.Line, e.Column, e.Path))
+	}
+	want := []string{
+		"review.yml:4:7 skills.review.agent.runtime",
+		"skill-loop.yml:0:0 ",
+		"skill-loop.yml:3:1 max_restarts",
+		"skill-loop.yml:10:9 skills.impl.next[1].id",
+		"skill-loop.yml:12:9 skills.impl.next[1].skill",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Load() errors =\n%s\nwant\n%s\n(%v)", strings.Join(got, "\n"), strings.Join(want, "\n"), err)
+	}
+	if !strings.HasPrefix(err.Error(), "5 problems in config:\n") {
+		t.Fatalf("Load() error = %q, want a summary line", err.Error())
+	}
+}
+
+func TestLoadReportsSyntaxErrorLine(t *testing.T) {
+	path := writeConfig(t, "default_entrypoint: impl\nskills:\n  impl:\n    next: [\n")
+
+	_, err := Load(path)
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Line == 0 {
+		t.Fatalf("Load() error = %v, want one error with a line", err)
+	}
+	if !strings.HasPrefix(err.Error(), path+":") {
+		t.Fatalf("Load() error = %q, want it to start with the file", err.Error())
+	}
+}
+
+func TestDiscoverWalksTreeSkippingIgnored(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := writeConfigFiles(t, map[string]string{
+		".gitignore":                      "build/\nskill-loop.local.yml\n",
+		"skill-loop.yml":                  "",
+		"skill-loop.local.yml":            "",
+		"services/api/skill-loop-ci.yaml": "",
+		"services/api/workflow.yml":       "",
+		"build/skill-loop.yml":            "",
+		"docs/skill-loop.example.yml":     "",
+		"docs/skill-loopy.yml":            "",
+		"docs/skill-loop-.yml":            "",
+		"docs/skill-loop.yml.bak":         "",
+	})
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init error: %v: %s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "skill-loop.yml"), nil, 0o600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	paths, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	var got []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			t.Fatalf("Rel() error: %v", err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := "docs/skill-loop.example.yml services/api/skill-loop-ci.yaml skill-loop.yml"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("Discover() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
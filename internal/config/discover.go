@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Discover returns the workflow configs under dir: skill-loop.yml and any
+// skill-loop-*.yml or skill-loop.*.yml files (.yaml works too) in dir or
+// its subdirectories. .git and, when dir is in a git repository, the
+// directories and files git ignores are skipped.
+func Discover(dir string) ([]string, error) {
+	ignored := IgnoredPaths(dir)
+
+	var paths []string
+	err := filepath.WalkDir(dir, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if current == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" || ignored[rel+"/"] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignored[rel] || !isConfigName(entry.Name()) {
+			return nil
+		}
+		paths = append(paths, current)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discover configs: %w", err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func isConfigName(name string) bool {
+	for _, ext := range []string{".yml", ".yaml"} {
+		stem, ok := strings.CutSuffix(name, ext)
+		if !ok {
+			continue
+		}
+		if stem == "skill-loop" {
+			return true
+		}
+		for _, prefix := range []string{"skill-loop-", "skill-loop."} {
+			if rest, ok := strings.CutPrefix(stem, prefix); ok && rest != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IgnoredPaths returns the paths under dir that git ignores, relative to dir
+// with slashes; directories end in a slash. Outside a git repository, or
+// without git, nothing is ignored.
+func IgnoredPaths(dir string) map[string]bool {
+	cmd := exec.Command("git", "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	ignored := make(map[string]bool)
+	for _, path := range bytes.Split(out, []byte{0}) {
+		if len(path) > 0 {
+			ignored[string(path)] = true
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Position is a location in a config file. Line and Column are 1-based and
+// zero when unknown.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Error is a problem found while loading a config, located at the YAML node it
+// concerns when that is known.
+type Error struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error formats e like a compiler diagnostic: file:line:column: message.
+func (e *Error) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+}
+
+// Errors are all the problems found in a config, ordered by file and
+// position. Load and LoadWithOptions return Errors for invalid configs.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("%d problems in config:", len(e)))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AsErrors returns the config errors in err. Errors that do not come from a
+// config are returned as a single Error for file without a position.
+func AsErrors(err error, file string) Errors {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return Errors{{File: file, Message: err.Error()}}
+}
+
+// positions maps config paths such as skills.impl.next[0].criteria to the
+// location of the YAML node that set them.
+type positions map[string]Position
+
+// lookup returns the position of path or, when the path was not written in
+// the file, of its closest written parent.
+func (p positions) lookup(path string) (Position, bool) {
+	for path != "" {
+		if pos, ok := p[path]; ok {
+			return pos, true
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return Position{}, false
+}
+
+func (p positions) merge(other positions) {
+	for path, pos := range other {
+		p[path] = pos
+	}
+}
+
+// problems collects the errors found while validating a config.
+type problems struct {
+	file      string
+	positions positions
+	errs      Errors
+}
+
+// add records err for the config path it concerns. An empty path refers to
+// the config as a whole.
+func (p *problems) add(path string, err error) {
+	var errs Errors
+	if errors.As(err, &errs) {
+		p.errs = append(p.errs, errs...)
+		return
+	}
+	e := &Error{File: displayPath(p.file), Path: path, Message: err.Error()}
+	if pos, ok := p.positions.lookup(path); ok {
+		e.File, e.Line, e.Column = displayPath(pos.File), pos.Line, pos.Column
+	}
+	p.errs = append(p.errs, e)
+}
+
+func (p *problems) addf(path string, format string, args ...any) {
+	p.add(path, fmt.Errorf(format, args...))
+}
+
+// err returns the collected errors sorted by position, or nil when there are
+// none.
+func (p *problems) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(p.errs, func(i, j int) bool {
+		a, b := p.errs[i], p.errs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return p.errs
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+):? `)
+
+// syntaxError converts a YAML parse error into an Error at the line it
+// reports.
+func syntaxError(file string, err error) error {
+	e := &Error{File: displayPath(file), Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if m := yamlLinePattern.FindStringSubmatch(e.Message); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Message = strings.Replace(e.Message, m[0], "", 1)
+	}
+	return Errors{e}
+}
+
+// displayPath shortens an absolute config path to one relative to the
+// working directory when the file is below it.
+func displayPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fragmentKeys are the top-level keys an included file may set.
+var fragmentKeys = []string{"agents", "include", "router", "skills", "vars"}
+
+// configFields are the top-level keys of a config. Keys that are not among
+// them are reported by scanKeys.
+var configFields = yamlFields(reflect.TypeFor[Config]())
+
+// configLayer is a config file merged with everything it extends and
+// includes, along with the file each skill, agent profile, variable and the
+// router came from, the position of every field and the problems that did
+// not stop the files from loading.
+type configLayer struct {
+	cfg          Config
+	file         string
+	skillSources map[string]string
+	agentSources map[string]string
+	varSources   map[string]string
+	routerSource string
+	positions    positions
+	errs         Errors
+}
+
+// loadLayer reads the config at path and resolves its extends and include
+// references, relative to the file that declares them. Fields are merged with
+// increasing precedence: the extended base, then included files in order, then
+// the file itself. Skills, agent profiles and vars are replaced by name;
+// the router and other fields are replaced when set. The name field is never
+// inherited. stack holds the files being loaded so that cycles can be
+// reported.
+func loadLayer(path string, stack []string, fragment bool) (*configLayer, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve config path: %w", err)
+	}
+	if i := slices.Index(stack, absPath); i >= 0 {
+		chain := append(slices.Clone(stack[i:]), absPath)
+		return nil, fmt.Errorf("config include cycle: %s", strings.Join(chain, " -> "))
+	}
+	stack = append(slices.Clone(stack), absPath)
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, syntaxError(absPath, err)
+	}
+	var own Config
+	ownPositions := make(positions)
+	var errs Errors
+	if len(doc.Content) > 0 {
+		if fragment {
+			errs = append(errs, checkFragmentKeys(doc.Content[0], absPath)...)
+		}
+		var unknown Errors
+		ownPositions, unknown = scanKeys(doc.Content[0], reflect.TypeFor[Config](), absPath)
+		errs = append(errs, unknown...)
+		if err := doc.Content[0].Decode(&own); err != nil {
+			return nil, append(errs, syntaxError(absPath, err).(Errors)...)
+		}
+	}
+
+	layer := &configLayer{
+		skillSources: make(map[string]string),
+		agentSources: make(map[string]string),
+		varSources:   make(map[string]string),
+		positions:    make(positions),
+	}
+	dir := filepath.Dir(absPath)
+	if own.Extends != "" {
+		base, err := loadLayer(resolveRef(dir, own.Extends), stack, false)
+		if err != nil {
+			return nil, refError(errs, ownPositions, "extends", fmt.Sprintf("extends %q", own.Extends), absPath, err)
+		}
+		layer = base
+	}
+	layer.errs = append(layer.errs, errs...)
+
+	includedSkills := make(map[string]string)
+	includedAgents := make(map[string]string)
+	includedVars := make(map[string]string)
+	includedRouter := ""
+	for i, ref := range own.Include {
+		included, err := loadLayer(resolveRef(dir, ref), stack, true)
+		if err != nil {
+			return nil, refError(layer.errs, ownPositions, fmt.Sprintf("include[%d]", i), fmt.Sprintf("include %q", ref), absPath, err)
+		}
+		layer.errs = append(layer.errs, included.errs...)
+		layer.positions.merge(included.positions)
+		if err := mergeIncluded("skill", &layer.cfg.Skills, layer.skillSources, includedSkills, included.cfg.Skills, included.skillSources); err != nil {
+			return nil, refError(layer.errs, ownPositions, fmt.Sprintf("include[%d]", i), "", absPath, err)
+		}
+		if err := mergeIncluded("agent profile", &layer.cfg.Agents, layer.agentSources, includedAgents, included.cfg.Agents, included.agentSources); err != nil {
+			return nil, refError(layer.errs, ownPositions, fmt.Sprintf("include[%d]", i), "", absPath, err)
+		}
+		if err := mergeIncluded("variable", &layer.cfg.Vars, layer.varSources, includedVars, included.cfg.Vars, included.varSources); err != nil {
+			return nil, refError(layer.errs, ownPositions, fmt.Sprintf("include[%d]", i), "", absPath, err)
+		}
+		if !isZeroAgent(included.cfg.Router) {
+			if includedRouter != "" && includedRouter != included.routerSource {
+				err := fmt.Errorf("router is included from both %s and %s", includedRouter, included.routerSource)
+				return nil, refError(layer.errs, ownPositions, fmt.Sprintf("include[%d]", i), "", absPath, err)
+			}
+			includedRouter = included.routerSource
+			layer.cfg.Router = included.cfg.Router
+			layer.routerSource = included.routerSource
+		}
+	}
+
+	layer.overlay(&own, absPath)
+	layer.positions.merge(ownPositions)
+	layer.file = absPath
+	return layer, nil
+}
+
+// refError reports err for the reference at path in the file source, such as
+// include[0], prefixed with label when it is set. Problems found inside the
+// referenced file keep their own positions.
+func refError(errs Errors, own positions, path string, label string, source string, err error) error {
+	var inner Errors
+	if errors.As(err, &inner) {
+		return append(errs, inner...)
+	}
+	if label != "" {
+		err = fmt.Errorf("%s: %w", label, err)
+	}
+	p := &problems{file: source, positions: own, errs: errs}
+	p.add(path, err)
+	return p.errs
+}
+
+// mergeIncluded adds the entries of an included file to dst. included maps
+// the entries taken from earlier includes of the same file to their source, so
+// that two includes defining the same entry are rejected unless both got it
+// from the same file.
+func mergeIncluded[V any](kind string, dst *map[string]V, sources map[string]string, included map[string]string, entries map[string]V, entrySources map[string]string) error {
+	for _, name := range sortedKeys(entries) {
+		source := entrySources[name]
+		if previous, ok := included[name]; ok && previous != source {
+			return fmt.Errorf("%s %q is included from both %s and %s", kind, name, previous, source)
+		}
+		included[name] = source
+		setEntry(dst, sources, name, entries[name], source)
+	}
+	return nil
+}
+
+func setEntry[V any](dst *map[string]V, sources map[string]string, name string, value V, source string) {
+	if *dst == nil {
+		*dst = make(map[string]V)
+	}
+	(*dst)[name] = value
+	sources[name] = source
+}
+
+// overlay applies the fields set in own, the file at source, on top of the
+// merged layers.
+func (l *configLayer) overlay(own *Config, source string) {
+	cfg := &l.cfg
+	cfg.Name = own.Name
+	cfg.Extends = own.Extends
+	cfg.Include = own.Include
+	if own.Schedule != "" {
+		cfg.Schedule = own.Schedule
+	}
+	if own.Timezone != "" {
+		cfg.Timezone = own.Timezone
+	}
+	if own.ScheduleJitterSeconds != 0 {
+		cfg.ScheduleJitterSeconds = own.ScheduleJitterSeconds
+	}
+	if own.Trigger != nil {
+		cfg.Trigger = own.Trigger
+	}
+	if own.Tracker != nil {
+		cfg.Tracker = own.Tracker
+	}
+	if !isZeroAgent(own.Router) {
+		cfg.Router = own.Router
+		l.routerSource = source
+	}
+	if own.DefaultEntrypoint != "" {
+		cfg.DefaultEntrypoint = own.DefaultEntrypoint
+	}
+	if own.MaxIterations != 0 {
+		cfg.MaxIterations = own.MaxIterations
+	}
+	if own.IdleTimeoutSeconds != 0 {
+		cfg.IdleTimeoutSeconds = own.IdleTimeoutSeconds
+	}
+	if own.MaxRestarts != nil {
+		cfg.MaxRestarts = own.MaxRestarts
+	}
+	for name, skill := range own.Skills {
+		setEntry(&cfg.Skills, l.skillSources, name, skill, source)
+	}
+	for name, agent := range own.Agents {
+		setEntry(&cfg.Agents, l.agentSources, name, agent, source)
+	}
+	for name, value := range own.Vars {
+		setEntry(&cfg.Vars, l.varSources, name, value, source)
+	}
+}
+
+func checkFragmentKeys(node *yaml.Node, file string) Errors {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var errs Errors
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if _, known := configFields[key.Value]; known && !slices.Contains(fragmentKeys, key.Value) {
+			errs = append(errs, &Error{
+				File:    displayPath(file),
+				Line:    key.Line,
+				Column:  key.Column,
+				Path:    key.Value,
+				Message: fmt.Sprintf("%s cannot be set in an included file (allowed: %s)", key.Value, strings.Join(fragmentKeys, ", ")),
+			})
+		}
+	}
+	return errs
+}
+
+func resolveRef(dir string, ref string) string {
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(dir, ref)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
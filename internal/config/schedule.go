@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// CronSchedule parses the workflow schedule, applying the configured timezone
+// and schedule offset. Standard 5-field expressions, descriptors such as @hourly or
+// @every 30m, and CRON_TZ=/TZ= prefixes are supported.
+func (c *Config) CronSchedule() (cron.Schedule, error) {
+	spec := strings.TrimSpace(c.Schedule)
+	if spec == "" {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+
+	if tz := strings.TrimSpace(c.Timezone); tz != "" {
+		if hasTimezonePrefix(spec) {
+			return nil, fmt.Errorf("timezone cannot be combined with a CRON_TZ= or TZ= prefix in schedule")
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		spec = "CRON_TZ=" + tz + " " + spec
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ScheduleJitterSeconds < 0 {
+		return nil, fmt.Errorf("schedule_jitter_seconds must be >= 0")
+	}
+	if offset := c.ScheduleOffset(); offset > 0 {
+		return &offsetSchedule{base: schedule, offset: offset}, nil
+	}
+	return schedule, nil
+}
+
+// ScheduleOffset returns the delay added to every scheduled run. It is not
+// jitter drawn per run: schedule_jitter_seconds bounds one stable offset in
+// [0, schedule_jitter_seconds) derived from the workflow name and schedule, so
+// workflows sharing a schedule are spread apart while each keeps exact
+// intervals and a next run that previews can report.
+func (c *Config) ScheduleOffset() time.Duration {
+	if c.ScheduleJitterSeconds <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(c.Name + "|" + c.Schedule))
+	//nolint:gosec // ScheduleJitterSeconds is positive, and the remainder is smaller than it.
+	return time.Duration(h.Sum64()%uint64(c.ScheduleJitterSeconds)) * time.Second
+}
+
+// offsetSchedule delays every fire time of the base schedule by the same
+// offset. Shifting the whole schedule keeps @every intervals exact and still
+// finds a fire time whose delayed run is pending when Next is called inside
+// its offset.
+type offsetSchedule struct {
+	base   cron.Schedule
+	offset time.Duration
+}
+
+func (s *offsetSchedule) Next(t time.Time) time.Time {
+	next := s.base.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// ScheduleLocation returns the timezone the schedule is evaluated in, taken
+// from timezone or a CRON_TZ=/TZ= prefix, falling back to the local timezone.
+func (c *Config) ScheduleLocation() *time.Location {
+	name := strings.TrimSpace(c.Timezone)
+	if spec := strings.TrimSpace(c.Schedule); name == "" && hasTimezonePrefix(spec) {
+		prefix := strings.Fields(spec)[0]
+		name = prefix[strings.Index(prefix, "=")+1:]
+	}
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func hasTimezonePrefix(spec string) bool {
+	return strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=")
+}
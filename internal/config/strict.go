@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtensionKeyPrefix marks keys that skill-loop ignores, so that configs can
+// carry tool-specific settings or YAML anchors next to known fields.
+const ExtensionKeyPrefix = "x-"
+
+// scanKeys walks the document node of file against t. It returns the
+// position of every key and sequence item, by config path, and an error for
+// every mapping key that does not name a field of t, with the closest known
+// key. Keys starting with ExtensionKeyPrefix are ignored. Map keys such as
+// skill names are not checked.
+func scanKeys(node *yaml.Node, t reflect.Type, file string) (positions, Errors) {
+	s := &keyScan{file: file, positions: make(positions)}
+	s.walk(node, t, "")
+	return s.positions, s.errs
+}
+
+type keyScan struct {
+	file      string
+	positions positions
+	errs      Errors
+}
+
+func (s *keyScan) walk(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				s.walkMerge(value, t, path)
+				continue
+			}
+			if strings.HasPrefix(key.Value, ExtensionKeyPrefix) {
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				s.errs = append(s.errs, s.unknownKey(key, path, fields))
+				continue
+			}
+			s.walk(value, field.Type, s.record(joinKeyPath(path, key.Value), key))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			s.walk(node.Content[i+1], t.Elem(), s.record(joinKeyPath(path, key.Value), key))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			s.walk(item, t.Elem(), s.record(fmt.Sprintf("%s[%d]", path, i), item))
+		}
+	}
+}
+
+// record stores the position of node for path and returns path.
+func (s *keyScan) record(path string, node *yaml.Node) string {
+	s.positions[path] = Position{File: s.file, Line: node.Line, Column: node.Column}
+	return path
+}
+
+// walkMerge checks the mappings merged into a struct with <<.
+func (s *keyScan) walkMerge(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			s.walk(item, t, path)
+		}
+		return
+	}
+	s.walk(node, t, path)
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := yamlName(field); field.IsExported() && name != "" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+func (s *keyScan) unknownKey(key *yaml.Node, path string, fields map[string]reflect.StructField) *Error {
+	where := "at the top level"
+	if path != "" {
+		where = "in " + path
+	}
+	msg := fmt.Sprintf("unknown key %q %s", key.Value, where)
+	if suggestion := closestKey(key.Value, sortedKeys(fields)); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return &Error{File: displayPath(s.file), Line: key.Line, Column: key.Column, Path: joinKeyPath(path, key.Value), Message: msg}
+}
+
+// closestKey returns the candidate within a small edit distance of key, or ""
+// when none is close enough to be a likely typo.
+func closestKey(key string, candidates []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(key), candidate)
+		if distance > max(2, len(candidate)/3) {
+			continue
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func joinKeyPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Tracker providers.
+const (
+	TrackerGitHub = "github"
+)
+
+// DefaultClaimLabel marks issues claimed by a workflow when
+// tracker.claim_label is omitted.
+const DefaultClaimLabel = "in-progress"
+
+// DefaultReadyLimit is how many ready issues are shown to skills when
+// tracker.ready_limit is omitted.
+const DefaultReadyLimit = 10
+
+type Tracker struct {
+	Provider   string   `yaml:"provider,omitempty" jsonschema:"description=Issue tracker provider. Only github is supported. Defaults to github." default:"github"`
+	Repo       string   `yaml:"repo" jsonschema:"required,description=Repository whose issues the workflow works on as owner/repo."`
+	APIURL     string   `yaml:"api_url,omitempty" jsonschema:"description=REST API base URL. Defaults to https://api.github.com. Set it for GitHub Enterprise Server."`
+	Labels     []string `yaml:"labels,omitempty" jsonschema:"description=Labels an open unassigned issue must all carry to count as ready. When omitted every open unassigned issue is ready."`
+	ClaimLabel string   `yaml:"claim_label,omitempty" jsonschema:"description=Label added to an issue when a route claims it and removed when a route closes it. Defaults to in-progress." default:"in-progress"`
+	ReadyLimit int      `yaml:"ready_limit,omitempty" jsonschema:"description=Maximum number of ready issues listed in the context given to skills. Defaults to 10." default:"10"`
+}
+
+// TrackerAction is run against the tracker when its route is selected, in
+// the order claim, comment, close.
+type TrackerAction struct {
+	Claim   bool   `yaml:"claim,omitempty" jsonschema:"description=Claim the first ready issue by assigning it to the authenticated user and adding the claim label. An issue that is already claimed is kept."`
+	Comment string `yaml:"comment,omitempty" jsonschema:"description=Go text/template posted as a comment on the claimed issue. .Skill and .Route and .Reason and .Output and .Issue are available."`
+	Close   bool   `yaml:"close,omitempty" jsonschema:"description=Close the claimed issue and remove the claim label."`
+}
+
+// EffectiveClaimLabel returns the label that marks claimed issues.
+func (t *Tracker) EffectiveClaimLabel() string {
+	if strings.TrimSpace(t.ClaimLabel) == "" {
+		return DefaultClaimLabel
+	}
+	return t.ClaimLabel
+}
+
+// EffectiveReadyLimit returns how many ready issues are shown to skills.
+func (t *Tracker) EffectiveReadyLimit() int {
+	if t.ReadyLimit <= 0 {
+		return DefaultReadyLimit
+	}
+	return t.ReadyLimit
+}
+
+// CommentTemplate parses the comment of the action. It returns nil when the
+// action posts no comment.
+func (a *TrackerAction) CommentTemplate() (*template.Template, error) {
+	if strings.TrimSpace(a.Comment) == "" {
+		return nil, nil
+	}
+	return template.New("comment").Option("missingkey=error").Parse(a.Comment)
+}
+
+func validateTracker(cfg *Config, p *problems) {
+	if cfg.Tracker == nil {
+		return
+	}
+	provider := cfg.Tracker.Provider
+	if provider == "" {
+		provider = TrackerGitHub
+	}
+	if provider != TrackerGitHub {
+		p.addf("tracker.provider", "tracker: unsupported provider %q (supported: %s)", cfg.Tracker.Provider, TrackerGitHub)
+	}
+	owner, repo, ok := strings.Cut(strings.TrimSpace(cfg.Tracker.Repo), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		p.addf("tracker.repo", "tracker.repo must be owner/repo, got %q", cfg.Tracker.Repo)
+	}
+	if cfg.Tracker.ReadyLimit < 0 {
+		p.addf("tracker.ready_limit", "tracker.ready_limit must be >= 0")
+	}
+}
+
+func validateTrackerAction(cfg *Config, action *TrackerAction) error {
+	if cfg.Tracker == nil {
+		return fmt.Errorf("tracker actions require a top-level tracker")
+	}
+	if !action.Claim && !action.Close && strings.TrimSpace(action.Comment) == "" {
+		return fmt.Errorf("tracker action must set claim, comment or close")
+	}
+	if _, err := action.CommentTemplate(); err != nil {
+		return fmt.Errorf("invalid tracker.comment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultWatchDebounce is how long file changes must settle before a
+// watch-triggered run starts when trigger.debounce is omitted.
+const DefaultWatchDebounce = 30 * time.Second
+
+type Trigger struct {
+	Watch    []string        `yaml:"watch,omitempty" jsonschema:"description=Files or directories or glob patterns to watch (relative to the config file). ** matches any number of directories. Each debounced change starts the workflow with the changed files as the initial prompt."`
+	Debounce string          `yaml:"debounce,omitempty" jsonschema:"description=How long changes must settle before the workflow starts. A Go duration such as 30s or 2m. Defaults to 30s." default:"30s"`
+	Webhook  *WebhookTrigger `yaml:"webhook,omitempty" jsonschema:"description=Allow skill-loop serve-triggers to start this workflow from an authenticated HTTP POST. Use {} to accept webhooks with the default prompt."`
+}
+
+type WebhookTrigger struct {
+	Prompt string `yaml:"prompt,omitempty" jsonschema:"description=Go text/template rendering the initial prompt. The JSON request body is available as .Payload and the workflow name as .Workflow. Defaults to the pretty-printed payload."`
+}
+
+// WatchPatterns returns the configured watch patterns, or nil when the
+// workflow is not triggered by file changes.
+func (c *Config) WatchPatterns() []string {
+	if c.Trigger == nil {
+		return nil
+	}
+	return c.Trigger.Watch
+}
+
+// WebhookEnabled reports whether the workflow accepts webhook triggers.
+func (c *Config) WebhookEnabled() bool {
+	return c.Trigger != nil && c.Trigger.Webhook != nil
+}
+
+// WebhookPromptTemplate parses trigger.webhook.prompt. It returns nil when
+// the workflow uses the default webhook prompt.
+func (c *Config) WebhookPromptTemplate() (*template.Template, error) {
+	if !c.WebhookEnabled() || strings.TrimSpace(c.Trigger.Webhook.Prompt) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("webhook").Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(c.Trigger.Webhook.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger.webhook.prompt: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WatchDebounce returns the effective debounce of the watch trigger.
+func (c *Config) WatchDebounce() (time.Duration, error) {
+	if c.Trigger == nil || strings.TrimSpace(c.Trigger.Debounce) == "" {
+		return DefaultWatchDebounce, nil
+	}
+	debounce, err := time.ParseDuration(strings.TrimSpace(c.Trigger.Debounce))
+	if err != nil {
+		return 0, fmt.Errorf("invalid trigger.debounce %q: %w", c.Trigger.Debounce, err)
+	}
+	if debounce <= 0 {
+		return 0, fmt.Errorf("trigger.debounce must be > 0")
+	}
+	return debounce, nil
+}
+
+func validateTrigger(cfg *Config, p *problems) {
+	if cfg.Trigger == nil {
+		return
+	}
+	if len(cfg.Trigger.Watch) == 0 && cfg.Trigger.Webhook == nil {
+		p.addf("trigger", "trigger requires watch or webhook")
+	}
+	if _, err := cfg.WebhookPromptTemplate(); err != nil {
+		p.add("trigger.webhook.prompt", err)
+	}
+	for i, pattern := range cfg.Trigger.Watch {
+		patternPath := fmt.Sprintf("trigger.watch[%d]", i)
+		if strings.TrimSpace(pattern) == "" {
+			p.addf(patternPath, "trigger.watch[%d] is empty", i)
+			continue
+		}
+		if _, err := path.Match(strings.TrimSpace(pattern), ""); err != nil {
+			p.addf(patternPath, "trigger.watch[%d]: invalid pattern %q", i, pattern)
+		}
+	}
+	if len(cfg.Trigger.Watch) > 0 && cfg.Schedule != "" {
+		p.addf("trigger.watch", "schedule and trigger.watch cannot be combined")
+	}
+	if _, err := cfg.WatchDebounce(); err != nil {
+		p.add("trigger.debounce", err)
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LoadOptions adjust how LoadWithOptions builds a config.
+type LoadOptions struct {
+	// Vars override values declared in the config's vars section.
+	Vars map[string]string
+}
+
+// ParseVarAssignments parses key=value pairs such as those passed with
+// `skill-loop run --var`.
+func ParseVarAssignments(assignments []string) (map[string]string, error) {
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected key=value", assignment)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// interpolateConfig expands ${NAME} and ${env:NAME} references in every string
+// value of cfg. Vars declared in the config are expanded first, with only
+// environment references allowed in their values, then overridden by
+// overrides. Both forms accept a default as ${NAME:-default}, and $${ escapes
+// a literal ${.
+// The environment variables referenced are recorded in cfg.Env.
+func interpolateConfig(cfg *Config, overrides map[string]string, p *problems) {
+	env := map[string]*string{}
+	vars := make(map[string]string, len(cfg.Vars))
+	for _, name := range sortedKeys(cfg.Vars) {
+		value, err := interpolate(cfg.Vars[name], nil, env)
+		if err != nil {
+			p.addf("vars."+name, "vars.%s: %w", name, err)
+		}
+		vars[name] = value
+	}
+	for _, name := range sortedKeys(overrides) {
+		if _, ok := vars[name]; !ok {
+			p.addf("", "variable %q is not declared in vars", name)
+			continue
+		}
+		vars[name] = overrides[name]
+	}
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		name := yamlName(root.Type().Field(i))
+		switch name {
+		case "", "vars", "extends", "include":
+			continue
+		}
+		interpolateValue(root.Field(i), name, vars, env, p)
+	}
+	cfg.Vars = vars
+	if len(env) > 0 {
+		cfg.Env = env
+	}
+}
+
+// interpolateValue expands references in every string reachable from v.
+// Map keys, such as skill names, are left as written.
+func interpolateValue(v reflect.Value, path string, vars map[string]string, env map[string]*string, p *problems) {
+	switch v.Kind() {
+	case reflect.String:
+		value, err := interpolate(v.String(), vars, env)
+		if err != nil {
+			p.addf(path, "%s: %w", path, err)
+			return
+		}
+		v.SetString(value)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), path, vars, env, p)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name := yamlName(field)
+			if !field.IsExported() || name == "" {
+				continue
+			}
+			interpolateValue(v.Field(i), path+"."+name, vars, env, p)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), vars, env, p)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			interpolateValue(elem, path+"."+key.String(), vars, env, p)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// interpolate expands the references in s. With nil vars only environment
+// references are allowed. The environment variables looked up are recorded
+// in env with their values, or nil when they are not set.
+func interpolate(s string, vars map[string]string, env map[string]*string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", s)
+		}
+		value, err := lookupReference(s[start+2:start+end], vars, env)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+func lookupReference(reference string, vars map[string]string, env map[string]*string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(reference, ":-")
+	name = strings.TrimSpace(name)
+
+	if envName, ok := strings.CutPrefix(name, "env:"); ok {
+		if envName == "" {
+			return "", fmt.Errorf("empty environment variable name in ${%s}", reference)
+		}
+		if !isEnvName(envName) {
+			return "", fmt.Errorf("invalid environment variable name %q in ${%s}", envName, reference)
+		}
+		value, ok := os.LookupEnv(envName)
+		if ok {
+			env[envName] = &value
+			return value, nil
+		}
+		env[envName] = nil
+		if hasFallback {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", envName)
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in ${%s}", reference)
+	}
+	if vars == nil {
+		return "", fmt.Errorf("${%s}: vars may only reference the environment", reference)
+	}
+	if value, ok := vars[name]; ok {
+		return value, nil
+	}
+	if hasFallback {
+		return fallback, nil
+	}
+	return "", fmt.Errorf("undefined variable %q", name)
+}
+
+// isEnvName reports whether name is a portable environment variable name:
+// letters, digits and underscores, not starting with a digit.
+func isEnvName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,179 @@
+// Package dryrun simulates a workflow without calling any agent. Skill outputs
+// and route choices come from a fixtures file or are entered at the terminal,
+// and the prompts the agents would receive are printed instead of sent.
+package dryrun
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+)
+
+// endOfOutput ends a skill output entered at the terminal.
+const endOfOutput = "."
+
+// Fixtures script a dry run. Skills maps a skill name to the outputs of its
+// successive runs; Routes maps a skill name to the route ids chosen after its
+// successive runs. Entries are used in order.
+type Fixtures struct {
+	Skills map[string][]string `yaml:"skills"`
+	Routes map[string][]string `yaml:"routes"`
+}
+
+// LoadFixtures reads a fixtures file.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixtures: %w", err)
+	}
+	var fixtures Fixtures
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fixtures); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse fixtures %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// Executor is an orchestrator.SkillExecutor that prints the prompts of a
+// workflow instead of running agents. Outputs and route choices are taken
+// from the fixtures first; once a skill's fixtures are used up they are read
+// from the input, or the run fails when there is none. Skills with a single
+// route never consult the router, so they need no route fixtures.
+type Executor struct {
+	fixtures    *Fixtures
+	reader      *bufio.Reader
+	out         io.Writer
+	skillRuns   map[string]int
+	routeChoice map[string]int
+}
+
+// New returns an Executor that prints to out and reads from in when the
+// fixtures do not cover a step. fixtures and in may be nil.
+func New(fixtures *Fixtures, in io.Reader, out io.Writer) *Executor {
+	if fixtures == nil {
+		fixtures = &Fixtures{}
+	}
+	e := &Executor{
+		fixtures:    fixtures,
+		out:         out,
+		skillRuns:   make(map[string]int),
+		routeChoice: make(map[string]int),
+	}
+	if in != nil {
+		e.reader = bufio.NewReader(in)
+	}
+	return e
+}
+
+// ExecuteSkill prints the skill prompt and returns the simulated output.
+func (e *Executor) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
+	e.printPrompt("skill prompt: "+name, agent, executor.SkillPrompt(name, input))
+
+	run := e.skillRuns[name]
+	e.skillRuns[name]++
+	if outputs := e.fixtures.Skills[name]; run < len(outputs) {
+		fmt.Fprintf(e.out, "--- output of %s (fixture %d) ---\n%s\n--- end ---\n", name, run+1, strings.TrimRight(outputs[run], "\n"))
+		return &executor.SkillResult{Stdout: outputs[run]}, nil
+	}
+	if e.reader == nil {
+		return nil, fmt.Errorf("no fixture for run %d of skill %q", run+1, name)
+	}
+
+	fmt.Fprintf(e.out, "Enter the output of %s, ending with a line containing only %q:\n", name, endOfOutput)
+	output, err := e.readOutput()
+	if err != nil {
+		return nil, fmt.Errorf("read output of skill %q: %w", name, err)
+	}
+	return &executor.SkillResult{Stdout: output}, nil
+}
+
+// RouteSkillOutput prints the router prompt and returns the simulated choice.
+func (e *Executor) RouteSkillOutput(skillName string, router config.Agent, output string, routes []config.Route, opts executor.ExecutionOptions) (*executor.RouterDecision, error) {
+	e.printPrompt("router prompt: "+skillName, router, executor.RouterPrompt(skillName, output, routes))
+
+	choice := e.routeChoice[skillName]
+	e.routeChoice[skillName]++
+	if choices := e.fixtures.Routes[skillName]; choice < len(choices) {
+		return &executor.RouterDecision{Route: strings.TrimSpace(choices[choice]), Reason: "dry-run fixture"}, nil
+	}
+	if e.reader == nil {
+		return nil, fmt.Errorf("no route fixture for run %d of skill %q", choice+1, skillName)
+	}
+
+	for {
+		fmt.Fprintf(e.out, "Choose the route after %s:\n", skillName)
+		for i, route := range routes {
+			fmt.Fprintf(e.out, "  %d) %s: %s\n", i+1, route.ID, route.Criteria)
+		}
+		fmt.Fprint(e.out, "Route [number or id]: ")
+
+		line, err := e.reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && err != nil {
+			return nil, fmt.Errorf("read route for skill %q: %w", skillName, err)
+		}
+		if route, ok := matchRoute(routes, answer); ok {
+			return &executor.RouterDecision{Route: route.ID, Reason: "chosen in dry run"}, nil
+		}
+		fmt.Fprintf(e.out, "Unknown route %q.\n", answer)
+	}
+}
+
+func (e *Executor) printPrompt(title string, agent config.Agent, prompt string) {
+	runtime := agent.Runtime
+	if runtime == "" {
+		runtime = "claude"
+	}
+	if agent.Model != "" {
+		runtime += ", " + agent.Model
+	}
+	fmt.Fprintf(e.out, "--- %s (%s) ---\n%s--- end ---\n", title, runtime, prompt)
+}
+
+// readOutput reads lines up to a line containing only endOfOutput or the end
+// of the input.
+func (e *Executor) readOutput() (string, error) {
+	var lines []string
+	for {
+		line, err := e.reader.ReadString('\n')
+		if strings.TrimRight(line, "\r\n") == endOfOutput {
+			break
+		}
+		if line != "" {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			if len(lines) == 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func matchRoute(routes []config.Route, answer string) (config.Route, bool) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(routes) {
+		return routes[n-1], true
+	}
+	for _, route := range routes {
+		if route.ID == answer {
+			return route, true
+		}
+	}
+	return config.Route{}, false
+}
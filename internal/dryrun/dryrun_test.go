@@ -0,0 +1,96 @@
+package dryrun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+)
+
+var reviewRoutes = []config.Route{
+	{ID: "approve", Criteria: "No blocking issues", Done: true},
+	{ID: "rework", Criteria: "Changes requested", Skill: "impl"},
+}
+
+func TestExecutorUsesFixturesInOrder(t *testing.T) {
+	var out strings.Builder
+	e := New(&Fixtures{
+		Skills: map[string][]string{"review": {"Missing tests", "LGTM"}},
+		Routes: map[string][]string{"review": {"rework", "approve"}},
+	}, nil, &out)
+
+	for i, want := range []struct{ output, route string }{{"Missing tests", "rework"}, {"LGTM", "approve"}} {
+		result, err := e.ExecuteSkill("review", config.Agent{}, "Previous skill: impl", executor.ExecutionOptions{})
+		if err != nil {
+			t.Fatalf("ExecuteSkill() #%d error: %v", i+1, err)
+		}
+		if result.Stdout != want.output {
+			t.Fatalf("ExecuteSkill() #%d = %q, want %q", i+1, result.Stdout, want.output)
+		}
+		decision, err := e.RouteSkillOutput("review", config.Agent{Runtime: "codex", Model: "gpt-5.4"}, result.Stdout, reviewRoutes, executor.ExecutionOptions{})
+		if err != nil {
+			t.Fatalf("RouteSkillOutput() #%d error: %v", i+1, err)
+		}
+		if decision.Route != want.route {
+			t.Fatalf("RouteSkillOutput() #%d = %q, want %q", i+1, decision.Route, want.route)
+		}
+	}
+
+	printed := out.String()
+	for _, want := range []string{
+		"--- skill prompt: review (claude) ---\n" + executor.SkillPrompt("review", "Previous skill: impl"),
+		"--- router prompt: review (codex, gpt-5.4) ---\n" + executor.RouterPrompt("review", "LGTM", reviewRoutes),
+		"--- output of review (fixture 2) ---\nLGTM\n",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Fatalf("output = %q, want it to contain %q", printed, want)
+		}
+	}
+
+	if _, err := e.ExecuteSkill("review", config.Agent{}, "", executor.ExecutionOptions{}); err == nil || !strings.Contains(err.Error(), `no fixture for run 3 of skill "review"`) {
+		t.Fatalf("ExecuteSkill() error = %v, want exhausted fixtures", err)
+	}
+}
+
+func TestExecutorReadsFromInputWhenFixturesRunOut(t *testing.T) {
+	var out strings.Builder
+	in := strings.NewReader("Added tests\nand docs\n.\nmerge\n2\n")
+	e := New(nil, in, &out)
+
+	result, err := e.ExecuteSkill("impl", config.Agent{}, "", executor.ExecutionOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteSkill() error: %v", err)
+	}
+	if result.Stdout != "Added tests\nand docs" {
+		t.Fatalf("ExecuteSkill() = %q, want the entered lines", result.Stdout)
+	}
+
+	decision, err := e.RouteSkillOutput("review", config.Agent{}, result.Stdout, reviewRoutes, executor.ExecutionOptions{})
+	if err != nil {
+		t.Fatalf("RouteSkillOutput() error: %v", err)
+	}
+	if decision.Route != "rework" {
+		t.Fatalf("RouteSkillOutput() = %q, want rework", decision.Route)
+	}
+	if !strings.Contains(out.String(), `Unknown route "merge".`) {
+		t.Fatalf("output = %q, want the unknown route reported", out.String())
+	}
+
+	if _, err := e.RouteSkillOutput("review", config.Agent{}, "", reviewRoutes, executor.ExecutionOptions{}); err == nil {
+		t.Fatal("RouteSkillOutput() expected error at the end of the input")
+	}
+}
+
+func TestLoadFixturesRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yml")
+	if err := os.WriteFile(path, []byte("skills:\n  impl: [done]\nroute:\n  review: [approve]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFixtures(path); err == nil || !strings.Contains(err.Error(), "field route not found") {
+		t.Fatalf("LoadFixtures() error = %v, want unknown field", err)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,6 +35,15 @@ type RouterDecision struct {
 type ExecutionOptions struct {
 	IdleTimeout time.Duration
 	MaxRestarts int
+	// Context, when set, kills the agent once it is done.
+	Context context.Context
+	// Stdout and Stderr receive the output of the agent. They default to
+	// os.Stdout and os.Stderr.
+	Stdout io.Writer
+	Stderr io.Writer
+	// TrackerContext is the issue tracker context the orchestrator appended
+	// to the input of a skill, if any.
+	TrackerContext string
 }
 
 func ExecuteSkill(name string, agent config.Agent, input string, opts ExecutionOptions) (*SkillResult, error) {
@@ -47,7 +57,7 @@ func ExecuteSkill(name string, agent config.Agent, input string, opts ExecutionO
 
 	attempt := 0
 	for {
-		output, err := executeCommand(normalizeAgentRuntime(agent.Runtime), binary, args, opts.IdleTimeout, true)
+		output, err := executeCommand(opts.Context, normalizeAgentRuntime(agent.Runtime), binary, args, opts.IdleTimeout, opts.Stdout, opts.Stderr)
 		if err == nil {
 			return parseSkillOutput(output)
 		}
@@ -55,7 +65,7 @@ func ExecuteSkill(name string, agent config.Agent, input string, opts ExecutionO
 		var idleErr *idleTimeoutError
 		if errors.As(err, &idleErr) && attempt < opts.MaxRestarts {
 			attempt++
-			fmt.Fprintf(os.Stderr, "[skill-loop] skill %s idle for %s, restarting (%d/%d)\n", name, opts.IdleTimeout, attempt, opts.MaxRestarts)
+			fmt.Fprintf(opts.Stderr, "[skill-loop] skill %s idle for %s, restarting (%d/%d)\n", name, opts.IdleTimeout, attempt, opts.MaxRestarts)
 			continue
 		}
 
@@ -74,7 +84,7 @@ func RouteSkillOutput(skillName string, router config.Agent, output string, rout
 			return nil, err
 		}
 
-		raw, err := executeCommand(runtime, binary, args, opts.IdleTimeout, false)
+		raw, err := executeCommand(opts.Context, runtime, binary, args, opts.IdleTimeout, nil, opts.Stderr)
 		if err != nil {
 			return nil, err
 		}
@@ -107,6 +117,18 @@ func FormatPromptText(text string) string {
 	return fmt.Sprintf("[truncated to last %d of %d characters]\n%s", promptTextCharLimit, len(runes), string(runes[len(runes)-promptTextCharLimit:]))
 }
 
+// SkillPrompt returns the prompt ExecuteSkill sends to the agent for the named
+// skill and its input.
+func SkillPrompt(name string, input string) string {
+	return buildSkillPrompt(name, input)
+}
+
+// RouterPrompt returns the prompt RouteSkillOutput first sends to the router
+// agent.
+func RouterPrompt(skillName string, output string, routes []config.Route) string {
+	return buildRouterPrompt(skillName, output, routes)
+}
+
 func buildSkillPrompt(name string, input string) string {
 	var sb strings.Builder
 	sb.WriteString("/")
@@ -155,39 +177,44 @@ func buildRouterRepairPrompt(skillName string, output string, routes []config.Ro
 	return sb.String()
 }
 
+// runtimeBinaries maps each supported agent runtime to the CLI it runs.
+var runtimeBinaries = map[string]string{
+	"claude":     "claude",
+	"codex":      "codex",
+	"cursor-cli": "agent",
+	"opencode":   "opencode",
+}
+
+// RuntimeBinary returns the executable an agent runtime is run with. An empty
+// runtime means claude.
+func RuntimeBinary(runtime string) (string, error) {
+	binary, ok := runtimeBinaries[normalizeAgentRuntime(runtime)]
+	if !ok {
+		return "", fmt.Errorf("unsupported agent %q", runtime)
+	}
+	return binary, nil
+}
+
 func buildCommand(agent string, model string, extraArgs []string, prompt string) (string, []string, error) {
+	binary, err := RuntimeBinary(agent)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []string
 	switch agent {
-	case "claude":
-		args := []string{"-p", prompt}
-		if model != "" {
-			args = append(args, "--model", model)
-		}
-		args = append(args, extraArgs...)
-		return "claude", args, nil
+	case "claude", "cursor-cli":
+		args = []string{"-p", prompt}
 	case "codex":
-		args := []string{"exec", prompt}
-		if model != "" {
-			args = append(args, "--model", model)
-		}
-		args = append(args, extraArgs...)
-		return "codex", args, nil
-	case "cursor-cli":
-		args := []string{"-p", prompt}
-		if model != "" {
-			args = append(args, "--model", model)
-		}
-		args = append(args, extraArgs...)
-		return "agent", args, nil
+		args = []string{"exec", prompt}
 	case "opencode":
-		args := []string{"run", prompt}
-		if model != "" {
-			args = append(args, "--model", model)
-		}
-		args = append(args, extraArgs...)
-		return "opencode", args, nil
-	default:
-		return "", nil, fmt.Errorf("unsupported agent %q", agent)
+		args = []string{"run", prompt}
+	}
+	if model != "" {
+		args = append(args, "--model", model)
 	}
+	args = append(args, extraArgs...)
+	return binary, args, nil
 }
 
 func parseSkillOutput(output []byte) (*SkillResult, error) {
@@ -219,7 +246,9 @@ func parseRouterOutput(output []byte, routes []config.Route) (*RouterDecision, e
 	return nil, fmt.Errorf("unknown route %q", decision.Route)
 }
 
-func executeCommand(agent string, binary string, args []string, idleTimeout time.Duration, streamStdout bool) ([]byte, error) {
+// executeCommand runs the agent, streaming its output to stdout and stderr.
+// A nil stdout keeps the output of the agent to the returned bytes.
+func executeCommand(ctx context.Context, agent string, binary string, args []string, idleTimeout time.Duration, stdout io.Writer, stderr io.Writer) ([]byte, error) {
 	cmd := exec.Command(binary, args...)
 
 	var stdoutBuf bytes.Buffer
@@ -227,11 +256,11 @@ func executeCommand(agent string, binary string, args []string, idleTimeout time
 
 	lastActivity := &activityClock{last: time.Now()}
 	stdoutWriter := io.MultiWriter(&stdoutBuf, lastActivity)
-	if streamStdout {
-		stdoutWriter = io.MultiWriter(os.Stdout, &stdoutBuf, lastActivity)
+	if stdout != nil {
+		stdoutWriter = io.MultiWriter(stdout, &stdoutBuf, lastActivity)
 	}
 	cmd.Stdout = stdoutWriter
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf, lastActivity)
+	cmd.Stderr = io.MultiWriter(stderr, &stderrBuf, lastActivity)
 
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("%s command failed: %w", agent, err)
@@ -258,6 +287,10 @@ func executeCommand(agent string, binary string, args []string, idleTimeout time
 				return nil, fmt.Errorf("%s command failed: %w", agent, err)
 			}
 			return stdoutBuf.Bytes(), nil
+		case <-ctx.Done():
+			_ = cmd.Process.Kill()
+			<-done
+			return nil, fmt.Errorf("%s command stopped: %w", agent, ctx.Err())
 		case <-ticker.C:
 			if idleTimeout > 0 && time.Since(lastActivity.Get()) > idleTimeout {
 				_ = cmd.Process.Kill()
@@ -275,6 +308,15 @@ func normalizeOptions(opts ExecutionOptions) ExecutionOptions {
 	if opts.MaxRestarts < 0 {
 		opts.MaxRestarts = defaultMaxRestarts
 	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
 	return opts
 }
 
@@ -0,0 +1,241 @@
+// Package graph turns a workflow config into the graph of its skills and
+// routes, and renders that graph as Mermaid, Graphviz DOT or plain text.
+package graph
+
+import (
+	"sort"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// Node kinds.
+const (
+	NodeStart   = "start"
+	NodeSkill   = "skill"
+	NodeDone    = "done"
+	NodeMissing = "missing"
+)
+
+// Edge kinds.
+const (
+	EdgeStart   = "start"
+	EdgeNext    = "next"
+	EdgeDone    = "done"
+	EdgeBlocked = "blocked"
+)
+
+// StartID and DoneID are the ids of the synthetic nodes that mark where the
+// workflow begins and where done routes end.
+const (
+	StartID = "__start__"
+	DoneID  = "__done__"
+)
+
+// Node is a skill, or one of the synthetic start and done nodes. Skills that
+// are routed to but not defined have kind missing. Layer and Order place the
+// node in a top-down layout: Layer is the number of routes needed to reach it
+// from the entrypoint and Order its position within the layer.
+type Node struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Kind    string `json:"kind"`
+	Layer   int    `json:"layer"`
+	Order   int    `json:"order"`
+	Current bool   `json:"current,omitempty"`
+}
+
+// Edge is a route from one skill to another, or to the done node. Label is
+// the route criteria, or the route id when no criteria are set.
+type Edge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Route     string `json:"route,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Kind      string `json:"kind"`
+	Traversed bool   `json:"traversed,omitempty"`
+}
+
+// Graph is the skill/route graph of a workflow.
+type Graph struct {
+	Name       string `json:"name"`
+	Entrypoint string `json:"entrypoint"`
+	Nodes      []Node `json:"nodes"`
+	Edges      []Edge `json:"edges"`
+}
+
+// Highlight marks the state of a session on a graph: the skill it is running
+// and the routes it has taken so far.
+type Highlight struct {
+	CurrentSkill string
+	Routes       []Traversal
+}
+
+// Traversal is a route taken after skill ran.
+type Traversal struct {
+	Skill string
+	Route string
+}
+
+// SessionHighlight returns the highlight for the recorded state of a session.
+func SessionHighlight(meta *session.Metadata) Highlight {
+	h := Highlight{CurrentSkill: meta.CurrentSkill}
+	for _, step := range meta.Routes {
+		h.Routes = append(h.Routes, Traversal{Skill: step.Skill, Route: step.Route})
+	}
+	return h
+}
+
+// Build returns the graph of cfg starting at entrypoint, or at the config's
+// default entrypoint when entrypoint is empty. Nodes are ordered by layer,
+// and skills that cannot be reached from the entrypoint come last.
+func Build(cfg *config.Config, name string, entrypoint string) *Graph {
+	if entrypoint == "" {
+		entrypoint = cfg.DefaultEntrypoint
+	}
+
+	g := &Graph{Name: name, Entrypoint: entrypoint}
+	g.Edges = append(g.Edges, Edge{From: StartID, To: entrypoint, Kind: EdgeStart})
+
+	skillNames := make([]string, 0, len(cfg.Skills))
+	for skillName := range cfg.Skills {
+		skillNames = append(skillNames, skillName)
+	}
+	sort.Strings(skillNames)
+
+	hasDone := false
+	for _, skillName := range skillNames {
+		for _, route := range cfg.Skills[skillName].Next {
+			edge := Edge{From: skillName, Route: route.ID, Label: route.Criteria, Kind: EdgeNext, To: route.Skill}
+			if edge.Label == "" {
+				edge.Label = route.ID
+			}
+			switch {
+			case route.Done:
+				edge.Kind = EdgeDone
+				edge.To = DoneID
+				hasDone = true
+			case route.Blocked:
+				edge.Kind = EdgeBlocked
+			}
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	g.Nodes = layout(cfg, entrypoint, skillNames, hasDone)
+	sortEdges(g.Edges, g.Nodes)
+	return g
+}
+
+// layout assigns layers by breadth-first search from the start node, in route
+// order, so that the main path of a workflow reads from top to bottom. Skills
+// that cannot be reached are laid out below, followed by the done node.
+func layout(cfg *config.Config, entrypoint string, skillNames []string, hasDone bool) []Node {
+	outgoing := map[string][]string{StartID: {entrypoint}}
+	for skillName, skill := range cfg.Skills {
+		for _, route := range skill.Next {
+			if !route.Done && route.Skill != "" {
+				outgoing[skillName] = append(outgoing[skillName], route.Skill)
+			}
+		}
+	}
+
+	layers := make(map[string]int)
+	var order []string
+	visit := func(root string, layer int) {
+		layers[root] = layer
+		order = append(order, root)
+		for queue := []string{root}; len(queue) > 0; queue = queue[1:] {
+			current := queue[0]
+			for _, next := range outgoing[current] {
+				if _, seen := layers[next]; seen {
+					continue
+				}
+				layers[next] = layers[current] + 1
+				order = append(order, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	bottom := func() int {
+		deepest := 0
+		for _, layer := range layers {
+			deepest = max(deepest, layer)
+		}
+		return deepest
+	}
+
+	visit(StartID, 0)
+	for _, skillName := range skillNames {
+		if _, seen := layers[skillName]; !seen {
+			visit(skillName, bottom()+1)
+		}
+	}
+	if hasDone {
+		layers[DoneID] = bottom() + 1
+		order = append(order, DoneID)
+	}
+
+	nodes := make([]Node, 0, len(order))
+	positions := make(map[int]int)
+	for _, id := range order {
+		node := Node{ID: id, Label: id, Kind: NodeSkill, Layer: layers[id]}
+		switch id {
+		case StartID:
+			node.Kind, node.Label = NodeStart, "start"
+		case DoneID:
+			node.Kind, node.Label = NodeDone, "done"
+		default:
+			if _, ok := cfg.Skills[id]; !ok {
+				node.Kind = NodeMissing
+			}
+		}
+		node.Order = positions[node.Layer]
+		positions[node.Layer]++
+		nodes = append(nodes, node)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Layer != nodes[j].Layer {
+			return nodes[i].Layer < nodes[j].Layer
+		}
+		return nodes[i].Order < nodes[j].Order
+	})
+	return nodes
+}
+
+// sortEdges orders edges by the position of their source node so that output
+// reads in the same top-down order as the layout. Routes of one skill keep
+// their config order.
+func sortEdges(edges []Edge, nodes []Node) {
+	position := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		position[node.ID] = i
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		return position[edges[i].From] < position[edges[j].From]
+	})
+}
+
+// Apply marks the current skill and the traversed routes of h on the graph.
+// The start edge counts as traversed as soon as any route has been taken or a
+// skill is running.
+func (g *Graph) Apply(h Highlight) {
+	for i := range g.Nodes {
+		g.Nodes[i].Current = h.CurrentSkill != "" && g.Nodes[i].ID == h.CurrentSkill
+	}
+
+	taken := make(map[Traversal]bool, len(h.Routes))
+	for _, route := range h.Routes {
+		taken[route] = true
+	}
+	started := h.CurrentSkill != "" || len(h.Routes) > 0
+	for i := range g.Edges {
+		edge := &g.Edges[i]
+		if edge.Kind == EdgeStart {
+			edge.Traversed = started
+			continue
+		}
+		edge.Traversed = taken[Traversal{Skill: edge.From, Route: edge.Route}]
+	}
+}
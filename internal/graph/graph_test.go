@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		DefaultEntrypoint: "impl",
+		Skills: map[string]config.Skill{
+			"impl": {Next: []config.Route{{ID: "review", Skill: "review"}}},
+			"review": {Next: []config.Route{
+				{ID: "approve", Criteria: "No blocking issues remain", Done: true},
+				{ID: "rework", Criteria: "Changes are needed", Skill: "impl"},
+				{ID: "escalate", Criteria: "Needs a human decision", Skill: "impl", Blocked: true},
+			}},
+			"cleanup": {Next: []config.Route{{ID: "archive", Skill: "archive"}}},
+		},
+	}
+}
+
+func TestBuildLaysOutSkillsFromEntrypoint(t *testing.T) {
+	g := Build(testConfig(), "review-loop", "")
+
+	var nodes []string
+	for _, node := range g.Nodes {
+		nodes = append(nodes, fmt.Sprintf("%s:%s:%d", node.ID, node.Kind, node.Layer))
+	}
+	want := "__start__:start:0,impl:skill:1,review:skill:2,cleanup:skill:3,archive:missing:4,__done__:done:5"
+	if got := strings.Join(nodes, ","); got != want {
+		t.Fatalf("nodes = %q, want %q", got, want)
+	}
+
+	var edges []string
+	for _, edge := range g.Edges {
+		edges = append(edges, edge.From+"->"+edge.To+":"+edge.Kind)
+	}
+	want = "__start__->impl:start,impl->review:next,review->__done__:done,review->impl:next,review->impl:blocked,cleanup->archive:next"
+	if got := strings.Join(edges, ","); got != want {
+		t.Fatalf("edges = %q, want %q", got, want)
+	}
+}
+
+func TestApplyHighlightsCurrentSkillAndTraversedRoutes(t *testing.T) {
+	g := Build(testConfig(), "review-loop", "")
+	g.Apply(Highlight{
+		CurrentSkill: "review",
+		Routes:       []Traversal{{Skill: "impl", Route: "review"}, {Skill: "review", Route: "rework"}},
+	})
+
+	for _, node := range g.Nodes {
+		if node.Current != (node.ID == "review") {
+			t.Fatalf("node %s current = %v, want only review current", node.ID, node.Current)
+		}
+	}
+	var traversed []string
+	for _, edge := range g.Edges {
+		if edge.Traversed {
+			traversed = append(traversed, edge.From+"/"+edge.Route)
+		}
+	}
+	if got := strings.Join(traversed, ","); got != "__start__/,impl/review,review/rework" {
+		t.Fatalf("traversed = %q, want start, impl/review and review/rework", got)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	g := Build(testConfig(), "review-loop", "")
+	g.Apply(Highlight{CurrentSkill: "review", Routes: []Traversal{{Skill: "impl", Route: "review"}}})
+
+	got := Mermaid(g)
+	for _, want := range []string{
+		"flowchart TD\n",
+		`  n0(["start"])`,
+		`  n5(("done"))`,
+		`  n2 ==>|"No blocking issues remain"| n5`,
+		`  n2 -.->|"Needs a human decision"| n1`,
+		"  class n2 current\n",
+		"  class n4 missing\n",
+		"  linkStyle 4 stroke:#d97706\n",
+		"  linkStyle 0,1 stroke:#2563eb,stroke-width:3px\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g := Build(testConfig(), "review-loop", "")
+	g.Apply(Highlight{CurrentSkill: "impl"})
+
+	got := DOT(g)
+	for _, want := range []string{
+		`digraph "review-loop" {`,
+		`  "impl" [label="impl", fillcolor="#fde68a", penwidth=3, style="rounded,filled"];`,
+		`  "review" -> "__done__" [label="No blocking issues remain", color="#059669", penwidth=2];`,
+		`  "review" -> "impl" [label="Needs a human decision", color="#d97706", style=dashed];`,
+		`  "__start__" -> "impl" [color="#2563eb", penwidth=3];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("DOT() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestASCII(t *testing.T) {
+	g := Build(testConfig(), "review-loop", "")
+	g.Apply(Highlight{CurrentSkill: "review", Routes: []Traversal{{Skill: "impl", Route: "review"}}})
+
+	want := `workflow: review-loop
+entrypoint: impl
+
+[impl]
+  --> review  [review] (taken)
+
+[review] *
+  ==> (done)  [approve] No blocking issues remain
+  --> impl  [rework] Changes are needed
+  ..> impl (blocked)  [escalate] Needs a human decision
+
+[cleanup]
+  --> archive  [archive]
+
+[archive] (missing)
+`
+	if got := ASCII(g); got != want {
+		t.Fatalf("ASCII() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderRejectsUnknownFormat(t *testing.T) {
+	if _, err := Render(Build(testConfig(), "review-loop", ""), "svg"); err == nil {
+		t.Fatal("Render() error = nil, want unknown format error")
+	}
+}
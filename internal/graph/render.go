@@ -0,0 +1,222 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Output formats accepted by Render.
+const (
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+	FormatASCII   = "ascii"
+)
+
+// Formats lists the output formats accepted by Render.
+var Formats = []string{FormatMermaid, FormatDOT, FormatASCII}
+
+// Colors shared by the Mermaid and DOT output.
+const (
+	colorCurrent   = "#fde68a"
+	colorTraversed = "#2563eb"
+	colorDone      = "#059669"
+	colorBlocked   = "#d97706"
+	colorMissing   = "#b91c1c"
+)
+
+// Render renders g in the given format.
+func Render(g *Graph, format string) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(g), nil
+	case FormatDOT:
+		return DOT(g), nil
+	case FormatASCII:
+		return ASCII(g), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Mermaid renders g as a Mermaid flowchart. Done routes are thick arrows into
+// the done node, blocked routes are dashed, and the current skill and
+// traversed routes are highlighted.
+func Mermaid(g *Graph) string {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.ID] = "n" + strconv.Itoa(i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, node := range g.Nodes {
+		label := mermaidText(node.Label)
+		switch node.Kind {
+		case NodeStart:
+			fmt.Fprintf(&b, "  %s([\"%s\"])\n", ids[node.ID], label)
+		case NodeDone:
+			fmt.Fprintf(&b, "  %s((\"%s\"))\n", ids[node.ID], label)
+		default:
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node.ID], label)
+		}
+	}
+
+	var blocked, traversed []string
+	for i, edge := range g.Edges {
+		arrow := "-->"
+		switch edge.Kind {
+		case EdgeDone:
+			arrow = "==>"
+		case EdgeBlocked:
+			arrow = "-.->"
+			blocked = append(blocked, strconv.Itoa(i))
+		}
+		if edge.Traversed {
+			traversed = append(traversed, strconv.Itoa(i))
+		}
+		if edge.Label == "" {
+			fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", ids[edge.From], arrow, mermaidText(edge.Label), ids[edge.To])
+	}
+
+	fmt.Fprintf(&b, "  classDef done stroke:%s,stroke-width:2px\n", colorDone)
+	fmt.Fprintf(&b, "  classDef missing stroke:%s,stroke-dasharray:4 3\n", colorMissing)
+	fmt.Fprintf(&b, "  classDef current fill:%s,stroke-width:3px\n", colorCurrent)
+	for _, node := range g.Nodes {
+		switch {
+		case node.Current:
+			fmt.Fprintf(&b, "  class %s current\n", ids[node.ID])
+		case node.Kind == NodeDone:
+			fmt.Fprintf(&b, "  class %s done\n", ids[node.ID])
+		case node.Kind == NodeMissing:
+			fmt.Fprintf(&b, "  class %s missing\n", ids[node.ID])
+		}
+	}
+	if len(blocked) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:%s\n", strings.Join(blocked, ","), colorBlocked)
+	}
+	if len(traversed) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:%s,stroke-width:3px\n", strings.Join(traversed, ","), colorTraversed)
+	}
+	return b.String()
+}
+
+// DOT renders g as a Graphviz digraph with the same styling as Mermaid.
+func DOT(g *Graph) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	b.WriteString("  edge [fontsize=10];\n")
+
+	for _, node := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(node.Label)}
+		style := "rounded"
+		switch node.Kind {
+		case NodeStart:
+			attrs = append(attrs, "shape=oval")
+		case NodeDone:
+			attrs = append(attrs, "shape=doublecircle", "color="+dotQuote(colorDone))
+		case NodeMissing:
+			attrs = append(attrs, "color="+dotQuote(colorMissing))
+			style += ",dashed"
+		}
+		if node.Current {
+			attrs = append(attrs, "fillcolor="+dotQuote(colorCurrent), "penwidth=3")
+			style += ",filled"
+		}
+		attrs = append(attrs, "style="+dotQuote(style))
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range g.Edges {
+		var attrs []string
+		if edge.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(edge.Label))
+		}
+		switch edge.Kind {
+		case EdgeDone:
+			attrs = append(attrs, "color="+dotQuote(colorDone), "penwidth=2")
+		case EdgeBlocked:
+			attrs = append(attrs, "color="+dotQuote(colorBlocked), "style=dashed")
+		}
+		if edge.Traversed {
+			attrs = append(attrs, "color="+dotQuote(colorTraversed), "penwidth=3")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ASCII renders g as an indented list of skills and their routes for reading
+// in a terminal. Regular routes are drawn as -->, done routes as ==> and
+// blocked routes as ..>; the current skill is marked with * and traversed
+// routes with (taken).
+func ASCII(g *Graph) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "workflow: %s\n", g.Name)
+	fmt.Fprintf(&b, "entrypoint: %s\n", g.Entrypoint)
+
+	outgoing := make(map[string][]Edge)
+	for _, edge := range g.Edges {
+		outgoing[edge.From] = append(outgoing[edge.From], edge)
+	}
+
+	for _, node := range g.Nodes {
+		if node.Kind == NodeStart || node.Kind == NodeDone {
+			continue
+		}
+
+		b.WriteString("\n[" + node.Label + "]")
+		if node.Kind == NodeMissing {
+			b.WriteString(" (missing)")
+		}
+		if node.Current {
+			b.WriteString(" *")
+		}
+		b.WriteString("\n")
+
+		edges := outgoing[node.ID]
+		if len(edges) == 0 && node.Kind != NodeMissing {
+			b.WriteString("  (no routes)\n")
+		}
+		for _, edge := range edges {
+			arrow, target := "-->", edge.To
+			switch edge.Kind {
+			case EdgeDone:
+				arrow, target = "==>", "(done)"
+			case EdgeBlocked:
+				arrow, target = "..>", edge.To+" (blocked)"
+			}
+			fmt.Fprintf(&b, "  %s %s  [%s]", arrow, target, edge.Route)
+			if edge.Label != edge.Route {
+				b.WriteString(" " + singleLine(edge.Label))
+			}
+			if edge.Traversed {
+				b.WriteString(" (taken)")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func mermaidText(s string) string {
+	return strings.ReplaceAll(singleLine(s), `"`, "#quot;")
+}
+
+func dotQuote(s string) string {
+	return strconv.Quote(singleLine(s))
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
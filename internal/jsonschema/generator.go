@@ -17,6 +17,27 @@ func Generate() ([]byte, error) {
 
 	s := r.Reflect(&config.Config{})
 
+	// A config that extends a base or includes fragments may inherit its
+	// required fields, so they are only required when it does neither.
+	if def, ok := s.Definitions["Config"]; ok && len(def.Required) > 0 {
+		def.If = &jsonschema.Schema{Not: &jsonschema.Schema{AnyOf: []*jsonschema.Schema{
+			{Required: []string{"extends"}},
+			{Required: []string{"include"}},
+		}}}
+		def.Then = &jsonschema.Schema{Required: def.Required}
+		def.Required = nil
+	}
+
+	// Keys with the extension prefix are ignored by the loader, so editors
+	// must accept them wherever a known field is allowed.
+	for _, def := range s.Definitions {
+		if def.Properties != nil && def.Properties.Len() > 0 {
+			def.PatternProperties = map[string]*jsonschema.Schema{
+				"^" + config.ExtensionKeyPrefix: jsonschema.TrueSchema,
+			}
+		}
+	}
+
 	s.ID = "https://raw.githubusercontent.com/takumiyoshikawa/skill-loop/main/schema.json"
 	s.Title = "skill-loop"
 	s.Description = "Schema for skill-loop YAML configuration files (skill-loop.yml)"
@@ -0,0 +1,240 @@
+// Package launch starts workflows in detached sessions, run by tmux or as a
+// plain detached process, or prepares the session of a run that stays in the
+// foreground. It is shared by
+// the run command, the webhook trigger server and the sessions UI.
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// Options mirrors the flags of `skill-loop run` that are forwarded to the
+// detached child process.
+type Options struct {
+	MaxIterations int
+	Prompt        string
+	Entrypoint    string
+	// Vars override config variables and are recorded on the session.
+	Vars map[string]string
+	// Record is the absolute path of a cassette to record the run into.
+	Record string
+	// Backend is the session backend of a detached run; empty selects tmux
+	// when it is installed. See session.ResolveBackend.
+	Backend string
+}
+
+// Detached starts cfg in a new session. Workflows with a schedule or a watch
+// trigger start a resident scheduler; all others run once.
+func Detached(cfg *config.Config, cfgPath string, opts Options) (*session.Metadata, error) {
+	if cfg.Schedule != "" || len(cfg.WatchPatterns()) > 0 {
+		if opts.Record != "" {
+			return nil, fmt.Errorf("recording is not supported for scheduled or watch-triggered workflows")
+		}
+		return startScheduled(cfg, cfgPath, opts)
+	}
+	return Once(cfg, cfgPath, opts)
+}
+
+// Once starts a single run of cfg in a new session, even when the workflow
+// also defines a schedule or a watch trigger.
+func Once(cfg *config.Config, cfgPath string, opts Options) (*session.Metadata, error) {
+	return startSession(cfg, cfgPath, opts, map[string]string{
+		"SKILL_LOOP_RUN_CHILD": "1",
+	}, nil)
+}
+
+// Foreground records a session for a single run of cfg that the calling
+// process executes itself instead of starting it detached. The session keeps
+// the detached child command, so that resuming it after a block starts a
+// detached run.
+func Foreground(cfg *config.Config, cfgPath string, opts Options) (*session.Metadata, error) {
+	meta, err := newSession(cfg, cfgPath, opts, map[string]string{
+		"SKILL_LOOP_RUN_CHILD": "1",
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	meta.Backend = session.BackendForeground
+	meta.TmuxSession = ""
+	meta.Status = session.StatusRunning
+	meta.PID = os.Getpid()
+	if err := session.Save(meta); err != nil {
+		return nil, fmt.Errorf("persist foreground session: %w", err)
+	}
+	return meta, nil
+}
+
+func ChildArgs(cfgPath string, opts Options) []string {
+	args := []string{"run", cfgPath}
+	if opts.MaxIterations > 0 {
+		args = append(args, "--max-iterations", strconv.Itoa(opts.MaxIterations))
+	}
+	if opts.Prompt != "" {
+		args = append(args, "--prompt", opts.Prompt)
+	}
+	if opts.Entrypoint != "" {
+		args = append(args, "--entrypoint", opts.Entrypoint)
+	}
+	for _, assignment := range sortedAssignments(opts.Vars) {
+		args = append(args, "--var", assignment)
+	}
+	if opts.Record != "" {
+		args = append(args, "--record", opts.Record)
+	}
+	return args
+}
+
+func startScheduled(cfg *config.Config, cfgPath string, opts Options) (*session.Metadata, error) {
+	effectiveMaxIterations := opts.MaxIterations
+	if effectiveMaxIterations <= 0 {
+		effectiveMaxIterations = cfg.MaxIterations
+	}
+	if effectiveMaxIterations <= 0 {
+		effectiveMaxIterations = orchestrator.DefaultMaxIterations
+	}
+
+	var nextRun *time.Time
+	if cfg.Schedule != "" {
+		schedule, err := cfg.CronSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("parse schedule: %w", err)
+		}
+		next := schedule.Next(time.Now())
+		nextRun = &next
+	}
+
+	// The scheduler process updates the metadata as soon as it starts, so it
+	// is complete before the process is launched and not saved again here.
+	return startSession(cfg, cfgPath, opts, map[string]string{
+		"SKILL_LOOP_SCHEDULE_CHILD": "1",
+	}, func(meta *session.Metadata) {
+		meta.Schedule = cfg.Schedule
+		meta.Watch = cfg.WatchPatterns()
+		meta.NextRun = nextRun
+		meta.CurrentIteration = 0
+		meta.MaxIterations = effectiveMaxIterations
+		meta.CurrentSkill = ""
+	})
+}
+
+// startSession creates a session running the child command and starts it.
+// prepare, when set, completes the metadata before the session starts.
+func startSession(cfg *config.Config, cfgPath string, opts Options, childEnv map[string]string, prepare func(*session.Metadata)) (*session.Metadata, error) {
+	backend, err := session.ResolveBackend(opts.Backend)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := newSession(cfg, cfgPath, opts, childEnv, true)
+	if err != nil {
+		return nil, err
+	}
+	meta.Backend = backend
+	if prepare != nil {
+		prepare(meta)
+	}
+
+	if err := session.Start(meta); err != nil {
+		cleanupErr := os.RemoveAll(filepath.Dir(meta.ScriptPath))
+		if cleanupErr != nil {
+			return nil, fmt.Errorf("start detached run: %w (cleanup failed: %v)", err, cleanupErr)
+		}
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// newSession creates the metadata of a session that runs the child command
+// for cfgPath with childEnv. Unless detached is set, a binary built by go run
+// is accepted in the command when no installed one is found.
+func newSession(cfg *config.Config, cfgPath string, opts Options, childEnv map[string]string, detached bool) (*session.Metadata, error) {
+	configDir := filepath.Dir(cfgPath)
+	if configDir == "" || configDir == "." {
+		var err error
+		configDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("get working directory: %w", err)
+		}
+	}
+
+	repoRoot, err := session.ResolveRepoRoot(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("resolve executable path: %w", err)
+	}
+	if resolved, err := resolveDetachedBinary(exePath); err == nil {
+		exePath = resolved
+	} else if detached {
+		return nil, err
+	}
+
+	command := append([]string{"env"}, sortedAssignments(childEnv)...)
+	command = append(command, exePath)
+	command = append(command, ChildArgs(cfgPath, opts)...)
+	workingDir := configDir
+
+	meta, err := session.New(repoRoot, workingDir, cfg.EffectiveName(cfgPath), "orchestrator", "skill-loop", command, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	// The environment variables the config referenced are pinned to the
+	// values they had when it was loaded, or unset when they were not set, so
+	// the child resolves the same config even where its environment differs,
+	// such as in a tmux server started elsewhere.
+	if err := session.WriteEnv(meta, cfg.Env); err != nil {
+		_ = os.RemoveAll(filepath.Dir(meta.ScriptPath))
+		return nil, err
+	}
+	meta.ConfigPath = cfgPath
+	meta.Vars = opts.Vars
+	return meta, nil
+}
+
+func sortedAssignments(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	assignments := make([]string, 0, len(env))
+	for key, value := range env {
+		assignments = append(assignments, key+"="+value)
+	}
+	sort.Strings(assignments)
+	return assignments
+}
+
+func resolveDetachedBinary(exePath string) (string, error) {
+	exePath, err := filepath.Abs(exePath)
+	if err != nil {
+		return "", fmt.Errorf("resolve absolute executable path: %w", err)
+	}
+
+	if !strings.Contains(exePath, string(filepath.Separator)+"go-build") {
+		return exePath, nil
+	}
+
+	installedPath, err := exec.LookPath("skill-loop")
+	if err != nil {
+		return "", fmt.Errorf("detached runs require an installed skill-loop binary on PATH when launched via go run: %w", err)
+	}
+	installedPath, err = filepath.Abs(installedPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve installed skill-loop path: %w", err)
+	}
+	return installedPath, nil
+}
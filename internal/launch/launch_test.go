@@ -0,0 +1,118 @@
+package launch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+func TestResolveDetachedBinaryUsesInstalledBinaryForGoRun(t *testing.T) {
+	binDir := t.TempDir()
+	binaryPath := filepath.Join(binDir, "skill-loop")
+	if err := os.WriteFile(binaryPath, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	//nolint:gosec // Test helper must be executable to stand in for the installed binary on PATH.
+	if err := os.Chmod(binaryPath, 0o755); err != nil {
+		t.Fatalf("Chmod() error: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	got, err := resolveDetachedBinary("/var/folders/tmp/go-build1234/b001/exe/skill-loop")
+	if err != nil {
+		t.Fatalf("resolveDetachedBinary() error: %v", err)
+	}
+	if got != binaryPath {
+		t.Fatalf("resolveDetachedBinary() = %q, want %q", got, binaryPath)
+	}
+}
+
+func TestResolveDetachedBinaryErrorsWhenInstalledBinaryMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := resolveDetachedBinary("/var/folders/tmp/go-build1234/b001/exe/skill-loop")
+	if err == nil || !strings.Contains(err.Error(), "installed skill-loop binary") {
+		t.Fatalf("resolveDetachedBinary() error = %v, want installed binary error", err)
+	}
+}
+
+func TestChildArgsForwardsVarsInOrder(t *testing.T) {
+	got := ChildArgs("/repo/skill-loop.yml", Options{
+		Entrypoint: "review",
+		Vars:       map[string]string{"repo": "acme/api", "model": "gpt-5.4"},
+	})
+	want := "run /repo/skill-loop.yml --entrypoint review --var model=gpt-5.4 --var repo=acme/api"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("ChildArgs() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestChildArgsForwardsRecord(t *testing.T) {
+	got := ChildArgs("/repo/skill-loop.yml", Options{Record: "/repo/testdata/review.cassette.yml"})
+	want := "run /repo/skill-loop.yml --record /repo/testdata/review.cassette.yml"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("ChildArgs() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestNewSessionPinsReferencedEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SKILL_LOOP_TEST_MODEL", "gpt-5.4")
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "skill-loop.yml")
+	content := `default_entrypoint: impl
+vars:
+  branch: ${env:SKILL_LOOP_TEST_UNSET:-main}
+skills:
+  impl:
+    agent:
+      model: ${env:SKILL_LOOP_TEST_MODEL}
+    next:
+      - id: finish
+        done: true
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	// The environment of the child differs from the one the config was
+	// loaded in, as it does for a tmux server started elsewhere.
+	t.Setenv("SKILL_LOOP_TEST_MODEL", "other")
+	t.Setenv("SKILL_LOOP_TEST_UNSET", "set")
+
+	meta, err := newSession(cfg, cfgPath, Options{}, map[string]string{"SKILL_LOOP_RUN_CHILD": "1"}, false)
+	if err != nil {
+		t.Fatalf("newSession() error: %v", err)
+	}
+	if got := strings.Join(meta.Command, " "); strings.Contains(got, "gpt-5.4") || strings.Contains(got, "SKILL_LOOP_TEST_UNSET") {
+		t.Fatalf("Command = %q, want pinned environment kept out of it", got)
+	}
+	if got := strings.Join(meta.Command[:2], " "); got != "env SKILL_LOOP_RUN_CHILD=1" {
+		t.Fatalf("Command prefix = %q, want %q", got, "env SKILL_LOOP_RUN_CHILD=1")
+	}
+
+	info, err := os.Stat(session.EnvPath(meta))
+	if err != nil {
+		t.Fatalf("Stat() error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("env file permissions = %o, want 600", perm)
+	}
+	data, err := os.ReadFile(session.EnvPath(meta))
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	want := "export SKILL_LOOP_TEST_MODEL='gpt-5.4'\nunset SKILL_LOOP_TEST_UNSET\n"
+	if string(data) != want {
+		t.Fatalf("env file = %q, want %q", data, want)
+	}
+}
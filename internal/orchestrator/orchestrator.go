@@ -1,16 +1,23 @@
 package orchestrator
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/takumiyoshikawa/skill-loop/internal/config"
 	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/tracker"
 )
 
 const DefaultMaxIterations = 100
 
+// newTracker connects to the issue tracker of a workflow. Tests replace it.
+var newTracker = tracker.New
+
 // SkillExecutor abstracts skill execution for testability.
 type SkillExecutor interface {
 	ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error)
@@ -22,6 +29,12 @@ type RunObserver interface {
 	SkillCompleted(iteration int, maxIterations int, skill string, stdout string)
 }
 
+// RouteObserver is optionally implemented by a RunObserver that also wants to
+// know which route was selected after each skill.
+type RouteObserver interface {
+	RouteSelected(iteration int, skill string, route config.Route)
+}
+
 type BlockedError struct {
 	RouteID string
 	Reason  string
@@ -36,6 +49,16 @@ func (e *BlockedError) Error() string {
 	return fmt.Sprintf("workflow blocked on route %q", e.RouteID)
 }
 
+// MaxIterationsError is returned when the iteration budget runs out before a
+// done or blocked route is selected.
+type MaxIterationsError struct {
+	MaxIterations int
+}
+
+func (e *MaxIterationsError) Error() string {
+	return fmt.Sprintf("max iterations (%d) reached", e.MaxIterations)
+}
+
 type defaultExecutor struct{}
 
 func (d *defaultExecutor) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
@@ -46,23 +69,64 @@ func (d *defaultExecutor) RouteSkillOutput(skillName string, router config.Agent
 	return executor.RouteSkillOutput(skillName, router, output, routes, opts)
 }
 
+// RunOptions configure RunWithOptions. Zero values select the defaults used by
+// Run: the config's max_iterations and default_entrypoint, the agent CLIs,
+// no observer, progress written to stdout, warnings to stderr and no
+// cancellation.
+type RunOptions struct {
+	MaxIterations int
+	Prompt        string
+	Entrypoint    string
+	Executor      SkillExecutor
+	Observer      RunObserver
+	// Output receives the progress and the output of the agents, and
+	// ErrOutput the warnings and the agents' stderr.
+	Output    io.Writer
+	ErrOutput io.Writer
+	// Context cancels the run: the running agent is stopped and no further
+	// iteration starts.
+	Context context.Context
+	// Tracker, when set, is used instead of connecting to the issue tracker
+	// of a workflow that configures one.
+	Tracker tracker.Tracker
+}
+
 func Run(cfg *config.Config, maxIterations int, prompt string, entrypoint string) error {
-	return runWith(cfg, maxIterations, prompt, entrypoint, &defaultExecutor{}, nil)
+	return RunWithOptions(cfg, RunOptions{MaxIterations: maxIterations, Prompt: prompt, Entrypoint: entrypoint})
 }
 
 func RunWith(cfg *config.Config, maxIterations int, prompt string, entrypoint string, exec SkillExecutor) error {
-	return runWith(cfg, maxIterations, prompt, entrypoint, exec, nil)
+	return RunWithOptions(cfg, RunOptions{MaxIterations: maxIterations, Prompt: prompt, Entrypoint: entrypoint, Executor: exec})
 }
 
 func RunObserved(cfg *config.Config, maxIterations int, prompt string, entrypoint string, observer RunObserver) error {
-	return runWith(cfg, maxIterations, prompt, entrypoint, &defaultExecutor{}, observer)
+	return RunWithOptions(cfg, RunOptions{MaxIterations: maxIterations, Prompt: prompt, Entrypoint: entrypoint, Observer: observer})
 }
 
 func RunWithObserver(cfg *config.Config, maxIterations int, prompt string, entrypoint string, exec SkillExecutor, observer RunObserver) error {
-	return runWith(cfg, maxIterations, prompt, entrypoint, exec, observer)
+	return RunWithOptions(cfg, RunOptions{MaxIterations: maxIterations, Prompt: prompt, Entrypoint: entrypoint, Executor: exec, Observer: observer})
 }
 
-func runWith(cfg *config.Config, maxIterations int, prompt string, entrypoint string, exec SkillExecutor, observer RunObserver) error {
+// RunWithOptions runs the workflow like Run with the settings in opts.
+func RunWithOptions(cfg *config.Config, opts RunOptions) error {
+	if opts.Executor == nil {
+		opts.Executor = &defaultExecutor{}
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	if opts.ErrOutput == nil {
+		opts.ErrOutput = os.Stderr
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	return runWith(cfg, opts)
+}
+
+func runWith(cfg *config.Config, run RunOptions) error {
+	maxIterations, entrypoint := run.MaxIterations, run.Entrypoint
+	exec, observer, out, ctx := run.Executor, run.Observer, run.Output, run.Context
 	if maxIterations <= 0 {
 		maxIterations = cfg.MaxIterations
 	}
@@ -78,10 +142,25 @@ func runWith(cfg *config.Config, maxIterations int, prompt string, entrypoint st
 		return err
 	}
 
+	var issues tracker.Tracker
+	if cfg.Tracker != nil {
+		issues = run.Tracker
+		if issues == nil {
+			var err error
+			if issues, err = newTracker(cfg.Tracker); err != nil {
+				return fmt.Errorf("tracker: %w", err)
+			}
+		}
+		issues = tracker.Cached(issues)
+	}
+
 	currentSkill := entrypoint
-	handoff := strings.TrimSpace(prompt)
+	handoff := strings.TrimSpace(run.Prompt)
 
 	for i := 0; i < maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		skill, ok := cfg.Skills[currentSkill]
 		if !ok {
 			return fmt.Errorf("skill %q not found in config", currentSkill)
@@ -91,14 +170,28 @@ func runWith(cfg *config.Config, maxIterations int, prompt string, entrypoint st
 			observer.IterationStarted(i+1, maxIterations, currentSkill)
 		}
 
-		fmt.Printf("==> Running skill: %s (iteration %d)\n", currentSkill, i+1)
+		fmt.Fprintf(out, "==> Running skill: %s (iteration %d)\n", currentSkill, i+1)
 
 		opts := executor.ExecutionOptions{
 			IdleTimeout: time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
 			MaxRestarts: cfg.EffectiveMaxRestarts(),
+			Context:     ctx,
+			Stdout:      out,
+			Stderr:      run.ErrOutput,
 		}
 
-		result, err := exec.ExecuteSkill(currentSkill, skill.Agent, handoff, opts)
+		input := handoff
+		if issues != nil {
+			trackerContext, err := tracker.Context(ctx, issues, cfg.Tracker)
+			if err != nil {
+				fmt.Fprintf(run.ErrOutput, "[skill-loop] tracker context unavailable: %v\n", err)
+			} else {
+				input = joinContext(handoff, trackerContext)
+				opts.TrackerContext = trackerContext
+			}
+		}
+
+		result, err := exec.ExecuteSkill(currentSkill, skill.Agent, input, opts)
 		if err != nil {
 			return fmt.Errorf("skill %q failed: %w", currentSkill, err)
 		}
@@ -111,14 +204,33 @@ func runWith(cfg *config.Config, maxIterations int, prompt string, entrypoint st
 			return fmt.Errorf("skill %q routing failed: %w", currentSkill, err)
 		}
 
-		fmt.Printf("==> Router selected: %s", route.ID)
+		if routeObserver, ok := observer.(RouteObserver); ok {
+			routeObserver.RouteSelected(i+1, currentSkill, route)
+		}
+
+		fmt.Fprintf(out, "==> Router selected: %s", route.ID)
 		if reason != "" {
-			fmt.Printf(" (%s)", reason)
+			fmt.Fprintf(out, " (%s)", reason)
+		}
+		fmt.Fprintln(out)
+
+		if route.Tracker != nil && issues != nil {
+			issue, err := tracker.Apply(ctx, issues, route.Tracker, tracker.ActionData{
+				Skill:  currentSkill,
+				Route:  route.ID,
+				Reason: reason,
+				Output: result.Stdout,
+			})
+			if err != nil {
+				return fmt.Errorf("route %q tracker action failed: %w", route.ID, err)
+			}
+			if issue != nil {
+				fmt.Fprintf(out, "==> Tracker: %s #%d\n", describeTrackerAction(route.Tracker), issue.Number)
+			}
 		}
-		fmt.Println()
 
 		if route.Done {
-			fmt.Println("==> Loop finished.")
+			fmt.Fprintln(out, "==> Loop finished.")
 			return nil
 		}
 
@@ -139,7 +251,7 @@ func runWith(cfg *config.Config, maxIterations int, prompt string, entrypoint st
 		currentSkill = route.Skill
 	}
 
-	return fmt.Errorf("max iterations (%d) reached", maxIterations)
+	return &MaxIterationsError{MaxIterations: maxIterations}
 }
 
 func selectRoute(exec SkillExecutor, router config.Agent, skillName string, routes []config.Route, output string, opts executor.ExecutionOptions) (config.Route, string, error) {
@@ -179,3 +291,62 @@ func buildHandoff(previousSkill string, routeID string, reason string, stdout st
 	sb.WriteString(executor.FormatPromptText(stdout))
 	return sb.String()
 }
+
+func joinContext(handoff string, trackerContext string) string {
+	if handoff == "" {
+		return trackerContext
+	}
+	return handoff + "\n\n" + trackerContext
+}
+
+func describeTrackerAction(action *config.TrackerAction) string {
+	var steps []string
+	if action.Claim {
+		steps = append(steps, "claimed")
+	}
+	if strings.TrimSpace(action.Comment) != "" {
+		steps = append(steps, "commented on")
+	}
+	if action.Close {
+		steps = append(steps, "closed")
+	}
+	return strings.Join(steps, ", ")
+}
+
+// Observers returns a RunObserver that forwards every event to each non-nil
+// observer, including route selections to those that implement
+// RouteObserver. It returns nil when no observer is given.
+func Observers(observers ...RunObserver) RunObserver {
+	var joined multiObserver
+	for _, observer := range observers {
+		if observer != nil {
+			joined = append(joined, observer)
+		}
+	}
+	if len(joined) == 0 {
+		return nil
+	}
+	return joined
+}
+
+type multiObserver []RunObserver
+
+func (m multiObserver) IterationStarted(iteration int, maxIterations int, skill string) {
+	for _, observer := range m {
+		observer.IterationStarted(iteration, maxIterations, skill)
+	}
+}
+
+func (m multiObserver) SkillCompleted(iteration int, maxIterations int, skill string, stdout string) {
+	for _, observer := range m {
+		observer.SkillCompleted(iteration, maxIterations, skill, stdout)
+	}
+}
+
+func (m multiObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	for _, observer := range m {
+		if routeObserver, ok := observer.(RouteObserver); ok {
+			routeObserver.RouteSelected(iteration, skill, route)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/takumiyoshikawa/skill-loop/internal/config"
 	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/tracker"
 )
 
 type mockExecutor struct {
@@ -33,6 +37,8 @@ type mockObserver struct {
 	maxes      []int
 	skills     []string
 	completed  []string
+	routes     []string
+	onRoute    func()
 }
 
 func (m *mockObserver) IterationStarted(iteration int, maxIterations int, skill string) {
@@ -45,6 +51,13 @@ func (m *mockObserver) SkillCompleted(iteration int, maxIterations int, skill st
 	m.completed = append(m.completed, stdout)
 }
 
+func (m *mockObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	m.routes = append(m.routes, skill+"/"+route.ID)
+	if m.onRoute != nil {
+		m.onRoute()
+	}
+}
+
 func (m *mockExecutor) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
 	m.skillInputs = append(m.skillInputs, input)
 	if m.skillCallIdx >= len(m.skillCalls) {
@@ -246,8 +259,9 @@ func TestRunMaxIterationsReached(t *testing.T) {
 	}
 
 	err := RunWith(cfg, 3, "", "", mock)
-	if err == nil {
-		t.Error("RunWith() should return error when max iterations reached")
+	var maxErr *MaxIterationsError
+	if !errors.As(err, &maxErr) || maxErr.MaxIterations != 3 {
+		t.Fatalf("RunWith() error = %v, want MaxIterationsError for 3 iterations", err)
 	}
 }
 
@@ -375,6 +389,9 @@ func TestRunWithObserver(t *testing.T) {
 	if observer.skills[0] != "impl" || observer.skills[1] != "review" {
 		t.Errorf("observer skills = %v, want [impl review]", observer.skills)
 	}
+	if got := strings.Join(observer.routes, ","); got != "impl/review,review/approve" {
+		t.Errorf("observer routes = %q, want impl/review,review/approve", got)
+	}
 }
 
 func TestRunPassesPreviousSkillStdoutToNextSkill(t *testing.T) {
@@ -450,3 +467,165 @@ func TestSelectRouteSingleRouteSkipsRouter(t *testing.T) {
 		t.Fatalf("router should not be called, got %d calls", exec.routerCallIdx)
 	}
 }
+
+type fakeTracker struct {
+	ready      []tracker.Issue
+	readyCalls int
+	claimed    *tracker.Issue
+	comments   []string
+	closed     []int
+}
+
+func (f *fakeTracker) Ready(ctx context.Context, limit int) ([]tracker.Issue, error) {
+	f.readyCalls++
+	if limit > 0 && len(f.ready) > limit {
+		return f.ready[:limit], nil
+	}
+	return f.ready, nil
+}
+
+func (f *fakeTracker) Claimed(ctx context.Context) (*tracker.Issue, error) { return f.claimed, nil }
+
+func (f *fakeTracker) Claim(ctx context.Context, number int) error {
+	for i, issue := range f.ready {
+		if issue.Number == number {
+			f.claimed = &f.ready[i]
+			f.ready = append(f.ready[:i:i], f.ready[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("issue #%d is not ready", number)
+}
+
+func (f *fakeTracker) Comment(ctx context.Context, number int, body string) error {
+	f.comments = append(f.comments, fmt.Sprintf("#%d: %s", number, body))
+	return nil
+}
+
+func (f *fakeTracker) Close(ctx context.Context, number int) error {
+	f.closed = append(f.closed, number)
+	f.claimed = nil
+	return nil
+}
+
+func TestRunAppliesTrackerActionsAndInjectsContext(t *testing.T) {
+	issues := &fakeTracker{ready: []tracker.Issue{{Number: 12, Title: "Add search", Body: "Search by name.", URL: "https://github.com/acme/api/issues/12"}}}
+	previous := newTracker
+	newTracker = func(cfg *config.Tracker) (tracker.Tracker, error) { return issues, nil }
+	t.Cleanup(func() { newTracker = previous })
+
+	cfg := &config.Config{
+		DefaultEntrypoint: "issue-check",
+		Tracker:           &config.Tracker{Repo: "acme/api"},
+		Skills: map[string]config.Skill{
+			"issue-check": {
+				Next: []config.Route{{ID: "start", Skill: "impl", Tracker: &config.TrackerAction{Claim: true}}},
+			},
+			"impl": {
+				Next: []config.Route{{ID: "ship", Done: true, Tracker: &config.TrackerAction{Comment: "Fixed by {{.Skill}}.", Close: true}}},
+			},
+		},
+	}
+	mock := &mockExecutor{
+		skillCalls: []mockSkillCall{
+			{result: &executor.SkillResult{Stdout: "Issue #12 looks ready"}},
+			{result: &executor.SkillResult{Stdout: "Implemented search"}},
+		},
+	}
+
+	if err := RunWith(cfg, 10, "", "", mock); err != nil {
+		t.Fatalf("RunWith() error: %v", err)
+	}
+	if got := mock.skillInputs[0]; !strings.Contains(got, "Ready issues, in the order a claim takes them:\n- #12 Add search") {
+		t.Fatalf("first skill input = %q, want the ready issues", got)
+	}
+	second := mock.skillInputs[1]
+	if !strings.Contains(second, "Previous skill: issue-check") || !strings.Contains(second, "Claimed issue: #12 Add search") || !strings.Contains(second, "Search by name.") {
+		t.Fatalf("second skill input = %q, want the handoff and the claimed issue", second)
+	}
+	if got := strings.Join(issues.comments, "\n"); got != "#12: Fixed by impl." {
+		t.Fatalf("comments = %q, want %q", got, "#12: Fixed by impl.")
+	}
+	if len(issues.closed) != 1 || issues.closed[0] != 12 {
+		t.Fatalf("closed = %v, want [12]", issues.closed)
+	}
+}
+
+func TestRunListsReadyIssuesOncePerRun(t *testing.T) {
+	issues := &fakeTracker{ready: []tracker.Issue{{Number: 12, Title: "Add search"}, {Number: 13, Title: "Add filters"}}}
+	previous := newTracker
+	newTracker = func(cfg *config.Tracker) (tracker.Tracker, error) { return issues, nil }
+	t.Cleanup(func() { newTracker = previous })
+
+	cfg := &config.Config{
+		DefaultEntrypoint: "triage",
+		Tracker:           &config.Tracker{Repo: "acme/api"},
+		Skills: map[string]config.Skill{
+			"triage": {Next: []config.Route{{ID: "again", Skill: "triage"}}},
+		},
+	}
+	mock := &mockExecutor{
+		skillCalls: []mockSkillCall{
+			{result: &executor.SkillResult{Stdout: "one"}},
+			{result: &executor.SkillResult{Stdout: "two"}},
+			{result: &executor.SkillResult{Stdout: "three"}},
+		},
+	}
+
+	var maxErr *MaxIterationsError
+	if err := RunWith(cfg, 3, "", "", mock); !errors.As(err, &maxErr) {
+		t.Fatalf("RunWith() error = %v, want MaxIterationsError", err)
+	}
+	for i, input := range mock.skillInputs {
+		if !strings.Contains(input, "- #12 Add search") || !strings.Contains(input, "- #13 Add filters") {
+			t.Fatalf("skill input %d = %q, want the ready issues", i, input)
+		}
+	}
+	if issues.readyCalls != 1 {
+		t.Fatalf("ready issues listed %d times, want once per run", issues.readyCalls)
+	}
+}
+
+func TestRunFailsWhenTrackerClaimFindsNoIssue(t *testing.T) {
+	previous := newTracker
+	newTracker = func(cfg *config.Tracker) (tracker.Tracker, error) { return &fakeTracker{}, nil }
+	t.Cleanup(func() { newTracker = previous })
+
+	cfg := &config.Config{
+		DefaultEntrypoint: "issue-check",
+		Tracker:           &config.Tracker{Repo: "acme/api"},
+		Skills: map[string]config.Skill{
+			"issue-check": {
+				Next: []config.Route{{ID: "start", Done: true, Tracker: &config.TrackerAction{Claim: true}}},
+			},
+		},
+	}
+	mock := &mockExecutor{skillCalls: []mockSkillCall{{result: &executor.SkillResult{Stdout: "ok"}}}}
+
+	err := RunWith(cfg, 10, "", "", mock)
+	if err == nil || !strings.Contains(err.Error(), `route "start" tracker action failed: no ready issue to claim`) {
+		t.Fatalf("RunWith() error = %v, want the failed claim", err)
+	}
+}
+
+func TestRunWithOptionsStopsWhenContextIsCanceled(t *testing.T) {
+	cfg := &config.Config{
+		DefaultEntrypoint: "impl",
+		Skills: map[string]config.Skill{
+			"impl": {Next: []config.Route{{ID: "again", Skill: "impl"}}},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mock := &mockExecutor{
+		skillCalls: []mockSkillCall{{result: &executor.SkillResult{Stdout: "pass 1"}}},
+	}
+	observer := &mockObserver{onRoute: cancel}
+
+	err := RunWithOptions(cfg, RunOptions{Executor: mock, Observer: observer, Output: io.Discard, Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunWithOptions() error = %v, want context.Canceled", err)
+	}
+	if mock.skillCallIdx != 1 {
+		t.Fatalf("skill calls = %d, want 1", mock.skillCallIdx)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -17,9 +20,18 @@ import (
 	"github.com/takumiyoshikawa/skill-loop/internal/session"
 )
 
+// controlPollInterval bounds how long a control request waits when the
+// signal announcing it is missed (or unsupported, as on Windows).
+const controlPollInterval = 5 * time.Second
+
+// notifyControl registers the control signal handler. Tests wrap it to check
+// when it runs.
+var notifyControl = session.NotifyControl
+
 type progressObserver struct {
 	onIteration     func(iteration int, maxIterations int, skill string)
 	onSkillComplete func(iteration int, maxIterations int, skill string, stdout string)
+	onRoute         func(iteration int, skill string, route config.Route)
 }
 
 func (p *progressObserver) IterationStarted(iteration int, maxIterations int, skill string) {
@@ -34,14 +46,56 @@ func (p *progressObserver) SkillCompleted(iteration int, maxIterations int, skil
 	}
 }
 
+func (p *progressObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	if p.onRoute != nil {
+		p.onRoute(iteration, skill, route)
+	}
+}
+
+// Run serves a resident session: it stays alive, runs the workflow on every
+// cron match of cfg.Schedule or after every debounced change to the files
+// watched by cfg.Trigger, and handles control requests in between.
 func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations int, prompt string, entrypoint string) error {
-	if cfg.Schedule == "" {
-		return fmt.Errorf("schedule is required for scheduler mode")
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stopSignals()
+	return serve(ctx, repoRoot, sessionID, cfg, options{
+		maxIterations: maxIterations,
+		prompt:        prompt,
+		entrypoint:    entrypoint,
+		stdout:        os.Stdout,
+		stderr:        os.Stderr,
+	})
+}
+
+// options configure serve.
+type options struct {
+	maxIterations int
+	prompt        string
+	entrypoint    string
+	// executor runs the skills; nil selects the agent CLIs.
+	executor orchestrator.SkillExecutor
+	// stdout and stderr receive the output of the scheduler and its runs.
+	// Each run also writes its output to its own log files.
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// serve runs the resident session until ctx is done.
+func serve(ctx context.Context, repoRoot string, sessionID string, cfg *config.Config, opts options) error {
+	maxIterations, prompt, entrypoint := opts.maxIterations, opts.prompt, opts.entrypoint
+	stderr := opts.stderr
+	watchPatterns := cfg.WatchPatterns()
+	if cfg.Schedule == "" && len(watchPatterns) == 0 {
+		return fmt.Errorf("schedule or trigger.watch is required for scheduler mode")
 	}
 
-	schedule, err := cron.ParseStandard(cfg.Schedule)
-	if err != nil {
-		return fmt.Errorf("parse schedule: %w", err)
+	var schedule cron.Schedule
+	if cfg.Schedule != "" {
+		var err error
+		schedule, err = cfg.CronSchedule()
+		if err != nil {
+			return fmt.Errorf("parse schedule: %w", err)
+		}
 	}
 
 	if maxIterations <= 0 {
@@ -60,16 +114,41 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 		if err != nil {
 			return err
 		}
-		update(meta)
-		return session.Save(meta)
+		return session.Update(meta, update)
+	}
+
+	stored, err := session.LoadByID(repoRoot, sessionID)
+	if err != nil {
+		return err
+	}
+
+	var runMu sync.Mutex
+	running := false
+	// A paused session stays paused when its resident process is restarted.
+	paused := stored.Status == session.StatusPaused
+	finishedRuns := 0
+
+	isPaused := func() bool {
+		runMu.Lock()
+		defer runMu.Unlock()
+		return paused
 	}
 
 	updateScheduled := func(lastErr string) error {
-		nextRun := schedule.Next(time.Now())
+		status := session.StatusPaused
+		var nextRun *time.Time
+		if !isPaused() {
+			status = session.StatusScheduled
+			if schedule != nil {
+				next := schedule.Next(time.Now())
+				nextRun = &next
+			}
+		}
 		return updateMeta(func(meta *session.Metadata) {
 			meta.Schedule = cfg.Schedule
-			meta.Status = session.StatusScheduled
-			meta.NextRun = &nextRun
+			meta.Watch = watchPatterns
+			meta.Status = status
+			meta.NextRun = nextRun
 			meta.CurrentIteration = 0
 			meta.MaxIterations = maxIterations
 			meta.CurrentSkill = ""
@@ -77,33 +156,50 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 			meta.ResumeSkill = ""
 			meta.ResumePrompt = ""
 			meta.LastError = lastErr
+			meta.ResidentPID = os.Getpid()
 			meta.EndedAt = nil
 		})
 	}
 
+	// Control requests are announced with a signal that terminates the
+	// process by default, so the handler must be in place before the
+	// resident PID is published.
+	controlSignals := make(chan os.Signal, 1)
+	notifyControl(controlSignals)
+	defer signal.Stop(controlSignals)
+
 	if err := updateScheduled(""); err != nil {
 		return err
 	}
 
 	c := cron.New()
-	var runMu sync.Mutex
-	running := false
+	var requestedRuns sync.WaitGroup
 
-	_, err = c.AddFunc(cfg.Schedule, func() {
+	execute := func(ex execution) {
+		label := ex.label()
 		meta, loadErr := session.LoadByID(repoRoot, sessionID)
 		if loadErr != nil {
-			fmt.Fprintf(os.Stderr, "scheduled run skipped: failed to load session metadata: %v\n", loadErr)
+			fmt.Fprintf(stderr, "%s skipped: failed to load session metadata: %v\n", label, loadErr)
+			return
+		}
+		if ex.resume && meta.Status != session.StatusBlocked {
+			fmt.Fprintf(stderr, "%s skipped: session is no longer blocked\n", label)
 			return
 		}
-		if meta.Status == session.StatusBlocked {
-			fmt.Fprintln(os.Stderr, "scheduled run skipped: session is blocked awaiting human input")
+		if !ex.resume && meta.Status == session.StatusBlocked {
+			fmt.Fprintf(stderr, "%s skipped: session is blocked awaiting human input\n", label)
 			return
 		}
 
 		runMu.Lock()
+		if paused && !ex.resume {
+			runMu.Unlock()
+			fmt.Fprintf(stderr, "%s skipped: session is paused\n", label)
+			return
+		}
 		if running {
 			runMu.Unlock()
-			fmt.Fprintln(os.Stderr, "scheduled run skipped: previous execution still in progress")
+			fmt.Fprintf(stderr, "%s skipped: previous execution still in progress\n", label)
 			return
 		}
 		running = true
@@ -112,9 +208,69 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 		defer func() {
 			runMu.Lock()
 			running = false
+			finishedRuns++
 			runMu.Unlock()
 		}()
 
+		// A resumed execution continues the run that was blocked, appending to
+		// its logs, so the history shows a single run from start to finish.
+		var run *session.Run
+		if ex.resume && meta.LastRunID != "" {
+			blockedRun, err := session.LoadRun(meta, meta.LastRunID)
+			if err == nil {
+				run = blockedRun
+				run.Status = session.StatusRunning
+				run.BlockReason = ""
+				run.EndedAt = nil
+			}
+		}
+		if run == nil {
+			newRun, err := session.NewRun(meta, ex.trigger)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s skipped: failed to record run: %v\n", label, err)
+				return
+			}
+			run = newRun
+			run.Entrypoint = ex.entrypoint
+			run.Prompt = ex.prompt
+		}
+		run.MaxIterations = maxIterations
+		if err := session.SaveRun(run); err != nil {
+			fmt.Fprintf(stderr, "failed to persist run %s: %v\n", run.ID, err)
+		}
+
+		updateRun := func(update func(*session.Run)) {
+			update(run)
+			if err := session.SaveRun(run); err != nil {
+				fmt.Fprintf(stderr, "failed to persist run %s: %v\n", run.ID, err)
+			}
+		}
+		finishRun := func(status session.Status, blockReason string, lastErr string) {
+			now := time.Now().UTC()
+			updateRun(func(run *session.Run) {
+				run.Status = status
+				run.BlockReason = blockReason
+				run.LastError = lastErr
+				run.EndedAt = &now
+			})
+		}
+
+		// The output of the run goes to its own logs as well. Other output of
+		// the scheduler, written while the run is in progress, does not.
+		runStdout, runStderr := opts.stdout, stderr
+		logs, err := session.OpenLogs(run.StdoutPath, run.StderrPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to open run logs: %v\n", err)
+		} else {
+			runStdout = io.MultiWriter(opts.stdout, logs.Stdout)
+			runStderr = io.MultiWriter(stderr, logs.Stderr)
+			defer func() {
+				if err := logs.Close(); err != nil {
+					fmt.Fprintf(stderr, "failed to close run logs: %v\n", err)
+				}
+			}()
+		}
+
 		if err := updateMeta(func(meta *session.Metadata) {
 			meta.Status = session.StatusRunning
 			meta.NextRun = nil
@@ -126,8 +282,12 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 			meta.ResumePrompt = ""
 			meta.LastError = ""
 			meta.EndedAt = nil
+			if meta.LastRunID != run.ID {
+				meta.Routes = nil
+			}
+			meta.LastRunID = run.ID
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to persist running session state: %v\n", err)
+			fmt.Fprintf(stderr, "failed to persist running session state: %v\n", err)
 		}
 
 		observer := &progressObserver{
@@ -138,7 +298,15 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 					meta.MaxIterations = maxIters
 					meta.CurrentSkill = skill
 				}); err != nil {
-					fmt.Fprintf(os.Stderr, "failed to persist session progress: %v\n", err)
+					fmt.Fprintf(stderr, "failed to persist session progress: %v\n", err)
+				}
+				updateRun(func(run *session.Run) {
+					run.CurrentIteration = iteration
+					run.MaxIterations = maxIters
+					run.CurrentSkill = skill
+				})
+				if err := session.RecordIteration(run.StdoutPath, run.StderrPath, iteration, maxIters, skill); err != nil {
+					fmt.Fprintf(stderr, "failed to record iteration: %v\n", err)
 				}
 			},
 			onSkillComplete: func(iteration int, maxIters int, skill string, stdout string) {
@@ -149,14 +317,33 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 					meta.CurrentSkill = skill
 					meta.LastSkillOutput = stdout
 				}); err != nil {
-					fmt.Fprintf(os.Stderr, "failed to persist session output: %v\n", err)
+					fmt.Fprintf(stderr, "failed to persist session output: %v\n", err)
+				}
+				updateRun(func(run *session.Run) {
+					run.LastSkillOutput = stdout
+				})
+			},
+			onRoute: func(iteration int, skill string, route config.Route) {
+				if err := updateMeta(func(meta *session.Metadata) {
+					meta.Routes = append(meta.Routes, session.RouteStep{Skill: skill, Route: route.ID})
+				}); err != nil {
+					fmt.Fprintf(stderr, "failed to persist selected route: %v\n", err)
 				}
 			},
 		}
 
-		runErr := orchestrator.RunObserved(cfg, maxIterations, prompt, entrypoint, observer)
+		runErr := orchestrator.RunWithOptions(cfg, orchestrator.RunOptions{
+			MaxIterations: maxIterations,
+			Prompt:        ex.prompt,
+			Entrypoint:    ex.entrypoint,
+			Executor:      opts.executor,
+			Observer:      observer,
+			Output:        runStdout,
+			ErrOutput:     runStderr,
+		})
 		var blocked *orchestrator.BlockedError
 		if errors.As(runErr, &blocked) {
+			finishRun(session.StatusBlocked, blocked.Reason, "")
 			now := time.Now().UTC()
 			if err := updateMeta(func(meta *session.Metadata) {
 				meta.Status = session.StatusBlocked
@@ -167,34 +354,155 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 				meta.LastError = ""
 				meta.EndedAt = &now
 			}); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to persist blocked session state: %v\n", err)
+				fmt.Fprintf(stderr, "failed to persist blocked session state: %v\n", err)
 			}
 			return
 		}
 		if runErr != nil {
-			fmt.Fprintf(os.Stderr, "scheduled run failed: %v\n", runErr)
+			fmt.Fprintf(runStderr, "%s failed: %v\n", label, runErr)
+			finishRun(session.StatusFailed, "", runErr.Error())
 			if err := updateScheduled(runErr.Error()); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to persist scheduled session state: %v\n", err)
+				fmt.Fprintf(stderr, "failed to persist scheduled session state: %v\n", err)
 			}
 			return
 		}
 
+		finishRun(session.StatusDone, "", "")
 		if err := updateScheduled(""); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to persist scheduled session state: %v\n", err)
+			fmt.Fprintf(stderr, "failed to persist scheduled session state: %v\n", err)
+		}
+	}
+
+	if schedule != nil {
+		c.Schedule(schedule, cron.FuncJob(func() {
+			execute(execution{trigger: session.TriggerSchedule, prompt: prompt, entrypoint: entrypoint})
+		}))
+	}
+
+	// A nil channel never fires, so the watch case below stays idle for
+	// cron-scheduled sessions.
+	var trigger *watchTrigger
+	var watchTicks <-chan time.Time
+	if len(watchPatterns) > 0 {
+		watchRoot, err := resolveWatchRoot(repoRoot, sessionID)
+		if err != nil {
+			return err
+		}
+		watch, err := newWatcher(watchRoot, watchPatterns)
+		if err != nil {
+			return fmt.Errorf("watch files: %w", err)
+		}
+		debounce, err := cfg.WatchDebounce()
+		if err != nil {
+			return err
+		}
+		trigger = newWatchTrigger(watch, debounce)
+		watchTicker := time.NewTicker(watchPollInterval)
+		defer watchTicker.Stop()
+		watchTicks = watchTicker.C
+	}
+
+	handleWatch := func(now time.Time) {
+		runMu.Lock()
+		busy, skip, finished := running, paused, finishedRuns
+		runMu.Unlock()
+		files, dropped, err := trigger.poll(now, busy, skip, finished)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to scan watched files: %v\n", err)
+			return
+		}
+		if len(dropped) > 0 {
+			fmt.Fprintf(stderr, "watch skipped: changes made during a run or while paused: %s\n", strings.Join(dropped, ", "))
+		}
+		if len(files) == 0 {
+			return
+		}
+
+		requestedRuns.Add(1)
+		go func() {
+			defer requestedRuns.Done()
+			execute(execution{trigger: session.TriggerWatch, prompt: buildWatchPrompt(prompt, files), entrypoint: entrypoint})
+		}()
+	}
+
+	handleControl := func() {
+		meta, err := session.LoadByID(repoRoot, sessionID)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to load session metadata for control requests: %v\n", err)
+			return
+		}
+		requests, err := session.DrainControl(meta)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to read control requests: %v\n", err)
+		}
+		for _, req := range requests {
+			switch req.Action {
+			case session.ControlTrigger:
+				runPrompt := prompt
+				if req.Prompt != "" {
+					runPrompt = req.Prompt
+				}
+				requestedRuns.Add(1)
+				go func() {
+					defer requestedRuns.Done()
+					execute(execution{trigger: session.TriggerManual, prompt: runPrompt, entrypoint: entrypoint})
+				}()
+			case session.ControlResume:
+				if meta.Status != session.StatusBlocked || meta.ResumeSkill == "" {
+					fmt.Fprintln(stderr, "ignoring resume request: session is not blocked")
+					continue
+				}
+				resumed := execution{
+					trigger:    session.TriggerResume,
+					prompt:     session.BuildResumePrompt(meta, req.Prompt),
+					entrypoint: meta.ResumeSkill,
+					resume:     true,
+				}
+				requestedRuns.Add(1)
+				go func() {
+					defer requestedRuns.Done()
+					execute(resumed)
+				}()
+			case session.ControlPause, session.ControlUnpause:
+				runMu.Lock()
+				paused = req.Action == session.ControlPause
+				idle := !running
+				runMu.Unlock()
+				// A running execution picks up the new state when it finishes,
+				// and a blocked session keeps awaiting input until it is resumed.
+				if idle && meta.Status != session.StatusBlocked {
+					if err := updateScheduled(meta.LastError); err != nil {
+						fmt.Fprintf(stderr, "failed to persist scheduled session state: %v\n", err)
+					}
+				}
+			default:
+				fmt.Fprintf(stderr, "ignoring unknown control request %q\n", req.Action)
+			}
 		}
-	})
-	if err != nil {
-		return fmt.Errorf("register cron job: %w", err)
 	}
 
 	c.Start()
-	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
-	defer stopSignals()
+	controlPoll := time.NewTicker(controlPollInterval)
+	defer controlPoll.Stop()
 
-	<-ctx.Done()
+	// Pick up requests written while the scheduler was starting.
+	handleControl()
+	for waiting := true; waiting; {
+		select {
+		case <-ctx.Done():
+			waiting = false
+		case <-controlSignals:
+			handleControl()
+		case <-controlPoll.C:
+			handleControl()
+		case now := <-watchTicks:
+			handleWatch(now)
+		}
+	}
 
 	stopCtx := c.Stop()
 	<-stopCtx.Done()
+	requestedRuns.Wait()
 
 	now := time.Now().UTC()
 	if err := updateMeta(func(meta *session.Metadata) {
@@ -202,6 +510,7 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 		meta.NextRun = nil
 		meta.CurrentIteration = 0
 		meta.CurrentSkill = ""
+		meta.ResidentPID = 0
 		meta.EndedAt = &now
 	}); err != nil {
 		return fmt.Errorf("persist stopped session state: %w", err)
@@ -209,3 +518,39 @@ func Run(repoRoot string, sessionID string, cfg *config.Config, maxIterations in
 
 	return nil
 }
+
+// resolveWatchRoot returns the directory watch patterns are relative to: the
+// directory of the session's config file, or the working directory.
+func resolveWatchRoot(repoRoot string, sessionID string) (string, error) {
+	meta, err := session.LoadByID(repoRoot, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if meta.ConfigPath != "" {
+		return filepath.Dir(meta.ConfigPath), nil
+	}
+	return os.Getwd()
+}
+
+// execution describes one workflow execution requested from the resident
+// scheduler, either by the cron schedule or through a control request.
+type execution struct {
+	trigger    string
+	prompt     string
+	entrypoint string
+	// resume continues the blocked run instead of starting a new one.
+	resume bool
+}
+
+func (e execution) label() string {
+	switch {
+	case e.resume:
+		return "resumed run"
+	case e.trigger == session.TriggerManual:
+		return "manual run"
+	case e.trigger == session.TriggerWatch:
+		return "watch run"
+	default:
+		return "scheduled run"
+	}
+}
@@ -0,0 +1,373 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// fakeExecutor records the skill inputs and selects the queued routes, or
+// the done route once the queue is empty.
+type fakeExecutor struct {
+	mu     sync.Mutex
+	inputs []string
+	routes []string
+	// wait, when set, holds every skill until it is closed.
+	wait chan struct{}
+}
+
+func (f *fakeExecutor) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
+	f.mu.Lock()
+	f.inputs = append(f.inputs, input)
+	f.mu.Unlock()
+	fmt.Fprintln(opts.Stderr, "agent warning")
+	if f.wait != nil {
+		<-f.wait
+	}
+	return &executor.SkillResult{Stdout: "reviewed"}, nil
+}
+
+func (f *fakeExecutor) RouteSkillOutput(skillName string, router config.Agent, output string, routes []config.Route, opts executor.ExecutionOptions) (*executor.RouterDecision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.routes) == 0 {
+		return &executor.RouterDecision{Route: "done"}, nil
+	}
+	route := f.routes[0]
+	f.routes = f.routes[1:]
+	return &executor.RouterDecision{Route: route, Reason: "needs a human"}, nil
+}
+
+func (f *fakeExecutor) lastInput() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.inputs) == 0 {
+		return ""
+	}
+	return f.inputs[len(f.inputs)-1]
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Schedule:          "@every 1h",
+		DefaultEntrypoint: "review",
+		MaxIterations:     5,
+		Skills: map[string]config.Skill{
+			"review": {Next: []config.Route{
+				{ID: "ask", Skill: "review", Blocked: true},
+				{ID: "done", Done: true},
+			}},
+		},
+	}
+}
+
+// startResident serves a new session in the background until the test ends.
+func startResident(t *testing.T, cfg *config.Config, opts options) *session.Metadata {
+	t.Helper()
+	return serveResident(t, newResident(t, cfg), cfg, opts)
+}
+
+// newResident creates the session of a resident process.
+func newResident(t *testing.T, cfg *config.Config) *session.Metadata {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	repoRoot := t.TempDir()
+	meta, err := session.New(repoRoot, repoRoot, "nightly", cfg.DefaultEntrypoint, "claude", []string{"skill-loop", "run"}, 0, 0)
+	if err != nil {
+		t.Fatalf("session.New() error: %v", err)
+	}
+	return meta
+}
+
+// serveResident serves meta in the background until the test ends.
+func serveResident(t *testing.T, meta *session.Metadata, cfg *config.Config, opts options) *session.Metadata {
+	t.Helper()
+	if opts.stdout == nil {
+		opts.stdout = io.Discard
+	}
+	if opts.stderr == nil {
+		opts.stderr = io.Discard
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, meta.RepoRoot, meta.ID, cfg, opts)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve() error: %v", err)
+		}
+	})
+
+	return waitForSession(t, meta, "the resident PID", func(meta *session.Metadata) bool {
+		return meta.ResidentPID != 0
+	})
+}
+
+// waitForSession polls the session metadata until cond holds.
+func waitForSession(t *testing.T, meta *session.Metadata, what string, cond func(*session.Metadata) bool) *session.Metadata {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		loaded, err := session.LoadByID(meta.RepoRoot, meta.ID)
+		if err != nil {
+			t.Fatalf("LoadByID() error: %v", err)
+		}
+		if cond(loaded) {
+			return loaded
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s: status %s", what, loaded.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeHandlesControlSignalRightAfterStart(t *testing.T) {
+	// The control signal terminates the process unless it is handled, so the
+	// handler must be registered before the resident PID is published.
+	published := make(chan int, 1)
+	t.Cleanup(func() { notifyControl = session.NotifyControl })
+	notifyControl = func(ch chan<- os.Signal) {
+		metas, err := session.List("")
+		if err != nil || len(metas) != 1 {
+			t.Errorf("List() = %d sessions, %v, want 1", len(metas), err)
+			published <- 0
+		} else {
+			published <- metas[0].ResidentPID
+		}
+		session.NotifyControl(ch)
+	}
+
+	meta := startResident(t, testConfig(), options{executor: &fakeExecutor{}})
+	if pid := <-published; pid != 0 {
+		t.Fatalf("ResidentPID = %d when the control signal was registered, want 0", pid)
+	}
+	if err := session.RequestPause(meta); err != nil {
+		t.Fatalf("RequestPause() error: %v", err)
+	}
+	waitForSession(t, meta, "the pause", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusPaused
+	})
+}
+
+func TestServeTriggerRunsWorkflow(t *testing.T) {
+	exec := &fakeExecutor{}
+	meta := startResident(t, testConfig(), options{executor: exec})
+
+	if err := session.RequestTrigger(meta, "check the docs"); err != nil {
+		t.Fatalf("RequestTrigger() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the triggered run", func(meta *session.Metadata) bool {
+		return meta.LastRunID != "" && meta.Status == session.StatusScheduled
+	})
+
+	if got := exec.lastInput(); !strings.Contains(got, "check the docs") {
+		t.Fatalf("skill input = %q, want the trigger prompt", got)
+	}
+	if meta.NextRun == nil {
+		t.Fatal("NextRun = nil after the run, want the next fire time")
+	}
+	run, err := session.LoadRun(meta, meta.LastRunID)
+	if err != nil {
+		t.Fatalf("LoadRun() error: %v", err)
+	}
+	if run.Trigger != session.TriggerManual || run.Status != session.StatusDone {
+		t.Fatalf("run trigger = %s, status = %s, want %s and %s", run.Trigger, run.Status, session.TriggerManual, session.StatusDone)
+	}
+}
+
+func TestServePauseAndUnpause(t *testing.T) {
+	meta := startResident(t, testConfig(), options{executor: &fakeExecutor{}})
+
+	if err := session.RequestPause(meta); err != nil {
+		t.Fatalf("RequestPause() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the pause", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusPaused
+	})
+	if meta.NextRun != nil {
+		t.Fatalf("NextRun = %v while paused, want none", meta.NextRun)
+	}
+	if err := session.RequestTrigger(meta, ""); err == nil {
+		t.Fatal("RequestTrigger() error = nil while paused")
+	}
+
+	if err := session.RequestUnpause(meta); err != nil {
+		t.Fatalf("RequestUnpause() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the unpause", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusScheduled
+	})
+	if meta.NextRun == nil {
+		t.Fatal("NextRun = nil after unpausing, want the next fire time")
+	}
+}
+
+func TestServeStaysPausedAfterRestart(t *testing.T) {
+	// The resident process of a paused session went away without shutting
+	// down and is started again.
+	meta := newResident(t, testConfig())
+	meta.Status = session.StatusPaused
+	meta.ResidentPID = 0
+	if err := session.Save(meta); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	meta = serveResident(t, meta, testConfig(), options{executor: &fakeExecutor{}})
+
+	if meta.Status != session.StatusPaused || meta.NextRun != nil {
+		t.Fatalf("Status = %s, NextRun = %v after the restart, want paused without a next run", meta.Status, meta.NextRun)
+	}
+	if err := session.RequestUnpause(meta); err != nil {
+		t.Fatalf("RequestUnpause() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the unpause", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusScheduled
+	})
+	if meta.NextRun == nil {
+		t.Fatal("NextRun = nil after unpausing, want the next fire time")
+	}
+}
+
+func TestServeResumeBlockedRun(t *testing.T) {
+	exec := &fakeExecutor{routes: []string{"ask"}}
+	meta := startResident(t, testConfig(), options{executor: exec})
+
+	if err := session.RequestTrigger(meta, ""); err != nil {
+		t.Fatalf("RequestTrigger() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the block", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusBlocked
+	})
+	blockedRunID := meta.LastRunID
+
+	// A pause sent while the run was still in progress arrives once it is
+	// blocked, and must not drop the block.
+	if _, err := session.SendControl(meta, session.ControlPause, ""); err != nil {
+		t.Fatalf("SendControl() error: %v", err)
+	}
+	waitForDrained(t, meta)
+	meta, err := session.LoadByID(meta.RepoRoot, meta.ID)
+	if err != nil {
+		t.Fatalf("LoadByID() error: %v", err)
+	}
+	if meta.Status != session.StatusBlocked || meta.ResumeSkill != "review" || meta.BlockReason != "needs a human" {
+		t.Fatalf("Status = %s, ResumeSkill = %q, BlockReason = %q after a pause, want the block kept", meta.Status, meta.ResumeSkill, meta.BlockReason)
+	}
+
+	if err := session.RequestResume(meta, "use v2"); err != nil {
+		t.Fatalf("RequestResume() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the resumed run", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusPaused
+	})
+	if got := exec.lastInput(); !strings.Contains(got, "use v2") {
+		t.Fatalf("skill input = %q, want the human input", got)
+	}
+	if meta.LastRunID != blockedRunID {
+		t.Fatalf("LastRunID = %s, want the blocked run %s to continue", meta.LastRunID, blockedRunID)
+	}
+	run, err := session.LoadRun(meta, meta.LastRunID)
+	if err != nil {
+		t.Fatalf("LoadRun() error: %v", err)
+	}
+	if run.Status != session.StatusDone {
+		t.Fatalf("run status = %s, want %s", run.Status, session.StatusDone)
+	}
+}
+
+// waitForDrained waits until the scheduler took every control request.
+func waitForDrained(t *testing.T, meta *session.Metadata) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		entries, err := os.ReadDir(session.ControlDir(meta))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatalf("read control directory: %v", err)
+		}
+		if len(entries) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d control requests still pending", len(entries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeKeepsSchedulerOutputOutOfRunLogs(t *testing.T) {
+	release := make(chan struct{})
+	stderr := &syncBuffer{}
+	meta := startResident(t, testConfig(), options{executor: &fakeExecutor{wait: release}, stderr: stderr})
+
+	if err := session.RequestTrigger(meta, ""); err != nil {
+		t.Fatalf("RequestTrigger() error: %v", err)
+	}
+	meta = waitForSession(t, meta, "the run", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusRunning && meta.CurrentSkill == "review"
+	})
+	// The scheduler reports the skipped trigger while the run is in progress.
+	if _, err := session.SendControl(meta, session.ControlTrigger, ""); err != nil {
+		t.Fatalf("SendControl() error: %v", err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(stderr.String(), "still in progress") {
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatalf("scheduler stderr = %q, want the skipped trigger", stderr.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	meta = waitForSession(t, meta, "the finished run", func(meta *session.Metadata) bool {
+		return meta.Status == session.StatusScheduled
+	})
+
+	run, err := session.LoadRun(meta, meta.LastRunID)
+	if err != nil {
+		t.Fatalf("LoadRun() error: %v", err)
+	}
+	runStdout, err := os.ReadFile(run.StdoutPath)
+	if err != nil {
+		t.Fatalf("read run stdout: %v", err)
+	}
+	runStderr, err := os.ReadFile(run.StderrPath)
+	if err != nil {
+		t.Fatalf("read run stderr: %v", err)
+	}
+	if !strings.Contains(string(runStdout), "==> Running skill: review") {
+		t.Fatalf("run stdout = %q, want the progress of the run", runStdout)
+	}
+	if !strings.Contains(string(runStderr), "agent warning") || strings.Contains(string(runStderr), "skipped") {
+		t.Fatalf("run stderr = %q, want the agent output only", runStderr)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
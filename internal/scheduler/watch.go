@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+// watchPollInterval is how often watched paths are rescanned for changes.
+const watchPollInterval = time.Second
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watcher detects file changes by polling, which works the same on every
+// platform and needs no extra dependencies. Patterns are slash-separated paths
+// relative to root; * and ? match within a path segment and ** matches any
+// number of directories. A plain directory pattern watches everything below it.
+// Like config discovery, scans skip .git and the paths git ignores, unless a
+// pattern names them directly.
+type watcher struct {
+	root     string
+	patterns []string
+	files    map[string]fileState
+}
+
+func newWatcher(root string, patterns []string) (*watcher, error) {
+	w := &watcher{root: root}
+	for _, pattern := range patterns {
+		pattern = path.Clean(filepath.ToSlash(strings.TrimSpace(pattern)))
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(pattern))); err == nil && info.IsDir() {
+			if pattern == "." {
+				pattern = "**"
+			} else {
+				pattern += "/**"
+			}
+		}
+		w.patterns = append(w.patterns, pattern)
+	}
+
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	w.files = files
+	return w, nil
+}
+
+// changes rescans the watched paths and returns the files that were created,
+// modified or removed since the previous call, relative to root.
+func (w *watcher) changes() ([]string, error) {
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for name, state := range files {
+		if previous, ok := w.files[name]; !ok || previous != state {
+			changed = append(changed, name)
+		}
+	}
+	for name := range w.files {
+		if _, ok := files[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	w.files = files
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func (w *watcher) scan() (map[string]fileState, error) {
+	// Listed on every scan, since a build can create ignored directories
+	// such as node_modules after the watch started.
+	ignored := config.IgnoredPaths(w.root)
+	files := make(map[string]fileState)
+	for _, pattern := range w.patterns {
+		base := filepath.Join(w.root, filepath.FromSlash(staticPrefix(pattern)))
+		err := filepath.WalkDir(base, func(current string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+
+			rel, err := filepath.Rel(w.root, current)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if entry.IsDir() {
+				if entry.Name() == ".git" || (current != base && ignored[rel+"/"]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if (current != base && ignored[rel]) || !matchPattern(pattern, rel) {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			files[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// staticPrefix returns the leading directories of pattern that contain no
+// wildcards, which is where scanning for matches has to start.
+func staticPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			if i == 0 {
+				return "."
+			}
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return pattern
+}
+
+func matchPattern(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// watchTrigger debounces the changes reported by a watcher into watch runs.
+// Changes made while a run is in progress, usually by the run itself, are
+// dropped, so a workflow that edits watched files does not trigger itself
+// again and again.
+type watchTrigger struct {
+	watcher    *watcher
+	debounce   time.Duration
+	pending    map[string]struct{}
+	lastChange time.Time
+	// finished is the number of finished runs at the previous poll.
+	finished int
+}
+
+func newWatchTrigger(w *watcher, debounce time.Duration) *watchTrigger {
+	return &watchTrigger{watcher: w, debounce: debounce, pending: map[string]struct{}{}}
+}
+
+// poll rescans the watched files and returns the changed files a run should
+// start for, if any, and the changed files it dropped. busy reports whether
+// a run is in progress, paused whether the session is paused, and finished
+// counts the runs finished so far.
+func (t *watchTrigger) poll(now time.Time, busy bool, paused bool, finished int) (files []string, dropped []string, err error) {
+	changed, err := t.watcher.changes()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range changed {
+		t.pending[name] = struct{}{}
+		t.lastChange = now
+	}
+	// The first scan after a run also drops its changes, since the run may
+	// have written files after the previous scan. Changes made while paused
+	// are dropped as well.
+	if busy || paused || finished != t.finished {
+		t.finished = finished
+		return nil, t.takePending(), nil
+	}
+	if len(t.pending) == 0 || now.Sub(t.lastChange) < t.debounce {
+		return nil, nil, nil
+	}
+	return t.takePending(), nil, nil
+}
+
+// takePending returns the pending files, sorted, and clears them.
+func (t *watchTrigger) takePending() []string {
+	if len(t.pending) == 0 {
+		return nil
+	}
+	files := make([]string, 0, len(t.pending))
+	for name := range t.pending {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	clear(t.pending)
+	return files
+}
+
+func buildWatchPrompt(prompt string, changed []string) string {
+	var b strings.Builder
+	if prompt = strings.TrimSpace(prompt); prompt != "" {
+		b.WriteString(prompt)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Changed files:\n")
+	for _, name := range changed {
+		b.WriteString("- ")
+		b.WriteString(name)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
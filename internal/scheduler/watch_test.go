@@ -0,0 +1,183 @@
+package scheduler
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "go.mod", name: "go.mod", want: true},
+		{pattern: "docs/*.md", name: "docs/guide.md", want: true},
+		{pattern: "docs/*.md", name: "docs/nested/guide.md", want: false},
+		{pattern: "docs/**/*.md", name: "docs/guide.md", want: true},
+		{pattern: "docs/**/*.md", name: "docs/a/b/guide.md", want: true},
+		{pattern: "docs/**", name: "docs/a/b/image.png", want: true},
+		{pattern: "**/*_test.go", name: "internal/x/x_test.go", want: true},
+		{pattern: "**/*_test.go", name: "internal/x/x.go", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "docs", "guide.md"), "v1")
+	writeFile(t, filepath.Join(root, "docs", "notes.txt"), "ignored")
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main")
+
+	w, err := newWatcher(root, []string{"docs/**/*.md", "src"})
+	if err != nil {
+		t.Fatalf("newWatcher() error: %v", err)
+	}
+
+	changed, err := w.changes()
+	if err != nil {
+		t.Fatalf("changes() error: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("changes() = %v before any edit, want none", changed)
+	}
+
+	writeFile(t, filepath.Join(root, "docs", "guide.md"), "v2 with more content")
+	writeFile(t, filepath.Join(root, "docs", "notes.txt"), "still ignored")
+	writeFile(t, filepath.Join(root, "docs", "api", "new.md"), "new")
+	if err := os.Remove(filepath.Join(root, "src", "main.go")); err != nil {
+		t.Fatalf("remove file: %v", err)
+	}
+
+	changed, err = w.changes()
+	if err != nil {
+		t.Fatalf("changes() error: %v", err)
+	}
+	want := "docs/api/new.md,docs/guide.md,src/main.go"
+	if got := strings.Join(changed, ","); got != want {
+		t.Fatalf("changes() = %q, want %q", got, want)
+	}
+}
+
+func TestWatcherSkipsIgnoredPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	root := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init error: %v: %s", err, out)
+	}
+	writeFile(t, filepath.Join(root, ".gitignore"), "node_modules/\n*.log\n")
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main")
+
+	w, err := newWatcher(root, []string{".", "node_modules/pkg/index.js"})
+	if err != nil {
+		t.Fatalf("newWatcher() error: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main // edited")
+	writeFile(t, filepath.Join(root, "src", "debug.log"), "noise")
+	writeFile(t, filepath.Join(root, "node_modules", "dep", "index.js"), "noise")
+	writeFile(t, filepath.Join(root, "node_modules", "pkg", "index.js"), "named directly")
+
+	changed, err := w.changes()
+	if err != nil {
+		t.Fatalf("changes() error: %v", err)
+	}
+	want := "node_modules/pkg/index.js,src/main.go"
+	if got := strings.Join(changed, ","); got != want {
+		t.Fatalf("changes() = %q, want %q", got, want)
+	}
+}
+
+func TestWatchTriggerDropsChangesMadeDuringRun(t *testing.T) {
+	root := t.TempDir()
+	guide := filepath.Join(root, "docs", "guide.md")
+	writeFile(t, guide, "v1")
+
+	w, err := newWatcher(root, []string{"docs"})
+	if err != nil {
+		t.Fatalf("newWatcher() error: %v", err)
+	}
+	trigger := newWatchTrigger(w, time.Minute)
+	start := time.Now()
+	var dropped []string
+	poll := func(offset time.Duration, busy bool, paused bool, finished int) string {
+		t.Helper()
+		files, skipped, err := trigger.poll(start.Add(offset), busy, paused, finished)
+		if err != nil {
+			t.Fatalf("poll() error: %v", err)
+		}
+		dropped = append(dropped, skipped...)
+		return strings.Join(files, ",")
+	}
+
+	writeFile(t, guide, "v2 edited")
+	if got := poll(0, false, false, 0); got != "" {
+		t.Fatalf("poll() = %q within the debounce, want none", got)
+	}
+	if got := poll(time.Minute, false, false, 0); got != "docs/guide.md" {
+		t.Fatalf("poll() = %q after the debounce, want the edited file", got)
+	}
+
+	// The run edits the file while it is in progress and again just before
+	// it finishes.
+	writeFile(t, guide, "v3 by the run")
+	if got := poll(time.Minute+time.Second, true, false, 0); got != "" {
+		t.Fatalf("poll() = %q during the run, want none", got)
+	}
+	writeFile(t, guide, "v4 by the run at the end")
+	if got := poll(time.Minute+2*time.Second, false, false, 1); got != "" {
+		t.Fatalf("poll() = %q right after the run, want none", got)
+	}
+	if got := poll(10*time.Minute, false, false, 1); got != "" {
+		t.Fatalf("poll() = %q after the run, want the run's changes dropped", got)
+	}
+	if got := strings.Join(dropped, ","); got != "docs/guide.md,docs/guide.md" {
+		t.Fatalf("dropped = %q, want the changes made during and right after the run", got)
+	}
+
+	writeFile(t, guide, "v5 a later edit by a person")
+	if got := poll(11*time.Minute, false, true, 1); got != "" {
+		t.Fatalf("poll() = %q while paused, want none", got)
+	}
+	if got := poll(12*time.Minute, false, false, 1); got != "" {
+		t.Fatalf("poll() = %q after unpausing, want changes made while paused dropped", got)
+	}
+	writeFile(t, guide, "v6 another edit by a person")
+	poll(13*time.Minute, false, false, 1)
+	if got := poll(14*time.Minute, false, false, 1); got != "docs/guide.md" {
+		t.Fatalf("poll() = %q, want a later edit to start a run", got)
+	}
+}
+
+func TestBuildWatchPrompt(t *testing.T) {
+	got := buildWatchPrompt("Review the edits.", []string{"a.go", "b.go"})
+	want := "Review the edits.\n\nChanged files:\n- a.go\n- b.go\n"
+	if got != want {
+		t.Fatalf("buildWatchPrompt() = %q, want %q", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	// Make sure a rewrite is visible even on filesystems with coarse mtimes.
+	future := time.Now().Add(time.Duration(len(content)) * time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("set file times: %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Session backends. A session records the backend that runs it in
+// Metadata.Backend; sessions without one run in tmux.
+const (
+	// BackendTmux runs the session script in a detached tmux session that can
+	// be attached to.
+	BackendTmux = "tmux"
+	// BackendProcess runs the session script as a detached process in its own
+	// process session, for machines without tmux. Its PID is kept in a pidfile
+	// next to the logs.
+	BackendProcess = "process"
+	// BackendForeground marks a run --foreground session, executed by the
+	// skill-loop process that created it.
+	BackendForeground = "foreground"
+)
+
+// Backend runs the script of a session detached from the caller and controls
+// the running session.
+type Backend interface {
+	// Start launches the run script of meta and returns the PID of its
+	// process, or 0 when it is unknown.
+	Start(meta *Metadata) (int, error)
+	// Stop terminates the processes of the session. Sessions that already
+	// exited are not an error.
+	Stop(meta *Metadata) error
+	// Attach connects the terminal to the session.
+	Attach(meta *Metadata) error
+	// Running reports whether the process of the session still runs.
+	Running(meta *Metadata) (bool, error)
+	// Cleanup releases what a finished session still holds.
+	Cleanup(meta *Metadata) error
+}
+
+var backends = map[string]Backend{
+	BackendTmux:       tmuxBackend{},
+	BackendProcess:    processBackend{},
+	BackendForeground: foregroundBackend{},
+}
+
+// ResolveBackend validates the backend selected for a new detached run. An
+// empty name selects tmux when it is installed and the process backend
+// otherwise.
+func ResolveBackend(name string) (string, error) {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "":
+		if _, err := exec.LookPath("tmux"); err != nil {
+			return BackendProcess, nil
+		}
+		return BackendTmux, nil
+	case BackendTmux, BackendProcess:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown session backend %q (want %s or %s)", name, BackendTmux, BackendProcess)
+	}
+}
+
+// BackendName returns the backend of meta.
+func BackendName(meta *Metadata) string {
+	if meta.Backend == "" {
+		return BackendTmux
+	}
+	return meta.Backend
+}
+
+func backendOf(meta *Metadata) (Backend, error) {
+	backend, ok := backends[BackendName(meta)]
+	if !ok {
+		return nil, fmt.Errorf("session %s has unknown backend %q", meta.ID, meta.Backend)
+	}
+	return backend, nil
+}
+
+type tmuxBackend struct{}
+
+func (tmuxBackend) Start(meta *Metadata) (int, error) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return 0, fmt.Errorf("tmux is required but not found on PATH (use the %s backend instead)", BackendProcess)
+	}
+
+	_ = killTMuxSession(meta.TmuxSession)
+
+	cmd := exec.Command("tmux", "new-session", "-d", "-s", meta.TmuxSession, "exec bash "+shellQuote(meta.ScriptPath))
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("start tmux session: %w", err)
+	}
+	// Keep pane content after exit so users can inspect run output with attach/capture.
+	_ = exec.Command("tmux", "set-option", "-t", meta.TmuxSession, "remain-on-exit", "on").Run()
+
+	pid, err := panePID(meta.TmuxSession)
+	if err != nil {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+func (tmuxBackend) Stop(meta *Metadata) error {
+	if err := killTMuxSession(meta.TmuxSession); err != nil {
+		return fmt.Errorf("stop tmux session: %w", err)
+	}
+	return nil
+}
+
+func (tmuxBackend) Attach(meta *Metadata) error {
+	cmd := exec.Command("tmux", "attach-session", "-t", meta.TmuxSession)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (tmuxBackend) Running(meta *Metadata) (bool, error) {
+	return HasTMuxSession(meta.TmuxSession)
+}
+
+func (tmuxBackend) Cleanup(meta *Metadata) error {
+	err := killTMuxSession(meta.TmuxSession)
+	if err != nil && !errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("cleanup tmux session %s: %w", meta.TmuxSession, err)
+	}
+	return nil
+}
+
+type processBackend struct{}
+
+// PIDPath returns the pidfile of a session run by the process backend.
+func PIDPath(meta *Metadata) string {
+	return filepath.Join(filepath.Dir(meta.ScriptPath), "run.pid")
+}
+
+func (processBackend) Start(meta *Metadata) (int, error) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return 0, fmt.Errorf("bash is required for the %s backend but not found on PATH", BackendProcess)
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", os.DevNull, err)
+	}
+	defer func() { _ = devNull.Close() }()
+
+	// The run script tees its output into the session logs, so the process
+	// itself needs no terminal.
+	cmd := exec.Command(bash, meta.ScriptPath)
+	cmd.Stdin = devNull
+	cmd.Stdout = devNull
+	cmd.Stderr = devNull
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("start detached process: %w", err)
+	}
+	pid := cmd.Process.Pid
+	// Reap the process when the caller outlives it, as the dashboard does.
+	go func() { _ = cmd.Wait() }()
+
+	if err := os.WriteFile(PIDPath(meta), []byte(strconv.Itoa(pid)+"\n"), 0o600); err != nil {
+		_ = terminateProcessGroup(pid)
+		return 0, fmt.Errorf("write pidfile: %w", err)
+	}
+	return pid, nil
+}
+
+// Stop signals the process group of the session, unless its process is gone
+// and the PID now belongs to another process.
+func (processBackend) Stop(meta *Metadata) error {
+	pid := processPID(meta)
+	if !groupLeader(pid) {
+		return nil
+	}
+	if err := terminateProcessGroup(pid); err != nil {
+		return fmt.Errorf("stop detached process %d: %w", pid, err)
+	}
+	return nil
+}
+
+func (processBackend) Attach(meta *Metadata) error {
+	return fmt.Errorf("session %s runs as a detached process without a terminal; follow it with skill-loop sessions logs %s -f", meta.ID, meta.ID)
+}
+
+// Running reports whether the detached process still leads its process
+// group, which a process that reused its PID does not.
+func (processBackend) Running(meta *Metadata) (bool, error) {
+	return groupLeader(processPID(meta)), nil
+}
+
+func (processBackend) Cleanup(meta *Metadata) error {
+	if err := os.Remove(PIDPath(meta)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove pidfile: %w", err)
+	}
+	return nil
+}
+
+// processPID returns the PID in the pidfile of meta, or the recorded PID when
+// the pidfile is missing.
+func processPID(meta *Metadata) int {
+	data, err := os.ReadFile(PIDPath(meta))
+	if err != nil {
+		return meta.PID
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return meta.PID
+	}
+	return pid
+}
+
+type foregroundBackend struct{}
+
+func (foregroundBackend) Start(meta *Metadata) (int, error) {
+	return 0, fmt.Errorf("session %s runs in the foreground and cannot be started detached", meta.ID)
+}
+
+func (foregroundBackend) Stop(meta *Metadata) error {
+	if !processAlive(meta.PID) {
+		return nil
+	}
+	if err := terminateProcess(meta.PID); err != nil {
+		return fmt.Errorf("stop foreground run: %w", err)
+	}
+	return nil
+}
+
+func (foregroundBackend) Attach(meta *Metadata) error {
+	return fmt.Errorf("session %s runs in the foreground of another terminal; follow it with skill-loop sessions logs %s -f", meta.ID, meta.ID)
+}
+
+func (foregroundBackend) Running(meta *Metadata) (bool, error) {
+	return processAlive(meta.PID), nil
+}
+
+func (foregroundBackend) Cleanup(meta *Metadata) error {
+	return nil
+}
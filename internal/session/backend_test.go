@@ -0,0 +1,180 @@
+//go:build !windows
+
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startProcessSession(t *testing.T, command ...string) *Metadata {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	repoRoot := t.TempDir()
+	meta, err := New(repoRoot, repoRoot, "detached", "orchestrator", "skill-loop", command, 0, 0)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	meta.Backend = BackendProcess
+	if err := Start(meta); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	return meta
+}
+
+func waitForStatus(t *testing.T, meta *Metadata, want Status) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err := Reconcile(meta); err != nil {
+			t.Fatalf("Reconcile() error: %v", err)
+		}
+		if meta.Status == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %s, want %s", meta.Status, want)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestProcessBackendRunsScriptDetached(t *testing.T) {
+	meta := startProcessSession(t, "echo", "hello from the process backend")
+
+	if meta.TmuxSession != "" {
+		t.Fatalf("TmuxSession = %q, want none for the process backend", meta.TmuxSession)
+	}
+	if meta.PID <= 0 {
+		t.Fatalf("PID = %d, want the detached process", meta.PID)
+	}
+	data, err := os.ReadFile(PIDPath(meta))
+	if err != nil {
+		t.Fatalf("read pidfile: %v", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		t.Fatal("pidfile is empty")
+	}
+
+	waitForStatus(t, meta, StatusDone)
+	stdout, err := os.ReadFile(meta.StdoutPath)
+	if err != nil {
+		t.Fatalf("read stdout log: %v", err)
+	}
+	if !strings.Contains(string(stdout), "hello from the process backend") {
+		t.Fatalf("stdout log = %q, want the command output", stdout)
+	}
+	if _, err := os.Stat(PIDPath(meta)); !os.IsNotExist(err) {
+		t.Fatalf("pidfile still exists after the run finished (err = %v)", err)
+	}
+	if err := Attach(meta); err == nil || !strings.Contains(err.Error(), "detached process") {
+		t.Fatalf("Attach() error = %v, want the process backend to refuse", err)
+	}
+}
+
+func TestProcessBackendStopTerminatesProcessGroup(t *testing.T) {
+	meta := startProcessSession(t, "sleep", "30")
+
+	if err := Reconcile(meta); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if meta.Status != StatusRunning {
+		t.Fatalf("status = %s, want %s", meta.Status, StatusRunning)
+	}
+
+	if err := Stop(meta); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if meta.Status != StatusStopped {
+		t.Fatalf("status = %s, want %s", meta.Status, StatusStopped)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for processAlive(meta.PID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("process %d still runs after Stop()", meta.PID)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestDeleteByIDStopsDetachedProcess(t *testing.T) {
+	meta := startProcessSession(t, "sleep", "30")
+
+	if err := DeleteByID(meta.RepoRoot, meta.ID); err != nil {
+		t.Fatalf("DeleteByID() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(meta.ScriptPath)); !os.IsNotExist(err) {
+		t.Fatalf("session directory still exists after DeleteByID() (err = %v)", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for processAlive(meta.PID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("process %d still runs after DeleteByID()", meta.PID)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestProcessBackendIgnoresReusedPID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repoRoot := t.TempDir()
+	meta, err := New(repoRoot, repoRoot, "detached", "orchestrator", "skill-loop", []string{"true"}, 0, 0)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	meta.Backend = BackendProcess
+	meta.Status = StatusRunning
+
+	// An unrelated process in the test's own process group took the PID of a
+	// session process that was killed before it could clean up.
+	other := exec.Command("sleep", "30")
+	if err := other.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = other.Process.Kill()
+		_ = other.Wait()
+	})
+	if err := os.WriteFile(PIDPath(meta), []byte(strconv.Itoa(other.Process.Pid)+"\n"), 0o600); err != nil {
+		t.Fatalf("write pidfile: %v", err)
+	}
+
+	if err := Stop(meta); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if !processAlive(other.Process.Pid) {
+		t.Fatal("Stop() signalled the process that reused the PID")
+	}
+
+	meta.Status = StatusRunning
+	if err := Reconcile(meta); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if meta.Status != StatusFailed {
+		t.Fatalf("status = %s, want %s", meta.Status, StatusFailed)
+	}
+	if _, err := os.Stat(PIDPath(meta)); !os.IsNotExist(err) {
+		t.Fatalf("stale pidfile still exists after Reconcile() (err = %v)", err)
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if got, err := ResolveBackend(""); err != nil || got != BackendProcess {
+		t.Fatalf("ResolveBackend(\"\") = %q, %v, want %s without tmux", got, err, BackendProcess)
+	}
+	if got, err := ResolveBackend(" TMUX "); err != nil || got != BackendTmux {
+		t.Fatalf("ResolveBackend(TMUX) = %q, %v, want %s", got, err, BackendTmux)
+	}
+	if _, err := ResolveBackend("screen"); err == nil || !strings.Contains(err.Error(), `unknown session backend "screen"`) {
+		t.Fatalf("ResolveBackend(screen) error = %v, want unknown backend", err)
+	}
+}
@@ -0,0 +1,204 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ControlTrigger = "trigger"
+	ControlPause   = "pause"
+	ControlUnpause = "unpause"
+	ControlResume  = "resume"
+)
+
+// ControlRequest is a request from the CLI or dashboard to the resident
+// process that owns a scheduled session. Requests are written as files into the
+// session's control directory and the process is signalled to pick them up.
+type ControlRequest struct {
+	ID          string    `json:"id"`
+	Action      string    `json:"action"`
+	Prompt      string    `json:"prompt,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// Errors wrapped by the errors of the Request functions, so that callers can
+// tell a request that cannot work for the session from one that failed.
+var (
+	// ErrNotResident is returned for sessions without a resident scheduler.
+	ErrNotResident = errors.New("not a scheduled or watch-triggered session")
+	// ErrInvalidStatus is returned when the status of the session does not
+	// allow the request.
+	ErrInvalidStatus = errors.New("invalid session status for the request")
+)
+
+// requestError is an error of a Request function that wraps one of the
+// errors above without repeating its text.
+type requestError struct {
+	kind    error
+	message string
+}
+
+func (e *requestError) Error() string { return e.message }
+
+func (e *requestError) Unwrap() error { return e.kind }
+
+func requestErrorf(kind error, format string, args ...any) error {
+	return &requestError{kind: kind, message: fmt.Sprintf(format, args...)}
+}
+
+func ControlDir(meta *Metadata) string {
+	return filepath.Join(filepath.Dir(meta.ScriptPath), "control")
+}
+
+// RequestTrigger asks the resident scheduler of meta to execute the workflow
+// immediately. A non-empty prompt replaces the initial prompt for that run.
+func RequestTrigger(meta *Metadata, prompt string) error {
+	if !meta.IsResident() {
+		return requestErrorf(ErrNotResident, "session %s is not a scheduled or watch-triggered session", meta.ID)
+	}
+	switch meta.Status {
+	case StatusScheduled:
+	case StatusRunning:
+		return requestErrorf(ErrInvalidStatus, "session %s is already running; wait for the current execution to finish", meta.ID)
+	case StatusPaused:
+		return requestErrorf(ErrInvalidStatus, "session %s is paused; unpause it first", meta.ID)
+	default:
+		return requestErrorf(ErrInvalidStatus, "session %s cannot be triggered while %s", meta.ID, meta.Status)
+	}
+	_, err := SendControl(meta, ControlTrigger, prompt)
+	return err
+}
+
+// RequestPause asks the resident scheduler of meta to skip cron fire times
+// until it is unpaused. A running execution is allowed to finish first.
+func RequestPause(meta *Metadata) error {
+	if !meta.IsResident() {
+		return requestErrorf(ErrNotResident, "session %s is not a scheduled or watch-triggered session", meta.ID)
+	}
+	if meta.Status != StatusScheduled && meta.Status != StatusRunning {
+		return requestErrorf(ErrInvalidStatus, "session %s cannot be paused while %s", meta.ID, meta.Status)
+	}
+	_, err := SendControl(meta, ControlPause, "")
+	return err
+}
+
+// RequestUnpause asks the resident scheduler of a paused session to resume
+// its schedule from the next fire time. Fire times missed while paused are
+// not replayed.
+func RequestUnpause(meta *Metadata) error {
+	if meta.Status != StatusPaused {
+		return requestErrorf(ErrInvalidStatus, "session %s is not paused", meta.ID)
+	}
+	_, err := SendControl(meta, ControlUnpause, "")
+	return err
+}
+
+// RequestResume delivers human input to the resident scheduler of a blocked
+// scheduled session. The blocked run continues from meta.ResumeSkill, and the
+// session returns to its schedule once that run finishes.
+func RequestResume(meta *Metadata, humanInput string) error {
+	if !meta.IsResident() {
+		return requestErrorf(ErrNotResident, "session %s is not a scheduled or watch-triggered session", meta.ID)
+	}
+	if meta.Status != StatusBlocked {
+		return requestErrorf(ErrInvalidStatus, "session %s is not blocked", meta.ID)
+	}
+	if meta.ResumeSkill == "" {
+		return fmt.Errorf("session %s is missing resume_skill", meta.ID)
+	}
+	_, err := SendControl(meta, ControlResume, humanInput)
+	return err
+}
+
+func SendControl(meta *Metadata, action string, prompt string) (*ControlRequest, error) {
+	if meta.ResidentPID <= 0 {
+		return nil, fmt.Errorf("session %s has no resident scheduler process", meta.ID)
+	}
+
+	now := time.Now().UTC()
+	id, err := newID(now)
+	if err != nil {
+		return nil, err
+	}
+	req := &ControlRequest{
+		ID:          id,
+		Action:      action,
+		Prompt:      strings.TrimSpace(prompt),
+		RequestedAt: now,
+	}
+
+	dir := ControlDir(meta)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create control directory: %w", err)
+	}
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal control request: %w", err)
+	}
+
+	// Write to a temporary name first so the scheduler never reads a partial request.
+	path := filepath.Join(dir, id+".json")
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write control request: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("write control request: %w", err)
+	}
+
+	if err := signalControl(meta.ResidentPID); err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("signal scheduler process %d: %w", meta.ResidentPID, err)
+	}
+	return req, nil
+}
+
+// DrainControl removes and returns all pending control requests, oldest first.
+func DrainControl(meta *Metadata) ([]*ControlRequest, error) {
+	dir := ControlDir(meta)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read control directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	requests := make([]*ControlRequest, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return requests, fmt.Errorf("remove control request: %w", err)
+		}
+		var req ControlRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			continue
+		}
+		requests = append(requests, &req)
+	}
+	// IDs only have second precision, so order by the recorded request time.
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].RequestedAt.Before(requests[j].RequestedAt)
+	})
+	return requests, nil
+}
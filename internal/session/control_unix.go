@@ -0,0 +1,18 @@
+//go:build !windows
+
+package session
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifyControl relays the signal used to announce new control requests to ch.
+func NotifyControl(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
+
+func signalControl(pid int) error {
+	return syscall.Kill(pid, syscall.SIGUSR1)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package session
+
+import "os"
+
+// NotifyControl is a no-op on Windows; resident processes poll the control
+// directory instead.
+func NotifyControl(ch chan<- os.Signal) {}
+
+func signalControl(pid int) error {
+	return nil
+}
@@ -0,0 +1,145 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Statuses lists every session status.
+var Statuses = []Status{
+	StatusPending,
+	StatusScheduled,
+	StatusPaused,
+	StatusRunning,
+	StatusBlocked,
+	StatusIdle,
+	StatusDone,
+	StatusFailed,
+	StatusStopped,
+}
+
+// Filter selects sessions by their metadata. The zero Filter matches every
+// session, and each field that is set narrows the selection.
+type Filter struct {
+	// Statuses matches sessions in any of the given statuses.
+	Statuses []Status
+	// Workflow matches the workflow name, ignoring case. It is normalized
+	// like the stored name, so "My Flow" matches the workflow my-flow.
+	Workflow string
+	// Config matches the config path, or its trailing path elements such as
+	// skill-loop.yml or .skill-loop/review.yml.
+	Config string
+	// Skill matches the skill the session is running or ran last.
+	Skill string
+	// Since and Until bound the start time of the session. Until is exclusive.
+	Since time.Time
+	Until time.Time
+	// Search matches text in the last skill output or the last error,
+	// ignoring case.
+	Search string
+}
+
+// IsZero reports whether f matches every session.
+func (f Filter) IsZero() bool {
+	return len(f.Statuses) == 0 && f.Workflow == "" && f.Config == "" && f.Skill == "" &&
+		f.Since.IsZero() && f.Until.IsZero() && f.Search == ""
+}
+
+// Match reports whether meta is selected by f.
+func (f Filter) Match(meta *Metadata) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, meta.Status) {
+		return false
+	}
+	if f.Workflow != "" && !strings.EqualFold(meta.WorkflowName, f.Workflow) &&
+		!strings.EqualFold(meta.WorkflowName, sanitizePathSegment(f.Workflow)) {
+		return false
+	}
+	if f.Config != "" && !matchConfigPath(meta.ConfigPath, f.Config) {
+		return false
+	}
+	if f.Skill != "" && lastSkill(meta) != f.Skill {
+		return false
+	}
+	if !f.Since.IsZero() && meta.StartedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !meta.StartedAt.Before(f.Until) {
+		return false
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(meta.LastSkillOutput), search) &&
+			!strings.Contains(strings.ToLower(meta.LastError), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseStatuses parses status names, each of which may hold a comma-separated
+// list.
+func ParseStatuses(values []string) ([]Status, error) {
+	var statuses []Status
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			status := Status(name)
+			if !slices.Contains(Statuses, status) {
+				return nil, fmt.Errorf("unknown session status %q", name)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// ParseTime parses a time bound of a filter: a duration before now such as
+// 15m or 24h, an RFC 3339 time, or a date in the local timezone. An empty
+// value returns the zero time.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative")
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("use a duration like 15m, an RFC 3339 time or a date like 2006-01-02")
+}
+
+// lastSkill returns the skill the session is running or ran last. Resident
+// sessions clear the current skill between runs, and their route history
+// tells which skill ran last.
+func lastSkill(meta *Metadata) string {
+	if meta.CurrentSkill != "" {
+		return meta.CurrentSkill
+	}
+	if len(meta.Routes) > 0 {
+		return meta.Routes[len(meta.Routes)-1].Skill
+	}
+	return ""
+}
+
+func matchConfigPath(configPath, want string) bool {
+	if configPath == "" {
+		return false
+	}
+	configPath = filepath.ToSlash(filepath.Clean(configPath))
+	want = filepath.ToSlash(filepath.Clean(want))
+	return configPath == want || strings.HasSuffix(configPath, "/"+want)
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterMatch(t *testing.T) {
+	started := time.Date(2026, 3, 7, 8, 0, 0, 0, time.UTC)
+	meta := &Metadata{
+		ID:              "run-1",
+		WorkflowName:    "Nightly-Review",
+		ConfigPath:      "/repo/.skill-loop/review.yml",
+		CurrentSkill:    "review",
+		Status:          StatusFailed,
+		StartedAt:       started,
+		LastSkillOutput: "All checks passed",
+		LastError:       "agent exited with status 2",
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "zero", want: true},
+		{name: "status", filter: Filter{Statuses: []Status{StatusRunning, StatusFailed}}, want: true},
+		{name: "other status", filter: Filter{Statuses: []Status{StatusDone}}},
+		{name: "workflow ignores case", filter: Filter{Workflow: "nightly-review"}, want: true},
+		{name: "other workflow", filter: Filter{Workflow: "nightly"}},
+		{name: "workflow as written in the config", filter: Filter{Workflow: "Nightly Review"}, want: true},
+		{name: "config file name", filter: Filter{Config: "review.yml"}, want: true},
+		{name: "config trailing path", filter: Filter{Config: "./.skill-loop/review.yml"}, want: true},
+		{name: "config full path", filter: Filter{Config: "/repo/.skill-loop/review.yml"}, want: true},
+		{name: "config partial name", filter: Filter{Config: "view.yml"}},
+		{name: "skill", filter: Filter{Skill: "review"}, want: true},
+		{name: "other skill", filter: Filter{Skill: "impl"}},
+		{name: "since start", filter: Filter{Since: started}, want: true},
+		{name: "since later", filter: Filter{Since: started.Add(time.Minute)}},
+		{name: "until later", filter: Filter{Until: started.Add(time.Minute)}, want: true},
+		{name: "until start is exclusive", filter: Filter{Until: started}},
+		{name: "search output", filter: Filter{Search: "checks PASSED"}, want: true},
+		{name: "search error", filter: Filter{Search: "status 2"}, want: true},
+		{name: "search miss", filter: Filter{Search: "timeout"}},
+		{name: "all must match", filter: Filter{Skill: "review", Statuses: []Status{StatusDone}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(meta); got != tt.want {
+				t.Fatalf("Match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatchSkillOfFinishedRun(t *testing.T) {
+	// Resident sessions clear the current skill once a run finishes.
+	meta := &Metadata{
+		Schedule: "@hourly",
+		Status:   StatusScheduled,
+		Routes:   []RouteStep{{Skill: "impl", Route: "review"}, {Skill: "review", Route: "done"}},
+	}
+	if !(Filter{Skill: "review"}).Match(meta) {
+		t.Fatal("Match(review) = false, want the last skill of the run to match")
+	}
+	if (Filter{Skill: "impl"}).Match(meta) {
+		t.Fatal("Match(impl) = true, want only the last skill to match")
+	}
+}
+
+func TestParseStatuses(t *testing.T) {
+	got, err := ParseStatuses([]string{"running, Blocked", "done"})
+	if err != nil {
+		t.Fatalf("ParseStatuses() error: %v", err)
+	}
+	want := []Status{StatusRunning, StatusBlocked, StatusDone}
+	if len(got) != len(want) {
+		t.Fatalf("ParseStatuses() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ParseStatuses() = %v, want %v", got, want)
+		}
+	}
+
+	if _, err := ParseStatuses([]string{"finished"}); err == nil {
+		t.Fatal("ParseStatuses(finished) error = nil, want error")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+
+	got, err := ParseTime("24h", now)
+	if err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("ParseTime(24h) = %v, %v, want a day ago", got, err)
+	}
+	got, err = ParseTime("2026-03-07T08:00:00Z", now)
+	if err != nil || !got.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("ParseTime(RFC 3339) = %v, %v, want 08:00 UTC", got, err)
+	}
+	got, err = ParseTime("2026-03-01", now)
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("ParseTime(date) = %v, %v, want local midnight", got, err)
+	}
+	if got, err := ParseTime("", now); err != nil || !got.IsZero() {
+		t.Fatalf("ParseTime(empty) = %v, %v, want zero", got, err)
+	}
+	for _, value := range []string{"-5m", "yesterday"} {
+		if _, err := ParseTime(value, now); err == nil {
+			t.Fatalf("ParseTime(%s) error = nil, want error", value)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// IterationMark records where an orchestrator iteration began in a pair of
+// log files, so log readers can show iteration boundaries and start reading
+// from a point in time even though log lines carry no timestamps.
+type IterationMark struct {
+	Iteration     int       `json:"iteration"`
+	MaxIterations int       `json:"max_iterations,omitempty"`
+	Skill         string    `json:"skill"`
+	StartedAt     time.Time `json:"started_at"`
+	StdoutOffset  int64     `json:"stdout_offset"`
+	StderrOffset  int64     `json:"stderr_offset"`
+}
+
+// IterationsPath returns the iteration log that sits next to stdoutPath. Both
+// sessions and runs keep one alongside their stdout.log and stderr.log.
+func IterationsPath(stdoutPath string) string {
+	return filepath.Join(filepath.Dir(stdoutPath), "iterations.jsonl")
+}
+
+// RecordIteration appends an iteration mark using the current sizes of the
+// given log files as its offsets.
+func RecordIteration(stdoutPath string, stderrPath string, iteration int, maxIterations int, skill string) error {
+	mark := IterationMark{
+		Iteration:     iteration,
+		MaxIterations: maxIterations,
+		Skill:         skill,
+		StartedAt:     time.Now().UTC(),
+		StdoutOffset:  fileSize(stdoutPath),
+		StderrOffset:  fileSize(stderrPath),
+	}
+
+	data, err := json.Marshal(mark)
+	if err != nil {
+		return fmt.Errorf("marshal iteration mark: %w", err)
+	}
+
+	file, err := os.OpenFile(IterationsPath(stdoutPath), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open iteration log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write iteration log: %w", err)
+	}
+	return file.Close()
+}
+
+// LoadIterations returns the iteration marks recorded next to stdoutPath in
+// the order they were written. A missing iteration log yields no marks.
+func LoadIterations(stdoutPath string) ([]IterationMark, error) {
+	file, err := os.Open(IterationsPath(stdoutPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open iteration log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var marks []IterationMark
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var mark IterationMark
+		if err := json.Unmarshal(scanner.Bytes(), &mark); err != nil {
+			// The writer may be in the middle of appending the last line.
+			continue
+		}
+		marks = append(marks, mark)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read iteration log: %w", err)
+	}
+	return marks, nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// TailOffset returns the offset in r where its last lines lines begin,
+// reading backwards from the end so that large logs are not read whole.
+// Nothing before from is considered, and a final newline does not start
+// another line. Zero lines keep from.
+func TailOffset(r io.ReadSeeker, from int64, lines int) (int64, error) {
+	if lines <= 0 {
+		return from, nil
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 32<<10)
+	for end := size; end > from; {
+		start := max(from, end-int64(len(buf)))
+		chunk := buf[:end-start]
+		if _, err := r.Seek(start, io.SeekStart); err != nil {
+			return 0, err
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			lines--
+			if lines == 0 {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return from, nil
+}
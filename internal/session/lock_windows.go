@@ -0,0 +1,15 @@
+//go:build windows
+
+package session
+
+import "os"
+
+// lockFile does nothing on Windows: updates are only serialized within the
+// process there.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
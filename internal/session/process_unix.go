@@ -0,0 +1,43 @@
+//go:build !windows
+
+package session
+
+import (
+	"errors"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// groupLeader reports whether pid runs and leads its own process group, as
+// the detached process of a session does. A process that reused the PID of a
+// session that died does not.
+func groupLeader(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	pgid, err := syscall.Getpgid(pid)
+	return err == nil && pgid == pid
+}
+
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// terminateProcessGroup signals every process of the group led by pid, so
+// that agents started by a detached run stop with it.
+func terminateProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+// detachedProcAttr starts a process in a new session, without a controlling
+// terminal and out of the caller's process group.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
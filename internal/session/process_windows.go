@@ -0,0 +1,45 @@
+//go:build windows
+
+package session
+
+import (
+	"os"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+// groupLeader reports whether pid runs; Windows has no process groups to
+// check.
+func groupLeader(pid int) bool {
+	return processAlive(pid)
+}
+
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+// terminateProcessGroup stops the process; Windows has no process groups to
+// signal.
+func terminateProcessGroup(pid int) error {
+	return terminateProcess(pid)
+}
+
+// detachedProcAttr starts a process in a new process group.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
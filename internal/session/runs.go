@@ -0,0 +1,184 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerResume   = "resume"
+	TriggerWatch    = "watch"
+)
+
+// Run records a single workflow execution performed by a resident session,
+// such as one cron-triggered execution of a scheduled workflow.
+type Run struct {
+	ID               string     `json:"id"`
+	SessionID        string     `json:"session_id"`
+	Trigger          string     `json:"trigger"`
+	Status           Status     `json:"status"`
+	Entrypoint       string     `json:"entrypoint,omitempty"`
+	Prompt           string     `json:"prompt,omitempty"`
+	StdoutPath       string     `json:"stdout_path"`
+	StderrPath       string     `json:"stderr_path"`
+	StartedAt        time.Time  `json:"started_at"`
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+	CurrentIteration int        `json:"current_iteration,omitempty"`
+	MaxIterations    int        `json:"max_iterations,omitempty"`
+	CurrentSkill     string     `json:"current_skill,omitempty"`
+	LastSkillOutput  string     `json:"last_skill_output,omitempty"`
+	BlockReason      string     `json:"block_reason,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+}
+
+// Duration reports how long the run took, or how long it has been running so far.
+func (r *Run) Duration() time.Duration {
+	end := time.Now().UTC()
+	if r.EndedAt != nil {
+		end = *r.EndedAt
+	}
+	if r.StartedAt.IsZero() || end.Before(r.StartedAt) {
+		return 0
+	}
+	return end.Sub(r.StartedAt)
+}
+
+func RunsDir(meta *Metadata) string {
+	return filepath.Join(filepath.Dir(meta.ScriptPath), "runs")
+}
+
+func NewRun(meta *Metadata, trigger string) (*Run, error) {
+	now := time.Now().UTC()
+	id, err := newID(now)
+	if err != nil {
+		return nil, err
+	}
+
+	runDir := filepath.Join(RunsDir(meta), id)
+	if err := os.MkdirAll(runDir, 0o750); err != nil {
+		return nil, fmt.Errorf("create run directory: %w", err)
+	}
+
+	run := &Run{
+		ID:         id,
+		SessionID:  meta.ID,
+		Trigger:    trigger,
+		Status:     StatusRunning,
+		StdoutPath: filepath.Join(runDir, "stdout.log"),
+		StderrPath: filepath.Join(runDir, "stderr.log"),
+		StartedAt:  now,
+	}
+	if err := SaveRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func SaveRun(run *Run) error {
+	runFilePath := filepath.Join(filepath.Dir(run.StdoutPath), "run.json")
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal run metadata: %w", err)
+	}
+
+	if err := os.WriteFile(runFilePath, data, 0o600); err != nil {
+		return fmt.Errorf("write run metadata: %w", err)
+	}
+	return nil
+}
+
+func LoadRun(meta *Metadata, runID string) (*Run, error) {
+	if strings.TrimSpace(runID) == "" || strings.ContainsAny(runID, `/\`) || runID == "." || runID == ".." {
+		return nil, os.ErrNotExist
+	}
+	return loadRunFromPath(filepath.Join(RunsDir(meta), runID, "run.json"))
+}
+
+// ListRuns returns the recorded runs of a session, newest first. Runs that
+// were still in progress when the resident process went away are reported as
+// stopped. The run records are not changed: only the resident process
+// writes them.
+func ListRuns(meta *Metadata) ([]*Run, error) {
+	entries, err := os.ReadDir(RunsDir(meta))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read runs directory: %w", err)
+	}
+
+	runs := make([]*Run, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := loadRunFromPath(filepath.Join(RunsDir(meta), entry.Name(), "run.json"))
+		if err != nil {
+			continue
+		}
+		if run.Status == StatusRunning && (meta.Status == StatusStopped || meta.Status == StatusFailed) {
+			run.Status = StatusStopped
+			if run.EndedAt == nil {
+				run.EndedAt = meta.EndedAt
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+
+	return runs, nil
+}
+
+func loadRunFromPath(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("read run metadata: %w", err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("parse run metadata: %w", err)
+	}
+	return &run, nil
+}
+
+// Logs holds the stdout and stderr log files of a session or run, opened for
+// appending.
+type Logs struct {
+	Stdout *os.File
+	Stderr *os.File
+}
+
+// OpenLogs opens the log files at the given paths for appending.
+func OpenLogs(stdoutPath string, stderrPath string) (*Logs, error) {
+	stdout, err := os.OpenFile(stdoutPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	stderr, err := os.OpenFile(stderrPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		_ = stdout.Close()
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	return &Logs{Stdout: stdout, Stderr: stderr}, nil
+}
+
+// Close closes the log files.
+func (l *Logs) Close() error {
+	return errors.Join(l.Stdout.Close(), l.Stderr.Close())
+}
@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,6 +26,7 @@ type Status string
 const (
 	StatusPending   Status = "pending"
 	StatusScheduled Status = "scheduled"
+	StatusPaused    Status = "paused"
 	StatusRunning   Status = "running"
 	StatusBlocked   Status = "blocked"
 	StatusIdle      Status = "idle"
@@ -33,39 +35,72 @@ const (
 	StatusStopped   Status = "stopped"
 )
 
+// IsActiveStatus reports whether a session with status still has a live
+// process, either executing the workflow or waiting for its next run.
+func IsActiveStatus(status Status) bool {
+	return status == StatusRunning || status == StatusScheduled || status == StatusPaused
+}
+
+// IsTerminalStatus reports whether a session with status has finished for
+// good and will not run again.
+func IsTerminalStatus(status Status) bool {
+	return status == StatusDone || status == StatusFailed || status == StatusStopped
+}
+
 type Metadata struct {
-	ID                 string     `json:"id"`
-	WorkflowName       string     `json:"workflow_name,omitempty"`
-	StorageName        string     `json:"storage_name,omitempty"`
-	Skill              string     `json:"skill"`
-	Runtime            string     `json:"runtime"`
-	RepoRoot           string     `json:"repo_root"`
-	WorkingDir         string     `json:"working_dir"`
-	ConfigPath         string     `json:"config_path,omitempty"`
-	Schedule           string     `json:"schedule,omitempty"`
-	Command            []string   `json:"command"`
-	TmuxSession        string     `json:"tmux_session"`
-	ScriptPath         string     `json:"script_path"`
-	StdoutPath         string     `json:"stdout_path"`
-	StderrPath         string     `json:"stderr_path"`
-	ExitCodePath       string     `json:"exit_code_path"`
-	Status             Status     `json:"status"`
-	PID                int        `json:"pid,omitempty"`
-	StartedAt          time.Time  `json:"started_at"`
-	LastOutputAt       time.Time  `json:"last_output_at"`
-	EndedAt            *time.Time `json:"ended_at,omitempty"`
-	NextRun            *time.Time `json:"next_run,omitempty"`
-	CurrentIteration   int        `json:"current_iteration,omitempty"`
-	MaxIterations      int        `json:"max_iterations,omitempty"`
-	CurrentSkill       string     `json:"current_skill,omitempty"`
-	LastSkillOutput    string     `json:"last_skill_output,omitempty"`
-	BlockReason        string     `json:"block_reason,omitempty"`
-	ResumeSkill        string     `json:"resume_skill,omitempty"`
-	ResumePrompt       string     `json:"resume_prompt,omitempty"`
-	IdleTimeoutSeconds int        `json:"idle_timeout_seconds"`
-	MaxRestarts        int        `json:"max_restarts"`
-	RestartCount       int        `json:"restart_count"`
-	LastError          string     `json:"last_error,omitempty"`
+	ID                 string            `json:"id"`
+	WorkflowName       string            `json:"workflow_name,omitempty"`
+	StorageName        string            `json:"storage_name,omitempty"`
+	Skill              string            `json:"skill"`
+	Runtime            string            `json:"runtime"`
+	RepoRoot           string            `json:"repo_root"`
+	WorkingDir         string            `json:"working_dir"`
+	ConfigPath         string            `json:"config_path,omitempty"`
+	Vars               map[string]string `json:"vars,omitempty"`
+	Schedule           string            `json:"schedule,omitempty"`
+	Command            []string          `json:"command"`
+	TmuxSession        string            `json:"tmux_session"`
+	ScriptPath         string            `json:"script_path"`
+	StdoutPath         string            `json:"stdout_path"`
+	StderrPath         string            `json:"stderr_path"`
+	ExitCodePath       string            `json:"exit_code_path"`
+	Status             Status            `json:"status"`
+	PID                int               `json:"pid,omitempty"`
+	StartedAt          time.Time         `json:"started_at"`
+	LastOutputAt       time.Time         `json:"last_output_at"`
+	EndedAt            *time.Time        `json:"ended_at,omitempty"`
+	NextRun            *time.Time        `json:"next_run,omitempty"`
+	CurrentIteration   int               `json:"current_iteration,omitempty"`
+	MaxIterations      int               `json:"max_iterations,omitempty"`
+	CurrentSkill       string            `json:"current_skill,omitempty"`
+	LastSkillOutput    string            `json:"last_skill_output,omitempty"`
+	BlockReason        string            `json:"block_reason,omitempty"`
+	ResumeSkill        string            `json:"resume_skill,omitempty"`
+	ResumePrompt       string            `json:"resume_prompt,omitempty"`
+	IdleTimeoutSeconds int               `json:"idle_timeout_seconds"`
+	MaxRestarts        int               `json:"max_restarts"`
+	RestartCount       int               `json:"restart_count"`
+	LastError          string            `json:"last_error,omitempty"`
+	LastRunID          string            `json:"last_run_id,omitempty"`
+	ResidentPID        int               `json:"resident_pid,omitempty"`
+	Watch              []string          `json:"watch,omitempty"`
+	Routes             []RouteStep       `json:"routes,omitempty"`
+	// Backend runs the session: BackendTmux (also when empty),
+	// BackendProcess or BackendForeground.
+	Backend string `json:"backend,omitempty"`
+}
+
+// RouteStep is a route taken by the orchestrator: the skill that ran and the
+// id of the route the router selected after it.
+type RouteStep struct {
+	Skill string `json:"skill"`
+	Route string `json:"route"`
+}
+
+// IsResident reports whether the session is served by a resident process that
+// runs the workflow repeatedly, either on a cron schedule or on file changes.
+func (m *Metadata) IsResident() bool {
+	return m.Schedule != "" || len(m.Watch) > 0
 }
 
 func ResolveRepoRoot(cwd string) (string, error) {
@@ -178,19 +213,70 @@ func New(repoRoot string, workingDir string, workflowName string, skill string,
 }
 
 func Save(meta *Metadata) error {
-	sessionFilePath := filepath.Join(filepath.Dir(meta.ScriptPath), "session.json")
-
 	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal session metadata: %w", err)
 	}
 
-	if err := os.WriteFile(sessionFilePath, data, 0o600); err != nil {
+	// Write to a temporary file first so readers never see partial metadata
+	// while the session process updates it.
+	path := metadataPath(meta)
+	tmp, err := os.CreateTemp(filepath.Dir(path), "session-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("write session metadata: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
 		return fmt.Errorf("write session metadata: %w", err)
 	}
 	return nil
 }
 
+// updateMu serializes Update within the process; the lock file of each
+// session serializes it across processes.
+var updateMu sync.Mutex
+
+// Update applies update to the stored metadata of meta and saves it, keeping
+// the fields the process of the session wrote since meta was loaded. meta is
+// replaced by the saved metadata. Concurrent updates of the same session do
+// not overwrite each other.
+func Update(meta *Metadata, update func(*Metadata)) error {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	lock, err := os.OpenFile(filepath.Join(filepath.Dir(meta.ScriptPath), "session.lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("open session lock: %w", err)
+	}
+	defer func() { _ = lock.Close() }()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("lock session metadata: %w", err)
+	}
+	defer func() { _ = unlockFile(lock) }()
+
+	stored, err := LoadFromPath(metadataPath(meta))
+	if err != nil {
+		return err
+	}
+	update(stored)
+	if err := Save(stored); err != nil {
+		return err
+	}
+	*meta = *stored
+	return nil
+}
+
+func metadataPath(meta *Metadata) string {
+	return filepath.Join(filepath.Dir(meta.ScriptPath), "session.json")
+}
+
 func UpdateCommand(meta *Metadata, command []string) error {
 	if len(command) == 0 {
 		return fmt.Errorf("command is required")
@@ -269,10 +355,17 @@ func DeleteByID(repoRoot string, id string) error {
 	if err != nil {
 		return err
 	}
-	if meta.TmuxSession != "" {
-		if err := killTMuxSession(meta.TmuxSession); err != nil && !errors.Is(err, exec.ErrNotFound) {
-			return fmt.Errorf("kill tmux session %s: %w", meta.TmuxSession, err)
-		}
+	backend, err := backendOf(meta)
+	if err != nil {
+		return err
+	}
+	// Stop the session while its directory still holds the pidfile of a
+	// detached process.
+	if err := backend.Stop(meta); err != nil && !errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("stop session %s: %w", meta.ID, err)
+	}
+	if err := backend.Cleanup(meta); err != nil && !errors.Is(err, exec.ErrNotFound) {
+		return err
 	}
 	sessionDir := filepath.Dir(meta.ScriptPath)
 	if err := os.RemoveAll(sessionDir); err != nil {
@@ -282,8 +375,17 @@ func DeleteByID(repoRoot string, id string) error {
 }
 
 func Start(meta *Metadata) error {
-	if _, err := exec.LookPath("tmux"); err != nil {
-		return fmt.Errorf("tmux is required but not found on PATH")
+	// A blocked foreground session resumes as a detached run.
+	if meta.Backend == BackendForeground {
+		name, err := ResolveBackend("")
+		if err != nil {
+			return err
+		}
+		meta.Backend = name
+	}
+	backend, err := backendOf(meta)
+	if err != nil {
+		return err
 	}
 
 	if err := os.MkdirAll(filepath.Dir(meta.StdoutPath), 0o750); err != nil {
@@ -294,18 +396,24 @@ func Start(meta *Metadata) error {
 		return fmt.Errorf("remove previous exit code: %w", err)
 	}
 
-	_ = killTMuxSession(meta.TmuxSession)
-
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", meta.TmuxSession, "exec bash "+shellQuote(meta.ScriptPath))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("start tmux session: %w", err)
+	switch {
+	case BackendName(meta) != BackendTmux:
+		meta.TmuxSession = ""
+	case meta.TmuxSession == "":
+		meta.TmuxSession = fmt.Sprintf("skill-loop-%s", meta.ID)
 	}
-	// Keep pane content after exit so users can inspect run output with attach/capture.
-	_ = exec.Command("tmux", "set-option", "-t", meta.TmuxSession, "remain-on-exit", "on").Run()
+
+	// The started process owns the metadata from now on, so everything but
+	// the PID is saved before it starts and the PID is merged into whatever
+	// the process wrote by then.
+	previous := *meta
 	if meta.StartedAt.IsZero() {
 		meta.StartedAt = time.Now().UTC()
 	}
 	meta.Status = StatusRunning
+	if meta.IsResident() {
+		meta.Status = StatusScheduled
+	}
 	meta.EndedAt = nil
 	meta.BlockReason = ""
 	meta.ResumeSkill = ""
@@ -314,16 +422,28 @@ func Start(meta *Metadata) error {
 	if meta.LastOutputAt.IsZero() {
 		meta.LastOutputAt = meta.StartedAt
 	}
+	if err := Save(meta); err != nil {
+		*meta = previous
+		return fmt.Errorf("persist starting session: %w", err)
+	}
 
-	pid, err := panePID(meta.TmuxSession)
-	if err == nil {
-		meta.PID = pid
+	pid, err := backend.Start(meta)
+	if err != nil {
+		*meta = previous
+		if saveErr := Save(meta); saveErr != nil {
+			return fmt.Errorf("%w (restoring session metadata failed: %v)", err, saveErr)
+		}
+		return err
+	}
+	if pid <= 0 {
+		return nil
 	}
 
-	if err := Save(meta); err != nil {
-		killErr := killTMuxSession(meta.TmuxSession)
-		if killErr != nil {
-			return fmt.Errorf("persist started session: %w (rollback failed: %v)", err, killErr)
+	if err := Update(meta, func(stored *Metadata) { stored.PID = pid }); err != nil {
+		meta.PID = pid
+		stopErr := backend.Stop(meta)
+		if stopErr != nil {
+			return fmt.Errorf("persist started session: %w (rollback failed: %v)", err, stopErr)
 		}
 		return fmt.Errorf("persist started session: %w", err)
 	}
@@ -331,8 +451,12 @@ func Start(meta *Metadata) error {
 }
 
 func Stop(meta *Metadata) error {
-	if err := killTMuxSession(meta.TmuxSession); err != nil {
-		return fmt.Errorf("stop tmux session: %w", err)
+	backend, err := backendOf(meta)
+	if err != nil {
+		return err
+	}
+	if err := backend.Stop(meta); err != nil {
+		return err
 	}
 	now := time.Now().UTC()
 	meta.Status = StatusStopped
@@ -345,25 +469,33 @@ func Stop(meta *Metadata) error {
 }
 
 func Attach(meta *Metadata) error {
-	cmd := exec.Command("tmux", "attach-session", "-t", meta.TmuxSession)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	backend, err := backendOf(meta)
+	if err != nil {
+		return err
+	}
+	return backend.Attach(meta)
 }
 
 func Reconcile(meta *Metadata) error {
 	updateLastOutputAt(meta)
 
+	backend, err := backendOf(meta)
+	if err != nil {
+		return err
+	}
+
 	exitCode, hasExitCode, err := ReadExitCode(meta.ExitCodePath)
 	if err != nil {
 		return err
 	}
 
 	if hasExitCode {
-		cleanupErr := killTMuxSession(meta.TmuxSession)
-		if cleanupErr != nil && !errors.Is(cleanupErr, exec.ErrNotFound) {
-			return fmt.Errorf("cleanup tmux session %s: %w", meta.TmuxSession, cleanupErr)
+		if err := backend.Cleanup(meta); err != nil {
+			return err
+		}
+		// A foreground run records its final status before the exit code.
+		if meta.Backend == BackendForeground {
+			return nil
 		}
 
 		now := time.Now().UTC()
@@ -392,11 +524,11 @@ func Reconcile(meta *Metadata) error {
 		return Save(meta)
 	}
 
-	hasSession, err := HasTMuxSession(meta.TmuxSession)
+	running, err := backend.Running(meta)
 	if err != nil {
 		return err
 	}
-	if hasSession {
+	if running {
 		if meta.Status == StatusPending {
 			meta.Status = StatusRunning
 			meta.EndedAt = nil
@@ -405,11 +537,19 @@ func Reconcile(meta *Metadata) error {
 		return nil
 	}
 
+	// A detached process that died without writing an exit code left its
+	// pidfile behind; its PID may be reused by an unrelated process.
+	if BackendName(meta) == BackendProcess {
+		if err := backend.Cleanup(meta); err != nil {
+			return err
+		}
+	}
+
 	if meta.Status == StatusRunning || meta.Status == StatusIdle || meta.Status == StatusPending {
 		now := time.Now().UTC()
 		meta.Status = StatusFailed
 		if meta.LastError == "" {
-			meta.LastError = "tmux session disappeared before exit code was written"
+			meta.LastError = BackendName(meta) + " session disappeared before exit code was written"
 		}
 		if meta.EndedAt == nil {
 			meta.EndedAt = &now
@@ -424,8 +564,8 @@ func BuildResumeCommand(meta *Metadata, humanInput string) ([]string, error) {
 	if meta.Status != StatusBlocked {
 		return nil, fmt.Errorf("session %s is not blocked", meta.ID)
 	}
-	if meta.Schedule != "" {
-		return nil, fmt.Errorf("resume is not supported for scheduled sessions")
+	if meta.IsResident() {
+		return nil, fmt.Errorf("scheduled session %s is resumed in place by its scheduler; use RequestResume", meta.ID)
 	}
 	if meta.ConfigPath == "" {
 		return nil, fmt.Errorf("session %s is missing config_path", meta.ID)
@@ -469,6 +609,17 @@ func BuildResumeCommand(meta *Metadata, humanInput string) ([]string, error) {
 		}
 	}
 
+	if prompt := BuildResumePrompt(meta, humanInput); prompt != "" {
+		command = append(command, "--prompt", prompt)
+	}
+	command = append(command, "--entrypoint", meta.ResumeSkill)
+
+	return command, nil
+}
+
+// BuildResumePrompt combines the prompt a blocked session was waiting on with
+// the human input supplied when resuming it.
+func BuildResumePrompt(meta *Metadata, humanInput string) string {
 	prompt := strings.TrimSpace(meta.ResumePrompt)
 	humanInput = strings.TrimSpace(humanInput)
 	switch {
@@ -477,12 +628,7 @@ func BuildResumeCommand(meta *Metadata, humanInput string) ([]string, error) {
 	case humanInput != "":
 		prompt = humanInput
 	}
-	if prompt != "" {
-		command = append(command, "--prompt", prompt)
-	}
-	command = append(command, "--entrypoint", meta.ResumeSkill)
-
-	return command, nil
+	return prompt
 }
 
 func preferredWorkingDir(configPath string, fallback string) string {
@@ -551,6 +697,7 @@ func writeScript(meta *Metadata) error {
 		"#!/bin/bash",
 		"set +euo pipefail",
 		"cd " + shellQuote(meta.WorkingDir),
+		"if [ -f " + shellQuote(EnvPath(meta)) + " ]; then . " + shellQuote(EnvPath(meta)) + "; fi",
 		"export SKILL_LOOP_SESSION_ID=" + shellQuote(meta.ID),
 		"export SKILL_LOOP_SESSION_REPO_ROOT=" + shellQuote(meta.RepoRoot),
 		"{ " + cmdLine.String() + " 2> >(tee -a " + shellQuote(meta.StderrPath) + " >&2); } | tee -a " + shellQuote(meta.StdoutPath),
@@ -566,6 +713,36 @@ func writeScript(meta *Metadata) error {
 	return nil
 }
 
+// EnvPath returns the env file of a session, sourced by its run script.
+func EnvPath(meta *Metadata) string {
+	return filepath.Join(filepath.Dir(meta.ScriptPath), "env.sh")
+}
+
+// WriteEnv writes the env file of meta. It sets the variables of env for the
+// session command, or unsets those without a value. The values may be
+// secrets, so they are kept out of the command and the metadata and the file
+// is only readable by its owner.
+func WriteEnv(meta *Metadata, env map[string]*string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		if value := env[name]; value != nil {
+			b.WriteString("export " + name + "=" + shellQuote(*value) + "\n")
+		} else {
+			b.WriteString("unset " + name + "\n")
+		}
+	}
+	if err := os.WriteFile(EnvPath(meta), []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write session env file: %w", err)
+	}
+	return nil
+}
+
 func panePID(sessionName string) (int, error) {
 	cmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_pid}")
 	out, err := cmd.Output()
@@ -1,9 +1,15 @@
 package session
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -366,6 +372,90 @@ func TestReconcileKeepsBlockedStatusWhenExitCodeExists(t *testing.T) {
 	}
 }
 
+func TestReconcileForegroundSession(t *testing.T) {
+	tempDir := t.TempDir()
+	meta := &Metadata{
+		ID:           "foreground-session",
+		RepoRoot:     tempDir,
+		ScriptPath:   filepath.Join(tempDir, "run.sh"),
+		ExitCodePath: filepath.Join(tempDir, "exit.code"),
+		StdoutPath:   filepath.Join(tempDir, "stdout.log"),
+		StderrPath:   filepath.Join(tempDir, "stderr.log"),
+		Status:       StatusRunning,
+		PID:          os.Getpid(),
+		Backend:      BackendForeground,
+		StartedAt:    time.Now().UTC(),
+		LastOutputAt: time.Now().UTC(),
+	}
+
+	// A live foreground process needs neither tmux nor an exit code.
+	t.Setenv("PATH", t.TempDir())
+	if err := Reconcile(meta); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if meta.Status != StatusRunning {
+		t.Fatalf("status = %s, want %s", meta.Status, StatusRunning)
+	}
+
+	meta.PID = -1
+	if err := Reconcile(meta); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if meta.Status != StatusFailed || meta.LastError != "foreground session disappeared before exit code was written" {
+		t.Fatalf("status = %s (%q), want failed for a vanished process", meta.Status, meta.LastError)
+	}
+
+	if err := Attach(meta); err == nil || !strings.Contains(err.Error(), "runs in the foreground") {
+		t.Fatalf("Attach() error = %v, want foreground error", err)
+	}
+}
+
+// childBackend starts nothing but writes the metadata the way the started
+// scheduler process does.
+type childBackend struct {
+	foregroundBackend
+	pid int
+}
+
+func (b childBackend) Start(meta *Metadata) (int, error) {
+	stored, err := LoadByID(meta.RepoRoot, meta.ID)
+	if err != nil {
+		return 0, err
+	}
+	stored.ResidentPID = b.pid
+	return b.pid, Save(stored)
+}
+
+func TestStartKeepsMetadataWrittenByTheStartedProcess(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	backends["child"] = childBackend{pid: 4242}
+	t.Cleanup(func() { delete(backends, "child") })
+
+	repoRoot := t.TempDir()
+	meta, err := New(repoRoot, repoRoot, "nightly", "orchestrator", "skill-loop", []string{"skill-loop", "run"}, 0, 0)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	meta.Backend = "child"
+	meta.Schedule = "@hourly"
+	if err := Start(meta); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	loaded, err := LoadByID(repoRoot, meta.ID)
+	if err != nil {
+		t.Fatalf("LoadByID() error: %v", err)
+	}
+	for _, got := range []*Metadata{meta, loaded} {
+		if got.ResidentPID != 4242 || got.PID != 4242 {
+			t.Fatalf("ResidentPID = %d, PID = %d, want both 4242", got.ResidentPID, got.PID)
+		}
+		if got.Status != StatusScheduled || got.Schedule != "@hourly" {
+			t.Fatalf("Status = %s, Schedule = %q, want the scheduled session saved before the start", got.Status, got.Schedule)
+		}
+	}
+}
+
 func TestBuildResumeCommand(t *testing.T) {
 	meta := &Metadata{
 		ID:          "blocked-session",
@@ -474,6 +564,44 @@ func TestUpdateCommandPrefersConfigDirectory(t *testing.T) {
 	}
 }
 
+func TestUpdateKeepsConcurrentChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tempDir := t.TempDir()
+	meta, err := New(tempDir, tempDir, "nightly-review", "skill-1", "claude", []string{"echo", "hello"}, 10*time.Second, 2)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	const updates = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			current := *meta
+			errs <- Update(&current, func(stored *Metadata) {
+				stored.Routes = append(stored.Routes, RouteStep{Skill: "review", Route: "retry"})
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+	}
+
+	loaded, err := LoadByID(tempDir, meta.ID)
+	if err != nil {
+		t.Fatalf("LoadByID() error: %v", err)
+	}
+	if len(loaded.Routes) != updates {
+		t.Fatalf("len(Routes) = %d, want all %d updates kept", len(loaded.Routes), updates)
+	}
+}
+
 func TestShellQuote(t *testing.T) {
 	tests := []struct {
 		input string
@@ -512,3 +640,390 @@ func TestNewID(t *testing.T) {
 		t.Errorf("expected ID to start with %s, got %s", expectedPrefix, id)
 	}
 }
+
+func TestRuns(t *testing.T) {
+	t.Run("records and lists runs newest first", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		tempDir := t.TempDir()
+		meta, err := New(tempDir, tempDir, "daily-check", "orchestrator", "skill-loop", []string{"echo", "hello"}, 10*time.Second, 2)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+
+		first, err := NewRun(meta, TriggerSchedule)
+		if err != nil {
+			t.Fatalf("NewRun() error: %v", err)
+		}
+		ended := first.StartedAt.Add(time.Minute)
+		first.Status = StatusDone
+		first.EndedAt = &ended
+		first.LastSkillOutput = "yesterday's result"
+		if err := SaveRun(first); err != nil {
+			t.Fatalf("SaveRun() error: %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		second, err := NewRun(meta, TriggerSchedule)
+		if err != nil {
+			t.Fatalf("NewRun() error: %v", err)
+		}
+
+		runs, err := ListRuns(meta)
+		if err != nil {
+			t.Fatalf("ListRuns() error: %v", err)
+		}
+		if len(runs) != 2 {
+			t.Fatalf("runs = %d, want 2", len(runs))
+		}
+		if runs[0].ID != second.ID || runs[1].ID != first.ID {
+			t.Fatalf("runs order = [%s %s], want [%s %s]", runs[0].ID, runs[1].ID, second.ID, first.ID)
+		}
+		if runs[1].Duration() != time.Minute {
+			t.Fatalf("Duration() = %s, want 1m", runs[1].Duration())
+		}
+
+		loaded, err := LoadRun(meta, first.ID)
+		if err != nil {
+			t.Fatalf("LoadRun() error: %v", err)
+		}
+		if loaded.LastSkillOutput != "yesterday's result" {
+			t.Fatalf("LastSkillOutput = %q, want yesterday's result", loaded.LastSkillOutput)
+		}
+
+		metas, err := List(tempDir)
+		if err != nil {
+			t.Fatalf("List() error: %v", err)
+		}
+		if len(metas) != 1 {
+			t.Fatalf("List() = %d sessions, want run records to be ignored", len(metas))
+		}
+	})
+
+	t.Run("reports in-progress runs stopped once the session stops", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		tempDir := t.TempDir()
+		meta, err := New(tempDir, tempDir, "daily-check", "orchestrator", "skill-loop", []string{"echo", "hello"}, 10*time.Second, 2)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		run, err := NewRun(meta, TriggerSchedule)
+		if err != nil {
+			t.Fatalf("NewRun() error: %v", err)
+		}
+
+		meta.Status = StatusStopped
+		runs, err := ListRuns(meta)
+		if err != nil {
+			t.Fatalf("ListRuns() error: %v", err)
+		}
+		if len(runs) != 1 || runs[0].Status != StatusStopped {
+			t.Fatalf("runs = %+v, want one stopped run", runs)
+		}
+		stored, err := LoadRun(meta, run.ID)
+		if err != nil {
+			t.Fatalf("LoadRun() error: %v", err)
+		}
+		if stored.Status != StatusRunning {
+			t.Fatalf("stored run status = %s, want ListRuns to leave it unchanged", stored.Status)
+		}
+	})
+
+	t.Run("rejects path traversal in run ids", func(t *testing.T) {
+		meta := &Metadata{ScriptPath: filepath.Join(t.TempDir(), "run.sh")}
+		if _, err := LoadRun(meta, "../session"); !os.IsNotExist(err) {
+			t.Fatalf("LoadRun() error = %v, want not exist", err)
+		}
+	})
+}
+
+func TestWriteEnvAppliesToTheSessionCommand(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SKILL_LOOP_TEST_UNSET", "inherited")
+	tempDir := t.TempDir()
+
+	command := []string{"bash", "-c", `printf '%s|%s' "$SKILL_LOOP_TEST_TOKEN" "${SKILL_LOOP_TEST_UNSET-unset}"`}
+	meta, err := New(tempDir, tempDir, "env", "orchestrator", "skill-loop", command, 0, 0)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	token := "it's secret"
+	if err := WriteEnv(meta, map[string]*string{
+		"SKILL_LOOP_TEST_TOKEN": &token,
+		"SKILL_LOOP_TEST_UNSET": nil,
+	}); err != nil {
+		t.Fatalf("WriteEnv() error: %v", err)
+	}
+
+	if err := exec.Command("bash", meta.ScriptPath).Run(); err != nil {
+		t.Fatalf("run script: %v", err)
+	}
+	stdout, err := os.ReadFile(meta.StdoutPath)
+	if err != nil {
+		t.Fatalf("read stdout: %v", err)
+	}
+	if got, want := string(stdout), "it's secret|unset"; got != want {
+		t.Fatalf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestOpenLogsAppendsToLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	stdoutPath := filepath.Join(dir, "stdout.log")
+	stderrPath := filepath.Join(dir, "stderr.log")
+	if err := os.WriteFile(stdoutPath, []byte("earlier run\n"), 0o600); err != nil {
+		t.Fatalf("write stdout log: %v", err)
+	}
+
+	logs, err := OpenLogs(stdoutPath, stderrPath)
+	if err != nil {
+		t.Fatalf("OpenLogs() error: %v", err)
+	}
+	if _, err := io.WriteString(logs.Stdout, "logged stdout\n"); err != nil {
+		t.Fatalf("write stdout: %v", err)
+	}
+	if _, err := io.WriteString(logs.Stderr, "logged stderr\n"); err != nil {
+		t.Fatalf("write stderr: %v", err)
+	}
+	if err := logs.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	for path, want := range map[string]string{stdoutPath: "earlier run\nlogged stdout\n", stderrPath: "logged stderr\n"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error: %v", path, err)
+		}
+		if string(data) != want {
+			t.Fatalf("%s = %q, want %q", path, data, want)
+		}
+	}
+}
+
+func TestRequestTrigger(t *testing.T) {
+	t.Run("queues a control request for the resident process", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		tempDir := t.TempDir()
+		meta, err := New(tempDir, tempDir, "daily-check", "orchestrator", "skill-loop", []string{"echo", "hello"}, 10*time.Second, 2)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		meta.Schedule = "0 9 * * *"
+		meta.Status = StatusScheduled
+		meta.ResidentPID = os.Getpid()
+
+		signals := make(chan os.Signal, 1)
+		NotifyControl(signals)
+		defer signal.Stop(signals)
+
+		if err := RequestTrigger(meta, "  check the flaky test  "); err != nil {
+			t.Fatalf("RequestTrigger() error: %v", err)
+		}
+
+		requests, err := DrainControl(meta)
+		if err != nil {
+			t.Fatalf("DrainControl() error: %v", err)
+		}
+		if len(requests) != 1 {
+			t.Fatalf("requests = %d, want 1", len(requests))
+		}
+		if requests[0].Action != ControlTrigger {
+			t.Fatalf("Action = %q, want %q", requests[0].Action, ControlTrigger)
+		}
+		if requests[0].Prompt != "check the flaky test" {
+			t.Fatalf("Prompt = %q, want trimmed prompt", requests[0].Prompt)
+		}
+
+		requests, err = DrainControl(meta)
+		if err != nil {
+			t.Fatalf("DrainControl() error: %v", err)
+		}
+		if len(requests) != 0 {
+			t.Fatalf("requests after drain = %d, want 0", len(requests))
+		}
+	})
+
+	t.Run("rejects sessions that are not idle and scheduled", func(t *testing.T) {
+		tests := []struct {
+			name string
+			meta *Metadata
+			kind error
+		}{
+			{name: "unscheduled", meta: &Metadata{ID: "a", Status: StatusRunning, ResidentPID: 1}, kind: ErrNotResident},
+			{name: "running", meta: &Metadata{ID: "b", Schedule: "@hourly", Status: StatusRunning, ResidentPID: 1}, kind: ErrInvalidStatus},
+			{name: "blocked", meta: &Metadata{ID: "c", Schedule: "@hourly", Status: StatusBlocked, ResidentPID: 1}, kind: ErrInvalidStatus},
+			{name: "no resident process", meta: &Metadata{ID: "d", Schedule: "@hourly", Status: StatusScheduled}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := RequestTrigger(tt.meta, "")
+				if err == nil {
+					t.Fatal("RequestTrigger() error = nil, want error")
+				}
+				if tt.kind != nil && !errors.Is(err, tt.kind) {
+					t.Fatalf("RequestTrigger() error = %v, want %v", err, tt.kind)
+				}
+			})
+		}
+	})
+}
+
+func TestRequestPauseAndUnpause(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tempDir := t.TempDir()
+	meta, err := New(tempDir, tempDir, "daily-check", "orchestrator", "skill-loop", []string{"echo", "hello"}, 10*time.Second, 2)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	meta.Schedule = "0 9 * * *"
+	meta.Status = StatusScheduled
+	meta.ResidentPID = os.Getpid()
+
+	signals := make(chan os.Signal, 2)
+	NotifyControl(signals)
+	defer signal.Stop(signals)
+
+	if err := RequestUnpause(meta); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("RequestUnpause() error = %v for scheduled session, want ErrInvalidStatus", err)
+	}
+	if err := RequestPause(meta); err != nil {
+		t.Fatalf("RequestPause() error: %v", err)
+	}
+
+	meta.Status = StatusPaused
+	if err := RequestPause(meta); err == nil {
+		t.Fatal("RequestPause() error = nil for paused session, want error")
+	}
+	if err := RequestTrigger(meta, ""); err == nil {
+		t.Fatal("RequestTrigger() error = nil for paused session, want error")
+	}
+	if err := RequestUnpause(meta); err != nil {
+		t.Fatalf("RequestUnpause() error: %v", err)
+	}
+
+	requests, err := DrainControl(meta)
+	if err != nil {
+		t.Fatalf("DrainControl() error: %v", err)
+	}
+	if len(requests) != 2 || requests[0].Action != ControlPause || requests[1].Action != ControlUnpause {
+		t.Fatalf("requests = %+v, want [pause unpause]", requests)
+	}
+}
+
+func TestBuildResumePrompt(t *testing.T) {
+	meta := &Metadata{ResumePrompt: "Previous skill stdout:\n<NEEDS_HUMAN>\n"}
+
+	if got, want := BuildResumePrompt(meta, " approve "), "Previous skill stdout:\n<NEEDS_HUMAN>\n\nHuman input:\napprove"; got != want {
+		t.Fatalf("BuildResumePrompt() = %q, want %q", got, want)
+	}
+	if got := BuildResumePrompt(&Metadata{}, "approve"); got != "approve" {
+		t.Fatalf("BuildResumePrompt() = %q, want approve", got)
+	}
+}
+
+func TestRequestResumeScheduledSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tempDir := t.TempDir()
+	meta, err := New(tempDir, tempDir, "daily-check", "orchestrator", "skill-loop", []string{"echo", "hello"}, 10*time.Second, 2)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	meta.Schedule = "0 9 * * *"
+	meta.Status = StatusBlocked
+	meta.ResumeSkill = "apply-feedback"
+	meta.ResidentPID = os.Getpid()
+
+	if _, err := BuildResumeCommand(meta, "ship it"); err == nil {
+		t.Fatal("BuildResumeCommand() error = nil for scheduled session, want error")
+	}
+
+	signals := make(chan os.Signal, 1)
+	NotifyControl(signals)
+	defer signal.Stop(signals)
+
+	if err := RequestResume(meta, "ship it"); err != nil {
+		t.Fatalf("RequestResume() error: %v", err)
+	}
+	requests, err := DrainControl(meta)
+	if err != nil {
+		t.Fatalf("DrainControl() error: %v", err)
+	}
+	if len(requests) != 1 || requests[0].Action != ControlResume || requests[0].Prompt != "ship it" {
+		t.Fatalf("requests = %+v, want one resume request with human input", requests)
+	}
+
+	meta.Status = StatusScheduled
+	if err := RequestResume(meta, "ship it"); err == nil {
+		t.Fatal("RequestResume() error = nil for scheduled status, want error")
+	}
+}
+
+func TestRecordIterationUsesLogSizes(t *testing.T) {
+	dir := t.TempDir()
+	stdoutPath := filepath.Join(dir, "stdout.log")
+	stderrPath := filepath.Join(dir, "stderr.log")
+	if err := os.WriteFile(stdoutPath, []byte("hello\n"), 0o600); err != nil {
+		t.Fatalf("write stdout: %v", err)
+	}
+
+	if err := RecordIteration(stdoutPath, stderrPath, 1, 5, "impl"); err != nil {
+		t.Fatalf("RecordIteration() error: %v", err)
+	}
+	if err := RecordIteration(stdoutPath, stderrPath, 2, 5, "review"); err != nil {
+		t.Fatalf("RecordIteration() error: %v", err)
+	}
+
+	marks, err := LoadIterations(stdoutPath)
+	if err != nil {
+		t.Fatalf("LoadIterations() error: %v", err)
+	}
+	if len(marks) != 2 {
+		t.Fatalf("marks = %+v, want 2", marks)
+	}
+	if marks[0].Skill != "impl" || marks[0].StdoutOffset != 6 || marks[0].StderrOffset != 0 {
+		t.Fatalf("marks[0] = %+v, want impl at stdout offset 6", marks[0])
+	}
+	if marks[1].Iteration != 2 || marks[1].MaxIterations != 5 {
+		t.Fatalf("marks[1] = %+v, want iteration 2/5", marks[1])
+	}
+
+	if marks, err := LoadIterations(filepath.Join(t.TempDir(), "stdout.log")); err != nil || marks != nil {
+		t.Fatalf("LoadIterations() = %v, %v for missing log, want nil, nil", marks, err)
+	}
+}
+
+func TestTailOffset(t *testing.T) {
+	var content strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	log := content.String()
+
+	tests := []struct {
+		name    string
+		content string
+		from    int64
+		lines   int
+		want    int64
+	}{
+		{name: "across read chunks", content: log, lines: 4000, want: int64(strings.Index(log, "line 1000\n"))},
+		{name: "last line", content: log, lines: 1, want: int64(strings.Index(log, "line 4999\n"))},
+		{name: "unterminated last line", content: "a\nb\nc", lines: 2, want: 2},
+		{name: "fewer lines than asked", content: "a\nb\n", lines: 5, want: 0},
+		{name: "stops at from", content: "a\nb\nc\n", from: 2, lines: 5, want: 2},
+		{name: "zero lines keep from", content: "a\nb\n", from: 2, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TailOffset(strings.NewReader(tt.content), tt.from, tt.lines)
+			if err != nil {
+				t.Fatalf("TailOffset() error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("TailOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
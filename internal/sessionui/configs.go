@@ -0,0 +1,198 @@
+package sessionui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// configCacheTTL is how long discovered configs are reused, long enough for
+// the New run form and the start request that follows it to share one walk
+// of the repository.
+const configCacheTTL = 10 * time.Second
+
+// configCache holds the configs discovered last.
+type configCache struct {
+	mu           sync.Mutex
+	paths        []string
+	discoveredAt time.Time
+}
+
+type configDTO struct {
+	Path              string   `json:"path"`
+	RelativePath      string   `json:"relativePath"`
+	Name              string   `json:"name,omitempty"`
+	DefaultEntrypoint string   `json:"defaultEntrypoint,omitempty"`
+	Entrypoints       []string `json:"entrypoints,omitempty"`
+	MaxIterations     int      `json:"maxIterations,omitempty"`
+	Schedule          string   `json:"schedule,omitempty"`
+	Watch             []string `json:"watch,omitempty"`
+	Error             string   `json:"error,omitempty"`
+}
+
+type configsResponse struct {
+	RepoRoot string      `json:"repoRoot"`
+	Configs  []configDTO `json:"configs"`
+}
+
+type startRunRequest struct {
+	ConfigPath    string `json:"configPath"`
+	Entrypoint    string `json:"entrypoint"`
+	Prompt        string `json:"prompt"`
+	MaxIterations int    `json:"maxIterations"`
+	Backend       string `json:"backend"`
+}
+
+func (h *handler) handleListConfigs(w http.ResponseWriter, r *http.Request) {
+	paths, err := h.discoverConfigs(false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := configsResponse{RepoRoot: h.repoRoot, Configs: make([]configDTO, 0, len(paths))}
+	for _, cfgPath := range paths {
+		dto := configDTO{Path: cfgPath, RelativePath: h.relativePath(cfgPath)}
+		cfg, err := h.store.loadConfig(cfgPath, config.LoadOptions{})
+		if err != nil {
+			dto.Error = err.Error()
+			response.Configs = append(response.Configs, dto)
+			continue
+		}
+
+		dto.Name = cfg.EffectiveName(cfgPath)
+		dto.DefaultEntrypoint = cfg.DefaultEntrypoint
+		dto.MaxIterations = cfg.MaxIterations
+		dto.Schedule = cfg.Schedule
+		dto.Watch = cfg.WatchPatterns()
+		for name := range cfg.Skills {
+			dto.Entrypoints = append(dto.Entrypoints, name)
+		}
+		sort.Strings(dto.Entrypoints)
+		response.Configs = append(response.Configs, dto)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	var req startRunRequest
+	if r.Body != nil {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeErrorMessage(w, http.StatusBadRequest, "invalid run request")
+			return
+		}
+	}
+	if req.MaxIterations < 0 {
+		writeErrorMessage(w, http.StatusBadRequest, "maxIterations must be >= 0")
+		return
+	}
+
+	backend, err := session.ResolveBackend(req.Backend)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Only configs discovered in the repository can be started, so the API
+	// cannot be used to run arbitrary files on the machine.
+	cfgPath, err := h.resolveDiscoveredConfig(req.ConfigPath)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cfg, err := h.store.loadConfig(cfgPath, config.LoadOptions{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entrypoint := strings.TrimSpace(req.Entrypoint)
+	if entrypoint != "" {
+		if err := cfg.ValidateEntrypoint(entrypoint); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	meta, err := h.store.startRun(cfg, cfgPath, launch.Options{
+		MaxIterations: req.MaxIterations,
+		Prompt:        strings.TrimSpace(req.Prompt),
+		Entrypoint:    entrypoint,
+		Backend:       backend,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, NewSession(meta))
+}
+
+func (h *handler) resolveDiscoveredConfig(requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return "", fmt.Errorf("configPath is required")
+	}
+	if !filepath.IsAbs(requested) {
+		requested = filepath.Join(h.repoRoot, requested)
+	}
+	requested = filepath.Clean(requested)
+
+	// A config created since the last discovery is not cached yet, so a miss
+	// discovers the configs again before rejecting the request.
+	for _, fresh := range []bool{false, true} {
+		paths, err := h.discoverConfigs(fresh)
+		if err != nil {
+			return "", err
+		}
+		for _, cfgPath := range paths {
+			if filepath.Clean(cfgPath) == requested {
+				return cfgPath, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("config %s is not a discovered skill-loop config", requested)
+}
+
+// discoverConfigs returns the configs of the repository. Discovery walks the
+// whole repository, so its result is reused for configCacheTTL unless fresh
+// is set.
+func (h *handler) discoverConfigs(fresh bool) ([]string, error) {
+	h.configs.mu.Lock()
+	defer h.configs.mu.Unlock()
+	if !fresh && h.configs.paths != nil && time.Since(h.configs.discoveredAt) < configCacheTTL {
+		return h.configs.paths, nil
+	}
+	paths, err := h.store.discoverConfigs(h.repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if paths == nil {
+		paths = []string{}
+	}
+	h.configs.paths, h.configs.discoveredAt = paths, time.Now()
+	return paths, nil
+}
+
+func (h *handler) relativePath(path string) string {
+	rel, err := filepath.Rel(h.repoRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
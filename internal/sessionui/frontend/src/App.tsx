@@ -1,8 +1,32 @@
 import { useEffect, useMemo, useState } from "react";
+import { NewRunForm } from "./components/NewRunForm";
 import { SessionContent } from "./components/SessionContent";
 import { Sidebar } from "./components/Sidebar";
-import type { LogPayload, Session, SessionStatus, SessionsPayload } from "./types";
-import { getErrorMessage, getJSON, groupSessions, sendJSON } from "./utils";
+import type {
+  ConfigOption,
+  ConfigsPayload,
+  LogEvent,
+  LogPayload,
+  Run,
+  RunsPayload,
+  Session,
+  SessionFilters,
+  SessionsPayload,
+  StartRunRequest,
+  WorkflowGraph,
+} from "./types";
+import {
+  DEFAULT_FILTERS,
+  appendLog,
+  baseName,
+  getErrorMessage,
+  getJSON,
+  groupSessions,
+  isResident,
+  sendJSON,
+  sessionURL,
+  sessionsURL,
+} from "./utils";
 
 const POLL_MS = 4000;
 
@@ -10,26 +34,36 @@ export default function App() {
   const [repoRoot, setRepoRoot] = useState("");
   const [sessions, setSessions] = useState<Session[]>([]);
   const [selectedId, setSelectedId] = useState("");
-  const [statusFilters, setStatusFilters] = useState<Array<SessionStatus | "all">>(["all"]);
+  const [filters, setFilters] = useState<SessionFilters>(DEFAULT_FILTERS);
   const [query, setQuery] = useState("");
   const [activeStream, setActiveStream] = useState<"stdout" | "stderr">("stdout");
   const [log, setLog] = useState<LogPayload | null>(null);
+  const [logLive, setLogLive] = useState(false);
+  const [graph, setGraph] = useState<WorkflowGraph | null>(null);
+  const [runs, setRuns] = useState<Run[]>([]);
+  const [selectedRunId, setSelectedRunId] = useState("");
   const [loading, setLoading] = useState(true);
   const [mutating, setMutating] = useState(false);
   const [error, setError] = useState("");
   const [flash, setFlash] = useState("");
   const [resumeDraft, setResumeDraft] = useState("");
+  const [configs, setConfigs] = useState<ConfigOption[]>([]);
+  const [isNewRunOpen, setIsNewRunOpen] = useState(false);
+
+  const listURL = useMemo(() => sessionsURL(filters), [filters]);
 
   useEffect(() => {
     let cancelled = false;
 
     const refresh = async () => {
       try {
-        const payload = await getJSON<SessionsPayload>("/api/sessions");
+        const payload = await getJSON<SessionsPayload>(listURL);
         if (cancelled) {
           return;
         }
-        setRepoRoot(payload.repoRoot);
+        if (!payload.allRepos) {
+          setRepoRoot(payload.repoRoot);
+        }
         setSessions(payload.sessions);
         setSelectedId((current) => {
           if (current && payload.sessions.some((session) => session.id === current)) {
@@ -55,7 +89,7 @@ export default function App() {
       cancelled = true;
       window.clearInterval(timer);
     };
-  }, []);
+  }, [listURL]);
 
   const selectedSession = useMemo(
     () => sessions.find((session) => session.id === selectedId) ?? null,
@@ -64,22 +98,21 @@ export default function App() {
 
   useEffect(() => {
     setResumeDraft("");
+    setSelectedRunId("");
   }, [selectedId]);
 
   useEffect(() => {
-    if (!selectedSession) {
-      setLog(null);
+    if (!selectedSession || !isResident(selectedSession)) {
+      setRuns([]);
       return;
     }
 
     let cancelled = false;
-    const refreshLog = async () => {
+    const refreshRuns = async () => {
       try {
-        const activePayload = await getJSON<LogPayload>(
-          `/api/sessions/${selectedSession.id}/logs/${activeStream}`,
-        );
+        const payload = await getJSON<RunsPayload>(sessionURL(selectedSession.id, "/runs", filters.allRepos));
         if (!cancelled) {
-          setLog(activePayload);
+          setRuns(payload.runs);
         }
       } catch (err) {
         if (!cancelled) {
@@ -88,28 +121,95 @@ export default function App() {
       }
     };
 
-    refreshLog();
-    const timer = window.setInterval(refreshLog, POLL_MS);
+    refreshRuns();
+    const timer = window.setInterval(refreshRuns, POLL_MS);
     return () => {
       cancelled = true;
       window.clearInterval(timer);
     };
-  }, [activeStream, selectedSession]);
+  }, [filters.allRepos, selectedSession]);
+
+  useEffect(() => {
+    if (!selectedId) {
+      setLog(null);
+      setLogLive(false);
+      return;
+    }
+
+    const source = new EventSource(
+      sessionURL(selectedId, `/logs/${activeStream}/events`, filters.allRepos, selectedRunId),
+    );
+    setLog(null);
+
+    // EventSource reconnects on its own and sends the last event ID, which is
+    // the byte offset of the last chunk, so the server resumes where it left off.
+    source.addEventListener("open", () => setLogLive(true));
+    source.addEventListener("error", () => setLogLive(false));
+    source.addEventListener("log", (event) => {
+      const payload = JSON.parse((event as MessageEvent<string>).data) as LogEvent;
+      setLog((current) => ({
+        sessionId: selectedId,
+        runId: selectedRunId || undefined,
+        stream: activeStream,
+        path: payload.path,
+        content: appendLog(payload.reset || !current ? "" : current.content, payload.content),
+      }));
+    });
+    source.addEventListener("status", (event) => {
+      const payload = JSON.parse((event as MessageEvent<string>).data) as Session;
+      setSessions((current) =>
+        current.map((session) => (session.id === payload.id ? payload : session)),
+      );
+    });
+
+    return () => {
+      source.close();
+      setLogLive(false);
+    };
+  }, [activeStream, filters.allRepos, selectedId, selectedRunId]);
+
+  // The highlighted skill and routes only change when the session moves on to
+  // another iteration or changes status, so refetch the graph on those.
+  const graphKey = selectedSession
+    ? `${selectedSession.id}:${selectedSession.status}:${selectedSession.currentIteration ?? 0}:${selectedSession.currentSkill ?? ""}`
+    : "";
+  useEffect(() => {
+    if (!selectedId) {
+      setGraph(null);
+      return;
+    }
+
+    let cancelled = false;
+    getJSON<WorkflowGraph>(sessionURL(selectedId, "/graph", filters.allRepos))
+      .then((payload) => {
+        if (!cancelled) {
+          setGraph(payload);
+        }
+      })
+      .catch(() => {
+        // Sessions without a readable config simply show no graph.
+        if (!cancelled) {
+          setGraph(null);
+        }
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [filters.allRepos, graphKey, selectedId]);
 
   const groupedSessions = useMemo(
-    () => groupSessions(sessions, statusFilters, query),
-    [query, sessions, statusFilters],
+    () => groupSessions(sessions, query, filters.allRepos),
+    [filters.allRepos, query, sessions],
   );
 
-  const repoName = useMemo(() => {
-    const parts = repoRoot.split("/").filter(Boolean);
-    return parts.at(-1) ?? "skill-loop";
-  }, [repoRoot]);
+  const repoName = useMemo(() => baseName(repoRoot) || "skill-loop", [repoRoot]);
 
   async function refreshAll() {
     try {
-      const payload = await getJSON<SessionsPayload>("/api/sessions");
-      setRepoRoot(payload.repoRoot);
+      const payload = await getJSON<SessionsPayload>(listURL);
+      if (!payload.allRepos) {
+        setRepoRoot(payload.repoRoot);
+      }
       setSessions(payload.sessions);
       setSelectedId((current) => {
         if (current && payload.sessions.some((session) => session.id === current)) {
@@ -128,7 +228,7 @@ export default function App() {
       return;
     }
     try {
-      const payload = await getJSON<Session>(`/api/sessions/${selectedSession.id}`);
+      const payload = await getJSON<Session>(sessionURL(selectedSession.id, "", filters.allRepos));
       setSessions((current) =>
         current.map((session) => (session.id === payload.id ? payload : session)),
       );
@@ -182,6 +282,80 @@ export default function App() {
     }
   }
 
+  async function triggerSelected() {
+    if (!selectedSession) {
+      return;
+    }
+    setMutating(true);
+    try {
+      const payload = await sendJSON<Session>(`/api/sessions/${selectedSession.id}/trigger`, {
+        method: "POST",
+        body: JSON.stringify({}),
+      });
+      setSessions((current) =>
+        current.map((session) => (session.id === payload.id ? payload : session)),
+      );
+      setFlash(`Triggered ${payload.id}`);
+      setError("");
+    } catch (err) {
+      setError(getErrorMessage(err));
+    } finally {
+      setMutating(false);
+    }
+  }
+
+  async function setSelectedPaused(paused: boolean) {
+    if (!selectedSession) {
+      return;
+    }
+    setMutating(true);
+    try {
+      const action = paused ? "pause" : "unpause";
+      const payload = await sendJSON<Session>(`/api/sessions/${selectedSession.id}/${action}`, {
+        method: "POST",
+      });
+      setSessions((current) =>
+        current.map((session) => (session.id === payload.id ? payload : session)),
+      );
+      setFlash(paused ? `Pausing ${payload.id}` : `Unpaused ${payload.id}`);
+      setError("");
+    } catch (err) {
+      setError(getErrorMessage(err));
+    } finally {
+      setMutating(false);
+    }
+  }
+
+  async function openNewRun() {
+    setIsNewRunOpen(true);
+    try {
+      const payload = await getJSON<ConfigsPayload>("/api/configs");
+      setConfigs(payload.configs);
+      setError("");
+    } catch (err) {
+      setError(getErrorMessage(err));
+    }
+  }
+
+  async function startRun(request: StartRunRequest) {
+    setMutating(true);
+    try {
+      const payload = await sendJSON<Session>("/api/runs", {
+        method: "POST",
+        body: JSON.stringify(request),
+      });
+      setSessions((current) => [payload, ...current.filter((session) => session.id !== payload.id)]);
+      setSelectedId(payload.id);
+      setIsNewRunOpen(false);
+      setFlash(`Started ${payload.id}`);
+      setError("");
+    } catch (err) {
+      setError(getErrorMessage(err));
+    } finally {
+      setMutating(false);
+    }
+  }
+
   async function prune(all: boolean) {
     setMutating(true);
     try {
@@ -219,12 +393,12 @@ export default function App() {
         repoName={repoName}
         repoRoot={repoRoot}
         query={query}
-        statusFilters={statusFilters}
+        filters={filters}
         loading={loading}
         groupedSessions={groupedSessions}
         selectedId={selectedId}
         onQueryChange={setQuery}
-        onStatusFilterChange={setStatusFilters}
+        onFiltersChange={setFilters}
         onRefresh={() => void refreshAll()}
         onSelect={setSelectedId}
       />
@@ -232,17 +406,35 @@ export default function App() {
       <SessionContent
         selectedSession={selectedSession}
         log={log}
+        logLive={logLive}
+        graph={graph}
+        runs={runs}
+        selectedRunId={selectedRunId}
         activeStream={activeStream}
         mutating={mutating}
+        readOnly={filters.allRepos && selectedSession?.repoRoot !== repoRoot}
         resumeDraft={resumeDraft}
+        onNewRun={() => void openNewRun()}
         onPruneInactive={() => void prune(true)}
         onRefreshSelected={() => void refreshSelected()}
         onStopSelected={() => void stopSelected()}
+        onTriggerSelected={() => void triggerSelected()}
+        onPauseSelected={(paused) => void setSelectedPaused(paused)}
         onResumeDraftChange={setResumeDraft}
         onResumeSelected={(prompt) => void resumeSelected(prompt)}
         onActiveStreamChange={setActiveStream}
+        onSelectRun={setSelectedRunId}
       />
 
+      {isNewRunOpen ? (
+        <NewRunForm
+          configs={configs}
+          mutating={mutating}
+          onSubmit={(request) => void startRun(request)}
+          onCancel={() => setIsNewRunOpen(false)}
+        />
+      ) : null}
+
       {(error || flash) && (
         <div className={error ? "toast error" : "toast success"}>{error || flash}</div>
       )}
@@ -0,0 +1,129 @@
+import { FormEvent, useEffect, useState } from "react";
+import type { ConfigOption, StartRunRequest } from "../types";
+
+type NewRunFormProps = {
+  configs: ConfigOption[];
+  mutating: boolean;
+  onSubmit: (request: StartRunRequest) => void;
+  onCancel: () => void;
+};
+
+export function NewRunForm({ configs, mutating, onSubmit, onCancel }: NewRunFormProps) {
+  const [configPath, setConfigPath] = useState("");
+  const [entrypoint, setEntrypoint] = useState("");
+  const [prompt, setPrompt] = useState("");
+  const [maxIterations, setMaxIterations] = useState("");
+
+  const selectedConfig = configs.find((config) => config.path === configPath) ?? null;
+
+  useEffect(() => {
+    if (configPath === "" || !configs.some((config) => config.path === configPath)) {
+      setConfigPath(configs.find((config) => !config.error)?.path ?? "");
+    }
+  }, [configs, configPath]);
+
+  useEffect(() => {
+    setEntrypoint("");
+  }, [configPath]);
+
+  function handleSubmit(event: FormEvent<HTMLFormElement>) {
+    event.preventDefault();
+    if (!selectedConfig) {
+      return;
+    }
+    onSubmit({
+      configPath: selectedConfig.path,
+      entrypoint,
+      prompt,
+      maxIterations: maxIterations === "" ? 0 : Number(maxIterations),
+    });
+  }
+
+  return (
+    <div className="modal-backdrop" role="presentation" onClick={onCancel}>
+      <form
+        className="new-run-form"
+        role="dialog"
+        aria-label="Start a run"
+        onSubmit={handleSubmit}
+        onClick={(event) => event.stopPropagation()}
+      >
+        <div className="resume-header">
+          <div>
+            <p className="eyebrow">New run</p>
+            <p className="resume-help">
+              Starts a detached run, just like <code>skill-loop run</code>.
+            </p>
+          </div>
+          <button type="button" className="secondary-button compact" onClick={onCancel}>
+            Cancel
+          </button>
+        </div>
+
+        {configs.length === 0 ? (
+          <div className="empty-state">No skill-loop.yml configs found in the repository.</div>
+        ) : (
+          <>
+            <label className="form-field">
+              <span className="section-label">Config</span>
+              <select value={configPath} onChange={(event) => setConfigPath(event.target.value)}>
+                {configs.map((config) => (
+                  <option key={config.path} value={config.path} disabled={Boolean(config.error)}>
+                    {config.relativePath}
+                    {config.error ? " (invalid)" : ""}
+                  </option>
+                ))}
+              </select>
+            </label>
+            {selectedConfig?.schedule || selectedConfig?.watch?.length ? (
+              <p className="resume-help">
+                This workflow is {selectedConfig.schedule ? "scheduled" : "watch-triggered"}; starting it
+                launches a resident session.
+              </p>
+            ) : null}
+
+            <label className="form-field">
+              <span className="section-label">Entrypoint</span>
+              <select value={entrypoint} onChange={(event) => setEntrypoint(event.target.value)}>
+                <option value="">
+                  Default{selectedConfig?.defaultEntrypoint ? ` (${selectedConfig.defaultEntrypoint})` : ""}
+                </option>
+                {(selectedConfig?.entrypoints ?? []).map((name) => (
+                  <option key={name} value={name}>
+                    {name}
+                  </option>
+                ))}
+              </select>
+            </label>
+
+            <label className="form-field">
+              <span className="section-label">Max iterations</span>
+              <input
+                type="number"
+                min={1}
+                value={maxIterations}
+                onChange={(event) => setMaxIterations(event.target.value)}
+                placeholder={String(selectedConfig?.maxIterations || 100)}
+              />
+            </label>
+
+            <label className="form-field">
+              <span className="section-label">Prompt</span>
+              <textarea
+                value={prompt}
+                onChange={(event) => setPrompt(event.target.value)}
+                placeholder="Optional initial prompt passed to the first skill."
+              />
+            </label>
+
+            <div className="action-row form-actions">
+              <button type="submit" className="secondary-button" disabled={mutating || !selectedConfig}>
+                Start run
+              </button>
+            </div>
+          </>
+        )}
+      </form>
+    </div>
+  );
+}
@@ -0,0 +1,50 @@
+import type { Run } from "../types";
+import { formatCompactDate, formatDuration, statusToneClass } from "../utils";
+
+type RunHistoryProps = {
+  runs: Run[];
+  selectedRunId: string;
+  onSelectRun: (id: string) => void;
+};
+
+export function RunHistory({ runs, selectedRunId, onSelectRun }: RunHistoryProps) {
+  return (
+    <div className="summary-section runs-section">
+      <div className="runs-header">
+        <span className="section-label">Run history</span>
+        <button
+          type="button"
+          className={selectedRunId === "" ? "tab-button active" : "tab-button"}
+          onClick={() => onSelectRun("")}
+        >
+          All output
+        </button>
+      </div>
+      {runs.length === 0 ? (
+        <div className="empty-state">No runs recorded yet.</div>
+      ) : (
+        <div className="runs-list">
+          {runs.map((run) => (
+            <button
+              key={run.id}
+              type="button"
+              className={run.id === selectedRunId ? "run-item active" : "run-item"}
+              onClick={() => onSelectRun(run.id)}
+              title={run.lastError || run.blockReason || run.lastSkillOutput || ""}
+            >
+              <span className={`tree-item-dot ${statusToneClass(run.status)}`} />
+              <strong>{formatCompactDate(run.startedAt)}</strong>
+              <span>{run.trigger}</span>
+              <span>{run.status}</span>
+              <span>
+                {run.currentIteration ?? 0}
+                {run.maxIterations ? `/${run.maxIterations}` : ""}
+              </span>
+              <span>{formatDuration(run.durationSeconds)}</span>
+            </button>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
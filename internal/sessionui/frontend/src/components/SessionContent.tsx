@@ -1,33 +1,75 @@
-import { FormEvent } from "react";
-import type { LogPayload, Session } from "../types";
+import { FormEvent, useEffect, useRef } from "react";
+import { RunHistory } from "./RunHistory";
+import { WorkflowGraph } from "./WorkflowGraph";
+import type { LogPayload, Run, Session, WorkflowGraph as Graph } from "../types";
+import { isResident } from "../utils";
 
 type SessionContentProps = {
   selectedSession: Session | null;
   log: LogPayload | null;
+  logLive: boolean;
+  graph: Graph | null;
+  runs: Run[];
+  selectedRunId: string;
   activeStream: "stdout" | "stderr";
   mutating: boolean;
+  // readOnly marks a session of another repository, which the dashboard only
+  // shows: the server refuses actions on it.
+  readOnly: boolean;
   resumeDraft: string;
+  onNewRun: () => void;
   onPruneInactive: () => void;
   onRefreshSelected: () => void;
   onStopSelected: () => void;
+  onTriggerSelected: () => void;
+  onPauseSelected: (paused: boolean) => void;
   onResumeDraftChange: (value: string) => void;
   onResumeSelected: (prompt: string) => void;
   onActiveStreamChange: (stream: "stdout" | "stderr") => void;
+  onSelectRun: (id: string) => void;
 };
 
 export function SessionContent({
   selectedSession,
   log,
+  logLive,
+  graph,
+  runs,
+  selectedRunId,
   activeStream,
   mutating,
+  readOnly,
   resumeDraft,
+  onNewRun,
   onPruneInactive,
   onRefreshSelected,
   onStopSelected,
+  onTriggerSelected,
+  onPauseSelected,
   onResumeDraftChange,
   onResumeSelected,
   onActiveStreamChange,
+  onSelectRun,
 }: SessionContentProps) {
+  const logRef = useRef<HTMLPreElement>(null);
+  const followLog = useRef(true);
+
+  // Keep the newest output in view while streaming, unless the user has
+  // scrolled up to read something.
+  useEffect(() => {
+    const element = logRef.current;
+    if (element && followLog.current) {
+      element.scrollTop = element.scrollHeight;
+    }
+  }, [log?.content]);
+
+  function handleLogScroll() {
+    const element = logRef.current;
+    if (element) {
+      followLog.current = element.scrollHeight - element.scrollTop - element.clientHeight < 24;
+    }
+  }
+
   function handleResumeSubmit(event: FormEvent<HTMLFormElement>) {
     event.preventDefault();
     onResumeSelected(resumeDraft);
@@ -41,6 +83,9 @@ export function SessionContent({
           <h2>{selectedSession ? selectedSession.workflowName : "No selection"}</h2>
         </div>
         <div className="action-row">
+          <button type="button" className="secondary-button" onClick={onNewRun} disabled={mutating}>
+            New run
+          </button>
           <button type="button" className="secondary-button" onClick={onPruneInactive} disabled={mutating}>
             Prune inactive
           </button>
@@ -49,13 +94,47 @@ export function SessionContent({
               <button type="button" className="secondary-button" onClick={onRefreshSelected}>
                 Refresh
               </button>
+              {isResident(selectedSession) ? (
+                <button
+                  type="button"
+                  className="secondary-button"
+                  onClick={onTriggerSelected}
+                  disabled={mutating || readOnly || selectedSession.status !== "scheduled"}
+                >
+                  Run now
+                </button>
+              ) : null}
+              {isResident(selectedSession) ? (
+                selectedSession.status === "paused" ? (
+                  <button
+                    type="button"
+                    className="secondary-button"
+                    onClick={() => onPauseSelected(false)}
+                    disabled={mutating || readOnly}
+                  >
+                    Unpause
+                  </button>
+                ) : (
+                  <button
+                    type="button"
+                    className="secondary-button"
+                    onClick={() => onPauseSelected(true)}
+                    disabled={
+                      mutating || readOnly || !["scheduled", "running"].includes(selectedSession.status)
+                    }
+                  >
+                    Pause
+                  </button>
+                )
+              ) : null}
               <button
                 type="button"
                 className="secondary-button"
                 onClick={onStopSelected}
                 disabled={
                   mutating ||
-                  !["running", "scheduled", "idle", "pending"].includes(selectedSession.status)
+                  readOnly ||
+                  !["running", "scheduled", "paused", "idle", "pending"].includes(selectedSession.status)
                 }
               >
                 Stop
@@ -74,13 +153,17 @@ export function SessionContent({
                 {selectedSession.previousSummary || "(empty)"}
               </pre>
             </div>
+            <WorkflowGraph graph={graph} />
+            {isResident(selectedSession) ? (
+              <RunHistory runs={runs} selectedRunId={selectedRunId} onSelectRun={onSelectRun} />
+            ) : null}
             {selectedSession.status === "blocked" ? (
               <form className="resume-form" onSubmit={handleResumeSubmit}>
                 <div className="resume-header">
                   <div>
                     <span className="section-label">Resume prompt</span>
                   </div>
-                  <button type="submit" className="secondary-button" disabled={mutating}>
+                  <button type="submit" className="secondary-button" disabled={mutating || readOnly}>
                     Resume
                   </button>
                 </div>
@@ -88,7 +171,7 @@ export function SessionContent({
                   value={resumeDraft}
                   onChange={(event) => onResumeDraftChange(event.target.value)}
                   placeholder="Add human guidance, approval, or constraints here."
-                  disabled={mutating}
+                  disabled={mutating || readOnly}
                 />
               </form>
             ) : null}
@@ -97,7 +180,9 @@ export function SessionContent({
           <section className="log-card">
             <div className="log-header">
               <div>
-                <p className="eyebrow">Log</p>
+                <p className="eyebrow">
+                  Log {logLive ? <span className="log-live">live</span> : null}
+                </p>
                 <div className="log-path" title={log?.path || ""}>
                   {log?.path || "No log file"}
                 </div>
@@ -115,7 +200,9 @@ export function SessionContent({
                 ))}
               </div>
             </div>
-            <pre className="log-output">{log?.content || "(empty)"}</pre>
+            <pre className="log-output" ref={logRef} onScroll={handleLogScroll}>
+              {log?.content || "(empty)"}
+            </pre>
           </section>
         </div>
       ) : (
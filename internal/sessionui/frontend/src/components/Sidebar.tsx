@@ -1,18 +1,18 @@
 import { useEffect, useMemo, useRef, useState } from "react";
 import { FolderIcon, SearchIcon } from "./Icons";
 import { STATUS_OPTIONS, statusToneClass } from "../utils";
-import type { SessionGroup, SessionStatus } from "../types";
+import type { SessionFilters, SessionGroup, SessionStatus } from "../types";
 
 type SidebarProps = {
   repoName: string;
   repoRoot: string;
   query: string;
-  statusFilters: Array<SessionStatus | "all">;
+  filters: SessionFilters;
   loading: boolean;
   groupedSessions: SessionGroup[];
   selectedId: string;
   onQueryChange: (value: string) => void;
-  onStatusFilterChange: (value: Array<SessionStatus | "all">) => void;
+  onFiltersChange: (value: SessionFilters) => void;
   onRefresh: () => void;
   onSelect: (id: string) => void;
 };
@@ -21,16 +21,17 @@ export function Sidebar({
   repoName,
   repoRoot,
   query,
-  statusFilters,
+  filters,
   loading,
   groupedSessions,
   selectedId,
   onQueryChange,
-  onStatusFilterChange,
+  onFiltersChange,
   onRefresh,
   onSelect,
 }: SidebarProps) {
   const [isFilterOpen, setIsFilterOpen] = useState(false);
+  const statusFilters = filters.statuses;
   const filterRef = useRef<HTMLDivElement | null>(null);
 
   const filterLabel = useMemo(() => {
@@ -61,9 +62,13 @@ export function Sidebar({
     };
   }, []);
 
+  function updateFilters(update: Partial<SessionFilters>) {
+    onFiltersChange({ ...filters, ...update });
+  }
+
   function toggleStatus(nextStatus: SessionStatus | "all") {
     if (nextStatus === "all") {
-      onStatusFilterChange(["all"]);
+      updateFilters({ statuses: ["all"] });
       setIsFilterOpen(false);
       return;
     }
@@ -74,7 +79,7 @@ export function Sidebar({
       ? current.filter((status) => status !== nextStatus)
       : [...current, nextStatus];
 
-    onStatusFilterChange(next.length === 0 ? ["all"] : next);
+    updateFilters({ statuses: next.length === 0 ? ["all"] : next });
     setIsFilterOpen(false);
   }
 
@@ -126,6 +131,53 @@ export function Sidebar({
         ) : null}
       </div>
 
+      <div className="filter-fields">
+        <label className="filter-field">
+          <span className="eyebrow">Workflow</span>
+          <input
+            value={filters.workflow}
+            onChange={(event) => updateFilters({ workflow: event.target.value })}
+            placeholder="Any workflow"
+          />
+        </label>
+        <label className="filter-field">
+          <span className="eyebrow">Config</span>
+          <input
+            value={filters.config}
+            onChange={(event) => updateFilters({ config: event.target.value })}
+            placeholder="skill-loop.yml"
+          />
+        </label>
+        <div className="filter-field-row">
+          <label className="filter-field">
+            <span className="eyebrow">From</span>
+            <input
+              type="date"
+              value={filters.since}
+              max={filters.until || undefined}
+              onChange={(event) => updateFilters({ since: event.target.value })}
+            />
+          </label>
+          <label className="filter-field">
+            <span className="eyebrow">To</span>
+            <input
+              type="date"
+              value={filters.until}
+              min={filters.since || undefined}
+              onChange={(event) => updateFilters({ until: event.target.value })}
+            />
+          </label>
+        </div>
+        <label className="filter-option">
+          <input
+            type="checkbox"
+            checked={filters.allRepos}
+            onChange={(event) => updateFilters({ allRepos: event.target.checked })}
+          />
+          <span>All repositories</span>
+        </label>
+      </div>
+
       <div className="tree-pane">
         {loading ? (
           <div className="empty-state">Loading sessions...</div>
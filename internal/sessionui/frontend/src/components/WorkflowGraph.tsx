@@ -0,0 +1,157 @@
+import type { GraphEdge, GraphNode, WorkflowGraph as Graph } from "../types";
+
+type WorkflowGraphProps = {
+  graph: Graph | null;
+};
+
+const NODE_WIDTH = 132;
+const NODE_HEIGHT = 34;
+const COLUMN_GAP = 28;
+const ROW_GAP = 52;
+const PADDING = 16;
+const BACK_EDGE_MARGIN = 96;
+const MAX_LABEL = 26;
+
+type Box = { x: number; y: number; node: GraphNode };
+
+export function WorkflowGraph({ graph }: WorkflowGraphProps) {
+  if (!graph) {
+    return null;
+  }
+
+  const layers = new Map<number, GraphNode[]>();
+  for (const node of graph.nodes) {
+    layers.set(node.layer, [...(layers.get(node.layer) ?? []), node]);
+  }
+  const widest = Math.max(1, ...Array.from(layers.values(), (nodes) => nodes.length));
+  const rowWidth = widest * NODE_WIDTH + (widest - 1) * COLUMN_GAP;
+  const layerCount = Math.max(1, ...Array.from(layers.keys(), (layer) => layer + 1));
+
+  const boxes = new Map<string, Box>();
+  for (const [layer, nodes] of layers) {
+    const offset = (rowWidth - (nodes.length * NODE_WIDTH + (nodes.length - 1) * COLUMN_GAP)) / 2;
+    for (const node of nodes) {
+      boxes.set(node.id, {
+        x: PADDING + offset + node.order * (NODE_WIDTH + COLUMN_GAP),
+        y: PADDING + layer * (NODE_HEIGHT + ROW_GAP),
+        node,
+      });
+    }
+  }
+
+  const width = PADDING * 2 + rowWidth + BACK_EDGE_MARGIN;
+  const height = PADDING * 2 + layerCount * NODE_HEIGHT + (layerCount - 1) * ROW_GAP;
+
+  return (
+    <div className="summary-section graph-section">
+      <span className="section-label">Workflow</span>
+      <div className="graph-scroll">
+        <svg
+          className="workflow-graph"
+          viewBox={`0 0 ${width} ${height}`}
+          width={width}
+          height={height}
+          role="img"
+          aria-label={`Skill graph of ${graph.name}`}
+        >
+          <defs>
+            <marker id="graph-arrow" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="7" markerHeight="7" orient="auto">
+              <path d="M0,0 L10,5 L0,10 z" className="graph-arrow" />
+            </marker>
+            <marker
+              id="graph-arrow-traversed"
+              viewBox="0 0 10 10"
+              refX="9"
+              refY="5"
+              markerWidth="7"
+              markerHeight="7"
+              orient="auto"
+            >
+              <path d="M0,0 L10,5 L0,10 z" className="graph-arrow traversed" />
+            </marker>
+          </defs>
+          {graph.edges.map((edge, index) => (
+            <GraphEdgePath key={`${edge.from}-${edge.route ?? ""}-${index}`} edge={edge} index={index} boxes={boxes} />
+          ))}
+          {Array.from(boxes.values(), (box) => (
+            <g
+              key={box.node.id}
+              className={[
+                "graph-node",
+                `kind-${box.node.kind}`,
+                box.node.current ? "current" : "",
+              ].join(" ")}
+            >
+              <title>{box.node.kind === "missing" ? `${box.node.label} (missing skill)` : box.node.label}</title>
+              <rect
+                x={box.x}
+                y={box.y}
+                width={NODE_WIDTH}
+                height={NODE_HEIGHT}
+                rx={box.node.kind === "skill" || box.node.kind === "missing" ? 8 : NODE_HEIGHT / 2}
+              />
+              <text x={box.x + NODE_WIDTH / 2} y={box.y + NODE_HEIGHT / 2}>
+                {truncate(box.node.label, 18)}
+              </text>
+            </g>
+          ))}
+        </svg>
+      </div>
+    </div>
+  );
+}
+
+type GraphEdgePathProps = {
+  edge: GraphEdge;
+  index: number;
+  boxes: Map<string, Box>;
+};
+
+function GraphEdgePath({ edge, index, boxes }: GraphEdgePathProps) {
+  const from = boxes.get(edge.from);
+  const to = boxes.get(edge.to);
+  if (!from || !to) {
+    return null;
+  }
+
+  let path: string;
+  let labelX: number;
+  let labelY: number;
+  if (to.node.layer > from.node.layer) {
+    const x1 = from.x + NODE_WIDTH / 2;
+    const y1 = from.y + NODE_HEIGHT;
+    const x2 = to.x + NODE_WIDTH / 2;
+    const y2 = to.y;
+    const bend = (y2 - y1) / 2;
+    path = `M${x1},${y1} C${x1},${y1 + bend} ${x2},${y2 - bend} ${x2},${y2}`;
+    labelX = (x1 + x2) / 2;
+    labelY = (y1 + y2) / 2;
+  } else {
+    // Routes back to the same or an earlier layer loop around the right side.
+    const x1 = from.x + NODE_WIDTH;
+    const y1 = from.y + NODE_HEIGHT / 2;
+    const x2 = to.x + NODE_WIDTH;
+    const y2 = to.y + NODE_HEIGHT / 2 + (from === to ? 8 : 0);
+    const reach = 36 + (index % 4) * 14;
+    path = `M${x1},${y1} C${x1 + reach},${y1} ${x2 + reach},${y2} ${x2},${y2}`;
+    labelX = Math.max(x1, x2) + reach * 0.75;
+    labelY = (y1 + y2) / 2;
+  }
+
+  const label = edge.label ?? "";
+  return (
+    <g className={["graph-edge", `kind-${edge.kind}`, edge.traversed ? "traversed" : ""].join(" ")}>
+      <title>{edge.route ? `${edge.route}: ${label}` : label}</title>
+      <path d={path} markerEnd={edge.traversed ? "url(#graph-arrow-traversed)" : "url(#graph-arrow)"} />
+      {label ? (
+        <text x={labelX} y={labelY}>
+          {truncate(label, MAX_LABEL)}
+        </text>
+      ) : null}
+    </g>
+  );
+}
+
+function truncate(value: string, max: number): string {
+  return value.length > max ? `${value.slice(0, max - 1)}…` : value;
+}
@@ -1,6 +1,7 @@
 export type SessionStatus =
   | "pending"
   | "scheduled"
+  | "paused"
   | "running"
   | "blocked"
   | "idle"
@@ -18,7 +19,9 @@ export type Session = {
   configPath?: string;
   configName: string;
   schedule?: string;
+  watch?: string[];
   command: string[];
+  backend: string;
   tmuxSession: string;
   scriptPath: string;
   sessionDir: string;
@@ -40,25 +43,121 @@ export type Session = {
   resumeSkill?: string;
   resumePrompt?: string;
   previousSummary?: string;
+  vars?: Record<string, string>;
   idleTimeoutSeconds: number;
   maxRestarts: number;
   restartCount: number;
   lastError?: string;
 };
 
+// SessionFilters mirror the query parameters of GET /api/sessions. since and
+// until hold dates as YYYY-MM-DD, both inclusive.
+export type SessionFilters = {
+  statuses: Array<SessionStatus | "all">;
+  workflow: string;
+  config: string;
+  since: string;
+  until: string;
+  allRepos: boolean;
+};
+
 export type SessionsPayload = {
   repoRoot: string;
+  allRepos: boolean;
   updatedAt: string;
+  total: number;
   sessions: Session[];
 };
 
+export type Run = {
+  id: string;
+  sessionId: string;
+  trigger: string;
+  status: SessionStatus;
+  entrypoint?: string;
+  prompt?: string;
+  stdoutPath: string;
+  stderrPath: string;
+  startedAt: string;
+  endedAt?: string;
+  durationSeconds: number;
+  currentIteration?: number;
+  maxIterations?: number;
+  currentSkill?: string;
+  lastSkillOutput?: string;
+  blockReason?: string;
+  lastError?: string;
+};
+
+export type RunsPayload = {
+  sessionId: string;
+  runs: Run[];
+};
+
 export type LogPayload = {
   sessionId: string;
+  runId?: string;
   stream: "stdout" | "stderr";
   path: string;
   content: string;
 };
 
+export type LogEvent = {
+  path: string;
+  offset: number;
+  content: string;
+  reset?: boolean;
+};
+
+export type GraphNode = {
+  id: string;
+  label: string;
+  kind: "start" | "skill" | "done" | "missing";
+  layer: number;
+  order: number;
+  current?: boolean;
+};
+
+export type GraphEdge = {
+  from: string;
+  to: string;
+  route?: string;
+  label?: string;
+  kind: "start" | "next" | "done" | "blocked";
+  traversed?: boolean;
+};
+
+export type WorkflowGraph = {
+  name: string;
+  entrypoint: string;
+  nodes: GraphNode[];
+  edges: GraphEdge[];
+};
+
+export type ConfigOption = {
+  path: string;
+  relativePath: string;
+  name?: string;
+  defaultEntrypoint?: string;
+  entrypoints?: string[];
+  maxIterations?: number;
+  schedule?: string;
+  watch?: string[];
+  error?: string;
+};
+
+export type ConfigsPayload = {
+  repoRoot: string;
+  configs: ConfigOption[];
+};
+
+export type StartRunRequest = {
+  configPath: string;
+  entrypoint: string;
+  prompt: string;
+  maxIterations: number;
+};
+
 export type ErrorPayload = {
   error: string;
 };
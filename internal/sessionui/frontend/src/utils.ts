@@ -1,27 +1,79 @@
-import type { ErrorPayload, Session, SessionGroup, SessionStatus } from "./types";
+import type { ErrorPayload, Session, SessionFilters, SessionGroup, SessionStatus } from "./types";
 
 export const STATUS_OPTIONS = [
   "all",
   "running",
   "blocked",
   "scheduled",
+  "paused",
   "failed",
   "done",
   "stopped",
   "idle",
 ] as const;
 
-export function groupSessions(
-  sessions: Session[],
-  statusFilters: Array<SessionStatus | "all">,
-  query: string,
-): SessionGroup[] {
+export const DEFAULT_FILTERS: SessionFilters = {
+  statuses: ["all"],
+  workflow: "",
+  config: "",
+  since: "",
+  until: "",
+  allRepos: false,
+};
+
+// sessionsURL builds the GET /api/sessions request for the filters, which the
+// server applies.
+export function sessionsURL(filters: SessionFilters): string {
+  const params = new URLSearchParams();
+  const statuses = filters.statuses.filter((status) => status !== "all");
+  if (statuses.length > 0) {
+    params.set("status", statuses.join(","));
+  }
+  if (filters.workflow.trim() !== "") {
+    params.set("workflow", filters.workflow.trim());
+  }
+  if (filters.config.trim() !== "") {
+    params.set("config", filters.config.trim());
+  }
+  if (filters.since !== "") {
+    params.set("since", filters.since);
+  }
+  if (filters.until !== "") {
+    // The server excludes the until bound, so end at the following day.
+    params.set("until", nextDate(filters.until));
+  }
+  if (filters.allRepos) {
+    params.set("allRepos", "true");
+  }
+  const query = params.toString();
+  return query === "" ? "/api/sessions" : `/api/sessions?${query}`;
+}
+
+// sessionURL builds a read-only request for a session. Sessions of other
+// repositories are only found with allRepos.
+export function sessionURL(id: string, path: string, allRepos: boolean, run = ""): string {
+  const params = new URLSearchParams();
+  if (run !== "") {
+    params.set("run", run);
+  }
+  if (allRepos) {
+    params.set("allRepos", "true");
+  }
+  const query = params.toString();
+  const url = `/api/sessions/${encodeURIComponent(id)}${path}`;
+  return query === "" ? url : `${url}?${query}`;
+}
+
+function nextDate(value: string): string {
+  const [year, month, day] = value.split("-").map(Number);
+  const next = new Date(year, month - 1, day + 1);
+  const pad = (part: number) => String(part).padStart(2, "0");
+  return `${next.getFullYear()}-${pad(next.getMonth() + 1)}-${pad(next.getDate())}`;
+}
+
+export function groupSessions(sessions: Session[], query: string, allRepos: boolean): SessionGroup[] {
   const normalizedQuery = query.trim().toLowerCase();
-  const appliesAll = statusFilters.length === 0 || statusFilters.includes("all");
   const filtered = sessions.filter((session) => {
-    if (!appliesAll && !statusFilters.includes(session.status)) {
-      return false;
-    }
     if (normalizedQuery === "") {
       return true;
     }
@@ -34,6 +86,9 @@ export function groupSessions(
       session.workingDir,
       session.detail,
       session.command.join(" "),
+      session.currentSkill ?? "",
+      session.lastSkillOutput ?? "",
+      session.lastError ?? "",
     ]
       .join(" ")
       .toLowerCase();
@@ -42,7 +97,8 @@ export function groupSessions(
 
   const groups = new Map<string, Session[]>();
   for (const item of filtered) {
-    const key = item.workflowName || "default";
+    const workflow = item.workflowName || "default";
+    const key = allRepos ? `${baseName(item.repoRoot)}/${workflow}` : workflow;
     const existing = groups.get(key);
     if (existing) {
       existing.push(item);
@@ -56,6 +112,28 @@ export function groupSessions(
     .map(([name, items]) => ({ name, items }));
 }
 
+export function baseName(path: string): string {
+  return path.split("/").filter(Boolean).at(-1) ?? "";
+}
+
+// MAX_LOG_CHARS keeps a long-running stream from growing the page without
+// bound; older output is dropped a line at a time.
+const MAX_LOG_CHARS = 512 * 1024;
+
+export function appendLog(current: string, chunk: string): string {
+  const next = current + chunk;
+  if (next.length <= MAX_LOG_CHARS) {
+    return next;
+  }
+  const trimmed = next.slice(next.length - MAX_LOG_CHARS);
+  const lineStart = trimmed.indexOf("\n");
+  return lineStart >= 0 ? trimmed.slice(lineStart + 1) : trimmed;
+}
+
+export function isResident(session: Session): boolean {
+  return Boolean(session.schedule) || (session.watch?.length ?? 0) > 0;
+}
+
 export function statusToneClass(status: SessionStatus): string {
   switch (status) {
     case "running":
@@ -63,6 +141,7 @@ export function statusToneClass(status: SessionStatus): string {
     case "scheduled":
       return "tone-blue";
     case "blocked":
+    case "paused":
       return "tone-amber";
     case "done":
       return "tone-green";
@@ -123,3 +202,17 @@ export function formatCompactDate(value?: string): string {
     minute: "2-digit",
   }).format(new Date(value));
 }
+
+export function formatDuration(seconds: number): string {
+  const total = Math.max(0, Math.round(seconds));
+  const hours = Math.floor(total / 3600);
+  const minutes = Math.floor((total % 3600) / 60);
+  const secs = total % 60;
+  if (hours > 0) {
+    return `${hours}h ${minutes}m`;
+  }
+  if (minutes > 0) {
+    return `${minutes}m ${secs}s`;
+  }
+  return `${secs}s`;
+}
@@ -0,0 +1,31 @@
+package sessionui
+
+import (
+	"net/http"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/graph"
+)
+
+// handleGetGraph returns the skill/route graph of the session's workflow with
+// the session's current skill and traversed routes marked.
+func (h *handler) handleGetGraph(w http.ResponseWriter, r *http.Request) {
+	meta, ok := h.loadViewedSession(w, r)
+	if !ok {
+		return
+	}
+	if meta.ConfigPath == "" {
+		writeErrorMessage(w, http.StatusNotFound, "session has no recorded config")
+		return
+	}
+
+	cfg, err := h.store.loadConfig(meta.ConfigPath, config.LoadOptions{Vars: meta.Vars})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	g := graph.Build(cfg, cfg.EffectiveName(meta.ConfigPath), "")
+	g.Apply(graph.SessionHighlight(meta))
+	writeJSON(w, http.StatusOK, g)
+}
@@ -0,0 +1,219 @@
+package sessionui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// SessionList is a page of run sessions, newest first. Total counts every
+// session that matched, including the ones outside the page. With AllRepos
+// set, the sessions come from every repository and RepoRoot is empty.
+type SessionList struct {
+	RepoRoot  string    `json:"repoRoot"`
+	AllRepos  bool      `json:"allRepos,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Total     int       `json:"total"`
+	Sessions  []Session `json:"sessions"`
+}
+
+// Session describes a run session. It is served by the dashboard API and
+// printed by skill-loop sessions --output json|yaml, so fields are only added,
+// never renamed or removed.
+type Session struct {
+	ID                 string            `json:"id"`
+	WorkflowName       string            `json:"workflowName"`
+	Skill              string            `json:"skill"`
+	Runtime            string            `json:"runtime"`
+	RepoRoot           string            `json:"repoRoot"`
+	WorkingDir         string            `json:"workingDir"`
+	ConfigPath         string            `json:"configPath,omitempty"`
+	ConfigName         string            `json:"configName"`
+	Schedule           string            `json:"schedule,omitempty"`
+	Watch              []string          `json:"watch,omitempty"`
+	Command            []string          `json:"command"`
+	Backend            string            `json:"backend"`
+	TmuxSession        string            `json:"tmuxSession"`
+	ScriptPath         string            `json:"scriptPath"`
+	SessionDir         string            `json:"sessionDir"`
+	StdoutPath         string            `json:"stdoutPath"`
+	StderrPath         string            `json:"stderrPath"`
+	ExitCodePath       string            `json:"exitCodePath"`
+	Status             session.Status    `json:"status"`
+	Detail             string            `json:"detail"`
+	PID                int               `json:"pid,omitempty"`
+	StartedAt          time.Time         `json:"startedAt"`
+	LastOutputAt       time.Time         `json:"lastOutputAt"`
+	EndedAt            *time.Time        `json:"endedAt,omitempty"`
+	NextRun            *time.Time        `json:"nextRun,omitempty"`
+	CurrentIteration   int               `json:"currentIteration,omitempty"`
+	MaxIterations      int               `json:"maxIterations,omitempty"`
+	CurrentSkill       string            `json:"currentSkill,omitempty"`
+	LastSkillOutput    string            `json:"lastSkillOutput,omitempty"`
+	BlockReason        string            `json:"blockReason,omitempty"`
+	ResumeSkill        string            `json:"resumeSkill,omitempty"`
+	ResumePrompt       string            `json:"resumePrompt,omitempty"`
+	PreviousSummary    string            `json:"previousSummary,omitempty"`
+	Vars               map[string]string `json:"vars,omitempty"`
+	IdleTimeoutSeconds int               `json:"idleTimeoutSeconds"`
+	MaxRestarts        int               `json:"maxRestarts"`
+	RestartCount       int               `json:"restartCount"`
+	LastError          string            `json:"lastError,omitempty"`
+}
+
+// Log is the captured output of one stream of a session, or of one of its
+// runs when RunID is set.
+type Log struct {
+	SessionID string `json:"sessionId"`
+	RunID     string `json:"runId,omitempty"`
+	Stream    string `json:"stream"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+}
+
+// PruneResult summarizes a prune. With DryRun set, the sessions were only
+// selected, not deleted.
+type PruneResult struct {
+	DryRun             bool     `json:"dryRun,omitempty"`
+	Pruned             int      `json:"pruned"`
+	PrunedSessionIDs   []string `json:"prunedSessionIds"`
+	FailedSessionIDs   []string `json:"failedSessionIds,omitempty"`
+	SkippedRunning     int      `json:"skippedRunning"`
+	SkippedNonTerminal int      `json:"skippedNonTerminal"`
+}
+
+// NewSession converts the metadata of a session.
+func NewSession(meta *session.Metadata) Session {
+	return Session{
+		ID:                 meta.ID,
+		WorkflowName:       meta.WorkflowName,
+		Skill:              meta.Skill,
+		Runtime:            meta.Runtime,
+		RepoRoot:           meta.RepoRoot,
+		WorkingDir:         meta.WorkingDir,
+		ConfigPath:         meta.ConfigPath,
+		ConfigName:         ConfigName(meta),
+		Schedule:           meta.Schedule,
+		Watch:              meta.Watch,
+		Command:            append([]string(nil), meta.Command...),
+		Backend:            session.BackendName(meta),
+		TmuxSession:        meta.TmuxSession,
+		ScriptPath:         meta.ScriptPath,
+		SessionDir:         filepath.Dir(meta.ScriptPath),
+		StdoutPath:         meta.StdoutPath,
+		StderrPath:         meta.StderrPath,
+		ExitCodePath:       meta.ExitCodePath,
+		Status:             meta.Status,
+		Detail:             Detail(meta),
+		PID:                meta.PID,
+		StartedAt:          meta.StartedAt,
+		LastOutputAt:       meta.LastOutputAt,
+		EndedAt:            meta.EndedAt,
+		NextRun:            meta.NextRun,
+		CurrentIteration:   meta.CurrentIteration,
+		MaxIterations:      meta.MaxIterations,
+		CurrentSkill:       meta.CurrentSkill,
+		LastSkillOutput:    meta.LastSkillOutput,
+		BlockReason:        meta.BlockReason,
+		ResumeSkill:        meta.ResumeSkill,
+		ResumePrompt:       meta.ResumePrompt,
+		PreviousSummary:    previousSummary(meta),
+		Vars:               meta.Vars,
+		IdleTimeoutSeconds: meta.IdleTimeoutSeconds,
+		MaxRestarts:        meta.MaxRestarts,
+		RestartCount:       meta.RestartCount,
+		LastError:          meta.LastError,
+	}
+}
+
+// Detail summarizes the state of a session in one line: the next run of a
+// scheduled session, what a blocked session waits for, the error of a failed
+// one.
+func Detail(meta *session.Metadata) string {
+	switch meta.Status {
+	case session.StatusScheduled:
+		detail := "next: n/a"
+		switch {
+		case meta.NextRun != nil:
+			detail = "next: " + meta.NextRun.Local().Format(time.DateTime)
+		case len(meta.Watch) > 0:
+			detail = "watching: " + strings.Join(meta.Watch, ", ")
+		}
+		if meta.LastError != "" {
+			detail += " error: " + meta.LastError
+		}
+		return detail
+	case session.StatusPaused:
+		if meta.LastError != "" {
+			return "paused error: " + meta.LastError
+		}
+		return "paused"
+	case session.StatusRunning:
+		if meta.IsResident() && meta.MaxIterations > 0 {
+			return fmt.Sprintf("iter: %d/%d", meta.CurrentIteration, meta.MaxIterations)
+		}
+		return "last_output: " + meta.LastOutputAt.Local().Format(time.DateTime)
+	case session.StatusBlocked:
+		if meta.BlockReason != "" {
+			return "awaiting input: " + meta.BlockReason
+		}
+		if meta.ResumeSkill != "" {
+			return "awaiting input for " + meta.ResumeSkill
+		}
+		return "awaiting human input"
+	case session.StatusFailed, session.StatusStopped:
+		if meta.LastError != "" {
+			return meta.LastError
+		}
+		if meta.EndedAt != nil {
+			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
+		}
+		return string(meta.Status)
+	default:
+		if meta.EndedAt != nil {
+			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
+		}
+		return meta.LastOutputAt.Local().Format(time.DateTime)
+	}
+}
+
+// ConfigName returns the workflow name of a session, falling back to the
+// file name of its config.
+func ConfigName(meta *session.Metadata) string {
+	if meta.WorkflowName != "" {
+		return meta.WorkflowName
+	}
+	if meta.ConfigPath == "" {
+		return "-"
+	}
+	return filepath.Base(meta.ConfigPath)
+}
+
+func previousSummary(meta *session.Metadata) string {
+	if strings.TrimSpace(meta.LastSkillOutput) != "" {
+		return strings.TrimSpace(meta.LastSkillOutput)
+	}
+	return extractPreviousSummary(meta.ResumePrompt)
+}
+
+func extractPreviousSummary(prompt string) string {
+	trimmed := strings.TrimSpace(prompt)
+	if trimmed == "" {
+		return ""
+	}
+
+	const marker = "Previous skill stdout:\n"
+	idx := strings.Index(trimmed, marker)
+	if idx == -1 {
+		return trimmed
+	}
+
+	summary := strings.TrimSpace(trimmed[idx+len(marker):])
+	if summary == "" {
+		return trimmed
+	}
+	return summary
+}
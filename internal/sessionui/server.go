@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
 	"github.com/takumiyoshikawa/skill-loop/internal/session"
 	"github.com/takumiyoshikawa/skill-loop/internal/sessionui/static"
 )
@@ -23,65 +28,50 @@ type sessionStore struct {
 	reconcile  func(meta *session.Metadata) error
 	stop       func(meta *session.Metadata) error
 	resume     func(meta *session.Metadata, prompt string) error
+	trigger    func(meta *session.Metadata, prompt string) error
+	pause      func(meta *session.Metadata) error
+	unpause    func(meta *session.Metadata) error
 	deleteByID func(repoRoot, id string) error
+	listRuns   func(meta *session.Metadata) ([]*session.Run, error)
+	loadRun    func(meta *session.Metadata, runID string) (*session.Run, error)
 	readFile   func(path string) ([]byte, error)
+	openFile   func(path string) (io.ReadSeekCloser, error)
+
+	discoverConfigs func(repoRoot string) ([]string, error)
+	loadConfig      func(path string, opts config.LoadOptions) (*config.Config, error)
+	startRun        func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error)
 }
 
 type handler struct {
 	repoRoot string
 	store    sessionStore
 	static   fs.FS
-}
-
-type listResponse struct {
-	RepoRoot  string       `json:"repoRoot"`
-	UpdatedAt time.Time    `json:"updatedAt"`
-	Sessions  []sessionDTO `json:"sessions"`
-}
-
-type sessionDTO struct {
-	ID                 string         `json:"id"`
-	WorkflowName       string         `json:"workflowName"`
-	Skill              string         `json:"skill"`
-	Runtime            string         `json:"runtime"`
-	RepoRoot           string         `json:"repoRoot"`
-	WorkingDir         string         `json:"workingDir"`
-	ConfigPath         string         `json:"configPath,omitempty"`
-	ConfigName         string         `json:"configName"`
-	Schedule           string         `json:"schedule,omitempty"`
-	Command            []string       `json:"command"`
-	TmuxSession        string         `json:"tmuxSession"`
-	ScriptPath         string         `json:"scriptPath"`
-	SessionDir         string         `json:"sessionDir"`
-	StdoutPath         string         `json:"stdoutPath"`
-	StderrPath         string         `json:"stderrPath"`
-	ExitCodePath       string         `json:"exitCodePath"`
-	Status             session.Status `json:"status"`
-	Detail             string         `json:"detail"`
-	PID                int            `json:"pid,omitempty"`
-	StartedAt          time.Time      `json:"startedAt"`
-	LastOutputAt       time.Time      `json:"lastOutputAt"`
-	EndedAt            *time.Time     `json:"endedAt,omitempty"`
-	NextRun            *time.Time     `json:"nextRun,omitempty"`
-	CurrentIteration   int            `json:"currentIteration,omitempty"`
-	MaxIterations      int            `json:"maxIterations,omitempty"`
-	CurrentSkill       string         `json:"currentSkill,omitempty"`
-	LastSkillOutput    string         `json:"lastSkillOutput,omitempty"`
-	BlockReason        string         `json:"blockReason,omitempty"`
-	ResumeSkill        string         `json:"resumeSkill,omitempty"`
-	ResumePrompt       string         `json:"resumePrompt,omitempty"`
-	PreviousSummary    string         `json:"previousSummary,omitempty"`
-	IdleTimeoutSeconds int            `json:"idleTimeoutSeconds"`
-	MaxRestarts        int            `json:"maxRestarts"`
-	RestartCount       int            `json:"restartCount"`
-	LastError          string         `json:"lastError,omitempty"`
-}
-
-type logResponse struct {
-	SessionID string `json:"sessionId"`
-	Stream    string `json:"stream"`
-	Path      string `json:"path"`
-	Content   string `json:"content"`
+	configs  configCache
+}
+
+type runDTO struct {
+	ID               string         `json:"id"`
+	SessionID        string         `json:"sessionId"`
+	Trigger          string         `json:"trigger"`
+	Status           session.Status `json:"status"`
+	Entrypoint       string         `json:"entrypoint,omitempty"`
+	Prompt           string         `json:"prompt,omitempty"`
+	StdoutPath       string         `json:"stdoutPath"`
+	StderrPath       string         `json:"stderrPath"`
+	StartedAt        time.Time      `json:"startedAt"`
+	EndedAt          *time.Time     `json:"endedAt,omitempty"`
+	DurationSeconds  float64        `json:"durationSeconds"`
+	CurrentIteration int            `json:"currentIteration,omitempty"`
+	MaxIterations    int            `json:"maxIterations,omitempty"`
+	CurrentSkill     string         `json:"currentSkill,omitempty"`
+	LastSkillOutput  string         `json:"lastSkillOutput,omitempty"`
+	BlockReason      string         `json:"blockReason,omitempty"`
+	LastError        string         `json:"lastError,omitempty"`
+}
+
+type runsResponse struct {
+	SessionID string   `json:"sessionId"`
+	Runs      []runDTO `json:"runs"`
 }
 
 type pruneRequest struct {
@@ -92,11 +82,8 @@ type resumeRequest struct {
 	Prompt string `json:"prompt"`
 }
 
-type pruneResponse struct {
-	Pruned             int      `json:"pruned"`
-	PrunedSessionIDs   []string `json:"prunedSessionIds"`
-	SkippedRunning     int      `json:"skippedRunning"`
-	SkippedNonTerminal int      `json:"skippedNonTerminal"`
+type triggerRequest struct {
+	Prompt string `json:"prompt"`
 }
 
 type errorResponse struct {
@@ -117,6 +104,9 @@ func NewHandler(repoRoot string) (http.Handler, error) {
 			reconcile: session.Reconcile,
 			stop:      session.Stop,
 			resume: func(meta *session.Metadata, prompt string) error {
+				if meta.IsResident() {
+					return session.RequestResume(meta, prompt)
+				}
 				command, err := session.BuildResumeCommand(meta, prompt)
 				if err != nil {
 					return err
@@ -126,8 +116,18 @@ func NewHandler(repoRoot string) (http.Handler, error) {
 				}
 				return session.Start(meta)
 			},
+			trigger:    session.RequestTrigger,
+			pause:      session.RequestPause,
+			unpause:    session.RequestUnpause,
 			deleteByID: session.DeleteByID,
+			listRuns:   session.ListRuns,
+			loadRun:    session.LoadRun,
 			readFile:   os.ReadFile,
+			openFile:   openLogFile,
+
+			discoverConfigs: config.Discover,
+			loadConfig:      config.LoadWithOptions,
+			startRun:        launch.Detached,
 		},
 		static: sub,
 	}
@@ -136,55 +136,155 @@ func NewHandler(repoRoot string) (http.Handler, error) {
 	mux.HandleFunc("GET /api/sessions", h.handleListSessions)
 	mux.HandleFunc("GET /api/sessions/{id}", h.handleGetSession)
 	mux.HandleFunc("GET /api/sessions/{id}/logs/{stream}", h.handleGetLog)
+	mux.HandleFunc("GET /api/sessions/{id}/logs/{stream}/events", h.handleStreamLog)
+	mux.HandleFunc("GET /api/sessions/{id}/runs", h.handleListRuns)
+	mux.HandleFunc("GET /api/sessions/{id}/graph", h.handleGetGraph)
 	mux.HandleFunc("POST /api/sessions/{id}/stop", h.handleStopSession)
 	mux.HandleFunc("POST /api/sessions/{id}/resume", h.handleResumeSession)
+	mux.HandleFunc("POST /api/sessions/{id}/trigger", h.handleTriggerSession)
+	mux.HandleFunc("POST /api/sessions/{id}/pause", h.handlePauseSession)
+	mux.HandleFunc("POST /api/sessions/{id}/unpause", h.handleUnpauseSession)
 	mux.HandleFunc("DELETE /api/sessions/{id}", h.handleDeleteSession)
 	mux.HandleFunc("POST /api/sessions/prune", h.handlePruneSessions)
+	mux.HandleFunc("GET /api/configs", h.handleListConfigs)
+	mux.HandleFunc("POST /api/runs", h.handleStartRun)
 	mux.Handle("/", h.handleSPA())
 
-	return mux, nil
+	return guardRequests(mux), nil
+}
+
+// guardRequests rejects requests that another site could have sent from a
+// browser. Their Host must be a loopback name or the address the dashboard
+// listens on, so that a page whose host name was rebound to the dashboard
+// cannot reach it. Requests other than GET and HEAD must also have an Origin,
+// when present, that matches the host of the dashboard and a JSON body, which
+// a cross-site form cannot send without a preflight.
+func guardRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r) {
+			writeErrorMessage(w, http.StatusForbidden, fmt.Sprintf("host %q is not allowed", r.Host))
+			return
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			parsed, err := url.Parse(origin)
+			if err != nil || parsed.Host != r.Host {
+				writeErrorMessage(w, http.StatusForbidden, "cross-origin requests are not allowed")
+				return
+			}
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeErrorMessage(w, http.StatusUnsupportedMediaType, "requests must have Content-Type application/json")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host of r is localhost, a loopback address
+// or the address of the connection it arrived on.
+func allowedHost(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = strings.Trim(r.Host, "[]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	local, ok := r.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr)
+	return ok && local.IP.Equal(ip)
 }
 
 func (h *handler) handleListSessions(w http.ResponseWriter, r *http.Request) {
-	metas, err := h.listRunSessions()
+	query := r.URL.Query()
+	filter, err := parseSessionFilter(query, time.Now())
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	allRepos, err := parseAllRepos(query)
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// offset and limit mirror the flags of skill-loop sessions ls; a zero
+	// limit returns every matching session.
+	offset, err := parseCount(query, "offset")
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := parseCount(query, "limit")
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	repoRoot := h.repoRoot
+	if allRepos {
+		repoRoot = ""
+	}
+	metas, err := h.listRunSessions(repoRoot)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	sessions := make([]sessionDTO, 0, len(metas))
+	matched := make([]*session.Metadata, 0, len(metas))
 	for _, meta := range metas {
-		sessions = append(sessions, toSessionDTO(meta))
+		if filter.Match(meta) {
+			matched = append(matched, meta)
+		}
 	}
 
-	writeJSON(w, http.StatusOK, listResponse{
-		RepoRoot:  h.repoRoot,
+	page := matched[min(offset, len(matched)):]
+	if limit > 0 && len(page) > limit {
+		page = page[:limit]
+	}
+	sessions := make([]Session, 0, len(page))
+	for _, meta := range page {
+		sessions = append(sessions, NewSession(meta))
+	}
+
+	writeJSON(w, http.StatusOK, SessionList{
+		RepoRoot:  repoRoot,
+		AllRepos:  allRepos,
 		UpdatedAt: time.Now().UTC(),
+		Total:     len(matched),
 		Sessions:  sessions,
 	})
 }
 
 func (h *handler) handleGetSession(w http.ResponseWriter, r *http.Request) {
-	meta, err := h.loadRunSession(r.PathValue("id"))
-	if err != nil {
-		h.writeSessionError(w, err)
+	meta, ok := h.loadViewedSession(w, r)
+	if !ok {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toSessionDTO(meta))
+	writeJSON(w, http.StatusOK, NewSession(meta))
 }
 
 func (h *handler) handleGetLog(w http.ResponseWriter, r *http.Request) {
-	meta, err := h.loadRunSession(r.PathValue("id"))
-	if err != nil {
-		h.writeSessionError(w, err)
+	meta, ok := h.loadViewedSession(w, r)
+	if !ok {
 		return
 	}
 
 	stream := r.PathValue("stream")
-	logPath, ok := logPathForStream(meta, stream)
+	runID := r.URL.Query().Get("run")
+	logPath, ok := h.resolveLogPath(w, meta, stream, runID)
 	if !ok {
-		writeErrorMessage(w, http.StatusNotFound, "unknown log stream")
 		return
 	}
 
@@ -194,16 +294,66 @@ func (h *handler) handleGetLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, logResponse{
+	writeJSON(w, http.StatusOK, Log{
 		SessionID: meta.ID,
+		RunID:     runID,
 		Stream:    stream,
 		Path:      logPath,
 		Content:   content,
 	})
 }
 
+// resolveLogPath returns the log file for stream, taken from the given run
+// when runID is set. It writes the error response itself and reports false
+// when the path cannot be resolved.
+func (h *handler) resolveLogPath(w http.ResponseWriter, meta *session.Metadata, stream string, runID string) (string, bool) {
+	logPath, ok := logPathForStream(meta.StdoutPath, meta.StderrPath, stream)
+	if !ok {
+		writeErrorMessage(w, http.StatusNotFound, "unknown log stream")
+		return "", false
+	}
+	if runID == "" {
+		return logPath, true
+	}
+
+	run, err := h.store.loadRun(meta, runID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			writeErrorMessage(w, http.StatusNotFound, "run not found")
+			return "", false
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return "", false
+	}
+	logPath, _ = logPathForStream(run.StdoutPath, run.StderrPath, stream)
+	return logPath, true
+}
+
+func (h *handler) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	meta, ok := h.loadViewedSession(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.store.listRuns(meta)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]runDTO, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, toRunDTO(run))
+	}
+
+	writeJSON(w, http.StatusOK, runsResponse{
+		SessionID: meta.ID,
+		Runs:      items,
+	})
+}
+
 func (h *handler) handleStopSession(w http.ResponseWriter, r *http.Request) {
-	meta, err := h.loadRunSession(r.PathValue("id"))
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
 	if err != nil {
 		h.writeSessionError(w, err)
 		return
@@ -214,11 +364,11 @@ func (h *handler) handleStopSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toSessionDTO(meta))
+	writeJSON(w, http.StatusOK, NewSession(meta))
 }
 
 func (h *handler) handleResumeSession(w http.ResponseWriter, r *http.Request) {
-	meta, err := h.loadRunSession(r.PathValue("id"))
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
 	if err != nil {
 		h.writeSessionError(w, err)
 		return
@@ -240,22 +390,78 @@ func (h *handler) handleResumeSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toSessionDTO(meta))
+	writeJSON(w, http.StatusOK, NewSession(meta))
+}
+
+func (h *handler) handleTriggerSession(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
+	if err != nil {
+		h.writeSessionError(w, err)
+		return
+	}
+
+	var req triggerRequest
+	if r.Body != nil {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeErrorMessage(w, http.StatusBadRequest, "invalid trigger request")
+			return
+		}
+	}
+
+	if err := h.store.trigger(meta, req.Prompt); err != nil {
+		writeControlError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, NewSession(meta))
+}
+
+func (h *handler) handlePauseSession(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
+	if err != nil {
+		h.writeSessionError(w, err)
+		return
+	}
+
+	if err := h.store.pause(meta); err != nil {
+		writeControlError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, NewSession(meta))
+}
+
+func (h *handler) handleUnpauseSession(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
+	if err != nil {
+		h.writeSessionError(w, err)
+		return
+	}
+
+	if err := h.store.unpause(meta); err != nil {
+		writeControlError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, NewSession(meta))
 }
 
 func (h *handler) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
-	meta, err := h.loadRunSession(r.PathValue("id"))
+	meta, err := h.loadRunSession(r.PathValue("id"), false)
 	if err != nil {
 		h.writeSessionError(w, err)
 		return
 	}
 
-	if meta.Status == session.StatusRunning || meta.Status == session.StatusScheduled {
+	if session.IsActiveStatus(meta.Status) {
 		writeErrorMessage(w, http.StatusConflict, "stop the session before deleting it")
 		return
 	}
 
-	if err := h.store.deleteByID(h.repoRoot, meta.ID); err != nil {
+	if err := h.store.deleteByID(meta.RepoRoot, meta.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -275,18 +481,18 @@ func (h *handler) handlePruneSessions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	metas, err := h.listRunSessions()
+	metas, err := h.listRunSessions(h.repoRoot)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	result := pruneResponse{}
+	result := PruneResult{}
 	for _, meta := range metas {
 		switch {
-		case meta.Status == session.StatusRunning || meta.Status == session.StatusScheduled:
+		case session.IsActiveStatus(meta.Status):
 			result.SkippedRunning++
-		case !req.All && !isTerminalStatus(meta.Status):
+		case !req.All && !session.IsTerminalStatus(meta.Status):
 			result.SkippedNonTerminal++
 		default:
 			if err := h.store.deleteByID(h.repoRoot, meta.ID); err != nil {
@@ -331,8 +537,11 @@ func (h *handler) handleSPA() http.Handler {
 	})
 }
 
-func (h *handler) listRunSessions() ([]*session.Metadata, error) {
-	all, err := h.store.list(h.repoRoot)
+// listRunSessions returns the run sessions of repoRoot, or of every repository
+// when repoRoot is empty. Only sessions with a live process are reconciled:
+// the status of the others no longer changes.
+func (h *handler) listRunSessions(repoRoot string) ([]*session.Metadata, error) {
+	all, err := h.store.list(repoRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -342,8 +551,10 @@ func (h *handler) listRunSessions() ([]*session.Metadata, error) {
 		if meta.Skill != "orchestrator" {
 			continue
 		}
-		if err := h.store.reconcile(meta); err != nil {
-			return nil, err
+		if session.IsActiveStatus(meta.Status) || meta.Status == session.StatusPending {
+			if err := h.store.reconcile(meta); err != nil {
+				return nil, err
+			}
 		}
 		runs = append(runs, meta)
 	}
@@ -351,17 +562,104 @@ func (h *handler) listRunSessions() ([]*session.Metadata, error) {
 	return runs, nil
 }
 
-func (h *handler) loadRunSession(id string) (*session.Metadata, error) {
+// parseSessionFilter reads the filter query parameters of GET /api/sessions,
+// which mirror the filter flags of skill-loop sessions ls.
+func parseSessionFilter(query url.Values, now time.Time) (session.Filter, error) {
+	statuses, err := session.ParseStatuses(query["status"])
+	if err != nil {
+		return session.Filter{}, err
+	}
+	since, err := session.ParseTime(query.Get("since"), now)
+	if err != nil {
+		return session.Filter{}, fmt.Errorf("invalid since %q: %w", query.Get("since"), err)
+	}
+	until, err := session.ParseTime(query.Get("until"), now)
+	if err != nil {
+		return session.Filter{}, fmt.Errorf("invalid until %q: %w", query.Get("until"), err)
+	}
+	return session.Filter{
+		Statuses: statuses,
+		Workflow: strings.TrimSpace(query.Get("workflow")),
+		Config:   strings.TrimSpace(query.Get("config")),
+		Skill:    strings.TrimSpace(query.Get("skill")),
+		Since:    since,
+		Until:    until,
+		Search:   strings.TrimSpace(query.Get("search")),
+	}, nil
+}
+
+// parseAllRepos reads the allRepos query parameter.
+func parseAllRepos(query url.Values) (bool, error) {
+	value := query.Get("allRepos")
+	if value == "" {
+		return false, nil
+	}
+	allRepos, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid allRepos %q", value)
+	}
+	return allRepos, nil
+}
+
+// parseCount reads a non-negative integer query parameter, which is zero
+// when it is missing.
+func parseCount(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// loadViewedSession loads the run session of a read-only request like
+// loadRunSession, also finding sessions of other repositories when the
+// request has allRepos=true, as the views of an allRepos listing do. It
+// writes the error response itself.
+func (h *handler) loadViewedSession(w http.ResponseWriter, r *http.Request) (*session.Metadata, bool) {
+	allRepos, err := parseAllRepos(r.URL.Query())
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	meta, err := h.loadRunSession(r.PathValue("id"), allRepos)
+	if err != nil {
+		h.writeSessionError(w, err)
+		return nil, false
+	}
+	return meta, true
+}
+
+// loadRunSession loads a run session like findRunSession and reconciles it
+// with its process.
+func (h *handler) loadRunSession(id string, allRepos bool) (*session.Metadata, error) {
+	meta, err := h.findRunSession(id, allRepos)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.store.reconcile(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// findRunSession reads a run session of the repository. With allRepos it also
+// reads sessions of other repositories, which are only ever viewed: actions
+// reach the sessions of the repository alone.
+func (h *handler) findRunSession(id string, allRepos bool) (*session.Metadata, error) {
 	meta, err := h.store.load(h.repoRoot, id)
+	if allRepos && errors.Is(err, os.ErrNotExist) {
+		meta, err = h.store.load("", id)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if meta.Skill != "orchestrator" {
 		return nil, os.ErrNotExist
 	}
-	if err := h.store.reconcile(meta); err != nil {
-		return nil, err
-	}
 	return meta, nil
 }
 
@@ -373,109 +671,47 @@ func (h *handler) writeSessionError(w http.ResponseWriter, err error) {
 	writeError(w, http.StatusInternalServerError, err)
 }
 
-func toSessionDTO(meta *session.Metadata) sessionDTO {
-	return sessionDTO{
-		ID:                 meta.ID,
-		WorkflowName:       meta.WorkflowName,
-		Skill:              meta.Skill,
-		Runtime:            meta.Runtime,
-		RepoRoot:           meta.RepoRoot,
-		WorkingDir:         meta.WorkingDir,
-		ConfigPath:         meta.ConfigPath,
-		ConfigName:         sessionConfigName(meta),
-		Schedule:           meta.Schedule,
-		Command:            append([]string(nil), meta.Command...),
-		TmuxSession:        meta.TmuxSession,
-		ScriptPath:         meta.ScriptPath,
-		SessionDir:         filepath.Dir(meta.ScriptPath),
-		StdoutPath:         meta.StdoutPath,
-		StderrPath:         meta.StderrPath,
-		ExitCodePath:       meta.ExitCodePath,
-		Status:             meta.Status,
-		Detail:             formatSessionDetail(meta),
-		PID:                meta.PID,
-		StartedAt:          meta.StartedAt,
-		LastOutputAt:       meta.LastOutputAt,
-		EndedAt:            meta.EndedAt,
-		NextRun:            meta.NextRun,
-		CurrentIteration:   meta.CurrentIteration,
-		MaxIterations:      meta.MaxIterations,
-		CurrentSkill:       meta.CurrentSkill,
-		LastSkillOutput:    meta.LastSkillOutput,
-		BlockReason:        meta.BlockReason,
-		ResumeSkill:        meta.ResumeSkill,
-		ResumePrompt:       meta.ResumePrompt,
-		PreviousSummary:    previousSummary(meta),
-		IdleTimeoutSeconds: meta.IdleTimeoutSeconds,
-		MaxRestarts:        meta.MaxRestarts,
-		RestartCount:       meta.RestartCount,
-		LastError:          meta.LastError,
-	}
-}
-
-func formatSessionDetail(meta *session.Metadata) string {
-	switch meta.Status {
-	case session.StatusScheduled:
-		if meta.NextRun == nil {
-			if meta.LastError != "" {
-				return "next: n/a error: " + meta.LastError
-			}
-			return "next: n/a"
-		}
-		detail := "next: " + meta.NextRun.Local().Format(time.DateTime)
-		if meta.LastError != "" {
-			detail += " error: " + meta.LastError
-		}
-		return detail
-	case session.StatusRunning:
-		if meta.Schedule != "" && meta.MaxIterations > 0 {
-			return fmt.Sprintf("iter: %d/%d", meta.CurrentIteration, meta.MaxIterations)
-		}
-		return "last_output: " + meta.LastOutputAt.Local().Format(time.DateTime)
-	case session.StatusBlocked:
-		if meta.BlockReason != "" {
-			return "awaiting input: " + meta.BlockReason
-		}
-		if meta.ResumeSkill != "" {
-			return "awaiting input for " + meta.ResumeSkill
-		}
-		return "awaiting human input"
-	case session.StatusFailed, session.StatusStopped:
-		if meta.LastError != "" {
-			return meta.LastError
-		}
-		if meta.EndedAt != nil {
-			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
-		}
-		return string(meta.Status)
+// writeControlError writes the error of a control request to a resident
+// scheduler, such as session.RequestTrigger.
+func writeControlError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, session.ErrNotResident):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, session.ErrInvalidStatus):
+		writeError(w, http.StatusConflict, err)
 	default:
-		if meta.EndedAt != nil {
-			return "ended: " + meta.EndedAt.Local().Format(time.DateTime)
-		}
-		return meta.LastOutputAt.Local().Format(time.DateTime)
+		writeError(w, http.StatusInternalServerError, err)
 	}
 }
 
-func sessionConfigName(meta *session.Metadata) string {
-	if meta.WorkflowName != "" {
-		return meta.WorkflowName
-	}
-	if meta.ConfigPath == "" {
-		return "-"
+func toRunDTO(run *session.Run) runDTO {
+	return runDTO{
+		ID:               run.ID,
+		SessionID:        run.SessionID,
+		Trigger:          run.Trigger,
+		Status:           run.Status,
+		Entrypoint:       run.Entrypoint,
+		Prompt:           run.Prompt,
+		StdoutPath:       run.StdoutPath,
+		StderrPath:       run.StderrPath,
+		StartedAt:        run.StartedAt,
+		EndedAt:          run.EndedAt,
+		DurationSeconds:  run.Duration().Seconds(),
+		CurrentIteration: run.CurrentIteration,
+		MaxIterations:    run.MaxIterations,
+		CurrentSkill:     run.CurrentSkill,
+		LastSkillOutput:  run.LastSkillOutput,
+		BlockReason:      run.BlockReason,
+		LastError:        run.LastError,
 	}
-	return filepath.Base(meta.ConfigPath)
-}
-
-func isTerminalStatus(status session.Status) bool {
-	return status == session.StatusDone || status == session.StatusFailed || status == session.StatusStopped
 }
 
-func logPathForStream(meta *session.Metadata, stream string) (string, bool) {
+func logPathForStream(stdoutPath string, stderrPath string, stream string) (string, bool) {
 	switch stream {
 	case "stdout":
-		return meta.StdoutPath, true
+		return stdoutPath, true
 	case "stderr":
-		return meta.StderrPath, true
+		return stderrPath, true
 	default:
 		return "", false
 	}
@@ -497,32 +733,6 @@ func readLogFile(readFile func(path string) ([]byte, error), path string, tail i
 	return content, nil
 }
 
-func previousSummary(meta *session.Metadata) string {
-	if strings.TrimSpace(meta.LastSkillOutput) != "" {
-		return strings.TrimSpace(meta.LastSkillOutput)
-	}
-	return extractPreviousSummary(meta.ResumePrompt)
-}
-
-func extractPreviousSummary(prompt string) string {
-	trimmed := strings.TrimSpace(prompt)
-	if trimmed == "" {
-		return ""
-	}
-
-	const marker = "Previous skill stdout:\n"
-	idx := strings.Index(trimmed, marker)
-	if idx == -1 {
-		return trimmed
-	}
-
-	summary := strings.TrimSpace(trimmed[idx+len(marker):])
-	if summary == "" {
-		return trimmed
-	}
-	return summary
-}
-
 func tailLines(s string, n int) string {
 	if n <= 0 {
 		return s
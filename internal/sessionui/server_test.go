@@ -1,14 +1,25 @@
 package sessionui
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/graph"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
 	"github.com/takumiyoshikawa/skill-loop/internal/session"
 )
 
@@ -54,7 +65,7 @@ func TestListSessionsFiltersAndFormats(t *testing.T) {
 		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var got listResponse
+	var got SessionList
 	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
@@ -108,7 +119,7 @@ func TestGetLogSupportsMissingFile(t *testing.T) {
 		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var got logResponse
+	var got Log
 	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
@@ -246,3 +257,897 @@ func TestTailLines(t *testing.T) {
 		t.Fatalf("tailLines() = %q, want %q", got, want)
 	}
 }
+
+func TestListRuns(t *testing.T) {
+	started := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	ended := started.Add(90 * time.Second)
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", Schedule: "0 9 * * *"}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			listRuns: func(meta *session.Metadata) ([]*session.Run, error) {
+				return []*session.Run{{
+					ID:              "run-a",
+					SessionID:       meta.ID,
+					Trigger:         session.TriggerSchedule,
+					Status:          session.StatusDone,
+					StartedAt:       started,
+					EndedAt:         &ended,
+					LastSkillOutput: "all checks passed",
+				}}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/sched-1/runs", nil)
+	req.SetPathValue("id", "sched-1")
+	rec := httptest.NewRecorder()
+
+	h.handleListRuns(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var got runsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Runs) != 1 || got.Runs[0].ID != "run-a" {
+		t.Fatalf("runs = %+v, want [run-a]", got.Runs)
+	}
+	if got.Runs[0].DurationSeconds != 90 {
+		t.Fatalf("durationSeconds = %v, want 90", got.Runs[0].DurationSeconds)
+	}
+	if got.Runs[0].LastSkillOutput != "all checks passed" {
+		t.Fatalf("lastSkillOutput = %q, want all checks passed", got.Runs[0].LastSkillOutput)
+	}
+}
+
+func TestGetLogReadsRunLog(t *testing.T) {
+	var readPath string
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", StdoutPath: "/tmp/stdout.log"}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			loadRun: func(meta *session.Metadata, runID string) (*session.Run, error) {
+				return &session.Run{ID: runID, StdoutPath: "/tmp/runs/" + runID + "/stdout.log"}, nil
+			},
+			readFile: func(path string) ([]byte, error) {
+				readPath = path
+				return []byte("run output\n"), nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/sched-1/logs/stdout?run=run-a", nil)
+	req.SetPathValue("id", "sched-1")
+	req.SetPathValue("stream", "stdout")
+	rec := httptest.NewRecorder()
+
+	h.handleGetLog(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if readPath != "/tmp/runs/run-a/stdout.log" {
+		t.Fatalf("read path = %q, want run log", readPath)
+	}
+}
+
+func TestTriggerSession(t *testing.T) {
+	var triggered bool
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", Schedule: "0 9 * * *", Status: session.StatusScheduled}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			trigger: func(meta *session.Metadata, prompt string) error {
+				triggered = true
+				return nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/sched-1/trigger", strings.NewReader(`{}`))
+	req.SetPathValue("id", "sched-1")
+	rec := httptest.NewRecorder()
+
+	h.handleTriggerSession(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if !triggered {
+		t.Fatal("trigger was not requested")
+	}
+}
+
+func TestTriggerSessionRejectsRunningSession(t *testing.T) {
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", Schedule: "0 9 * * *", Status: session.StatusRunning}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			trigger:   session.RequestTrigger,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/sched-1/trigger", nil)
+	req.SetPathValue("id", "sched-1")
+	rec := httptest.NewRecorder()
+
+	h.handleTriggerSession(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), "already running") {
+		t.Fatalf("body = %q, want the RequestTrigger error", rec.Body.String())
+	}
+}
+
+func TestPauseSessionRejectsNonResidentSession(t *testing.T) {
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", Status: session.StatusRunning}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			pause:     session.RequestPause,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/run-1/pause", nil)
+	req.SetPathValue("id", "run-1")
+	rec := httptest.NewRecorder()
+
+	h.handlePauseSession(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPauseAndUnpauseSession(t *testing.T) {
+	meta := &session.Metadata{ID: "sched-1", Skill: "orchestrator", Schedule: "0 9 * * *", Status: session.StatusScheduled}
+	var actions []string
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return meta, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			pause: func(meta *session.Metadata) error {
+				actions = append(actions, "pause")
+				return nil
+			},
+			unpause: func(meta *session.Metadata) error {
+				if meta.Status != session.StatusPaused {
+					return session.RequestUnpause(meta)
+				}
+				actions = append(actions, "unpause")
+				return nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/sched-1/unpause", nil)
+	req.SetPathValue("id", "sched-1")
+	rec := httptest.NewRecorder()
+	h.handleUnpauseSession(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("unpause status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions/sched-1/pause", nil)
+	req.SetPathValue("id", "sched-1")
+	rec = httptest.NewRecorder()
+	h.handlePauseSession(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("pause status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	meta.Status = session.StatusPaused
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions/sched-1/unpause", nil)
+	req.SetPathValue("id", "sched-1")
+	rec = httptest.NewRecorder()
+	h.handleUnpauseSession(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unpause status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	if strings.Join(actions, ",") != "pause,unpause" {
+		t.Fatalf("actions = %v, want [pause unpause]", actions)
+	}
+}
+
+func TestDeleteSessionRejectsPausedSession(t *testing.T) {
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{ID: id, Skill: "orchestrator", Schedule: "@daily", Status: session.StatusPaused}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/sessions/sched-1", nil)
+	req.SetPathValue("id", "sched-1")
+	rec := httptest.NewRecorder()
+
+	h.handleDeleteSession(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestListConfigs(t *testing.T) {
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			discoverConfigs: func(repoRoot string) ([]string, error) {
+				return []string{"/repo/skill-loop.yml", "/repo/skill-loop-broken.yml"}, nil
+			},
+			loadConfig: func(path string, _ config.LoadOptions) (*config.Config, error) {
+				if strings.Contains(path, "broken") {
+					return nil, errors.New("default_entrypoint is required")
+				}
+				return &config.Config{
+					Name:              "review",
+					DefaultEntrypoint: "impl",
+					MaxIterations:     5,
+					Skills:            map[string]config.Skill{"review": {}, "impl": {}},
+				}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/configs", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleListConfigs(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got configsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Configs) != 2 {
+		t.Fatalf("configs = %d, want 2", len(got.Configs))
+	}
+	if got.Configs[0].RelativePath != "skill-loop.yml" || strings.Join(got.Configs[0].Entrypoints, ",") != "impl,review" {
+		t.Fatalf("config = %+v, want relative path and sorted entrypoints", got.Configs[0])
+	}
+	if got.Configs[1].Error == "" {
+		t.Fatalf("broken config = %+v, want load error", got.Configs[1])
+	}
+}
+
+func TestStartRun(t *testing.T) {
+	var gotPath string
+	var gotOpts launch.Options
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			discoverConfigs: func(repoRoot string) ([]string, error) {
+				return []string{"/repo/skill-loop.yml"}, nil
+			},
+			loadConfig: func(path string, _ config.LoadOptions) (*config.Config, error) {
+				return &config.Config{DefaultEntrypoint: "impl", Skills: map[string]config.Skill{"impl": {}, "review": {}}}, nil
+			},
+			startRun: func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error) {
+				gotPath = cfgPath
+				gotOpts = opts
+				return &session.Metadata{ID: "run-1", Skill: "orchestrator", Status: session.StatusPending}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/runs", strings.NewReader(`{"configPath":"skill-loop.yml","entrypoint":"review","prompt":" focus on tests ","maxIterations":3,"backend":"process"}`))
+	rec := httptest.NewRecorder()
+
+	h.handleStartRun(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if gotPath != "/repo/skill-loop.yml" {
+		t.Fatalf("config path = %q, want /repo/skill-loop.yml", gotPath)
+	}
+	if gotOpts.Entrypoint != "review" || gotOpts.Prompt != "focus on tests" || gotOpts.MaxIterations != 3 || gotOpts.Backend != session.BackendProcess {
+		t.Fatalf("options = %+v, want review entrypoint, trimmed prompt, 3 iterations and the process backend", gotOpts)
+	}
+}
+
+func TestDiscoveredConfigsAreCached(t *testing.T) {
+	discovered := []string{"/repo/skill-loop.yml"}
+	discoveries := 0
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			discoverConfigs: func(repoRoot string) ([]string, error) {
+				discoveries++
+				return discovered, nil
+			},
+			loadConfig: func(path string, _ config.LoadOptions) (*config.Config, error) {
+				return &config.Config{DefaultEntrypoint: "impl", Skills: map[string]config.Skill{"impl": {}}}, nil
+			},
+			startRun: func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error) {
+				return &session.Metadata{ID: "run-1", Skill: "orchestrator", Status: session.StatusPending}, nil
+			},
+		},
+	}
+	startRun := func(body string) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.handleStartRun(rec, httptest.NewRequest(http.MethodPost, "/api/runs", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	h.handleListConfigs(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/configs", nil))
+	if code := startRun(`{"configPath":"skill-loop.yml"}`); code != http.StatusCreated {
+		t.Fatalf("start status = %d, want %d", code, http.StatusCreated)
+	}
+	if discoveries != 1 {
+		t.Fatalf("discoveries = %d, want the listed configs reused", discoveries)
+	}
+
+	// A config created after the cached discovery is found by discovering again.
+	discovered = append(discovered, "/repo/skill-loop-new.yml")
+	if code := startRun(`{"configPath":"skill-loop-new.yml"}`); code != http.StatusCreated {
+		t.Fatalf("start status = %d, want %d", code, http.StatusCreated)
+	}
+	if discoveries != 2 {
+		t.Fatalf("discoveries = %d, want a fresh discovery for the new config", discoveries)
+	}
+}
+
+func TestStartRunRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "undiscovered config", body: `{"configPath":"/etc/other.yml"}`},
+		{name: "unknown entrypoint", body: `{"configPath":"/repo/skill-loop.yml","entrypoint":"deploy"}`},
+		{name: "negative iterations", body: `{"configPath":"/repo/skill-loop.yml","maxIterations":-1}`},
+		{name: "unknown backend", body: `{"configPath":"/repo/skill-loop.yml","backend":"screen"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &handler{
+				repoRoot: "/repo",
+				store: sessionStore{
+					discoverConfigs: func(repoRoot string) ([]string, error) {
+						return []string{"/repo/skill-loop.yml"}, nil
+					},
+					loadConfig: func(path string, _ config.LoadOptions) (*config.Config, error) {
+						return &config.Config{DefaultEntrypoint: "impl", Skills: map[string]config.Skill{"impl": {}}}, nil
+					},
+					startRun: func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error) {
+						t.Fatal("run should not start")
+						return nil, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/runs", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.handleStartRun(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestStreamLogFollowsAppendedOutput(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "stdout.log")
+	if err := os.WriteFile(logPath, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	server := newStreamServer(t, logPath)
+	events := openEventStream(t, server.URL+"/api/sessions/run-1/logs/stdout/events", "")
+
+	if event, data := events.next(t); event != "status" || !strings.Contains(data, `"status":"running"`) {
+		t.Fatalf("first event = %s %s, want running status", event, data)
+	}
+	if got := events.nextLog(t); got.Content != "first\n" || got.Offset != 6 {
+		t.Fatalf("log event = %+v, want existing content at offset 6", got)
+	}
+
+	appendFile(t, logPath, "second\n")
+	if got := events.nextLog(t); got.Content != "second\n" || got.Offset != 13 {
+		t.Fatalf("log event = %+v, want appended content at offset 13", got)
+	}
+
+	resumed := openEventStream(t, server.URL+"/api/sessions/run-1/logs/stdout/events", "6")
+	if got := resumed.nextLog(t); got.Content != "second\n" {
+		t.Fatalf("resumed log event = %+v, want content after offset 6", got)
+	}
+}
+
+func TestStreamLogStatusDoesNotReconcile(t *testing.T) {
+	previous := logStreamStatusInterval
+	logStreamStatusInterval = 10 * time.Millisecond
+	t.Cleanup(func() { logStreamStatusInterval = previous })
+
+	logPath := filepath.Join(t.TempDir(), "stdout.log")
+	var mu sync.Mutex
+	status := session.StatusRunning
+	reconciled := 0
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return &session.Metadata{ID: id, Skill: "orchestrator", Status: status, StdoutPath: logPath}, nil
+			},
+			reconcile: func(meta *session.Metadata) error {
+				mu.Lock()
+				defer mu.Unlock()
+				reconciled++
+				return nil
+			},
+			openFile: openLogFile,
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/sessions/{id}/logs/{stream}/events", h.handleStreamLog)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	events := openEventStream(t, server.URL+"/api/sessions/run-1/logs/stdout/events", "")
+	if event, data := events.next(t); event != "status" || !strings.Contains(data, `"status":"running"`) {
+		t.Fatalf("first event = %s %s, want running status", event, data)
+	}
+
+	mu.Lock()
+	status = session.StatusDone
+	mu.Unlock()
+	if event, data := events.next(t); event != "status" || !strings.Contains(data, `"status":"done"`) {
+		t.Fatalf("next event = %s %s, want done status", event, data)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if reconciled != 1 {
+		t.Fatalf("reconcile calls = %d, want only the one when the stream opened", reconciled)
+	}
+}
+
+func TestStreamLogRejectsInvalidOffset(t *testing.T) {
+	server := newStreamServer(t, filepath.Join(t.TempDir(), "stdout.log"))
+
+	resp, err := http.Get(server.URL + "/api/sessions/run-1/logs/stdout/events?offset=-1")
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestTailOffset(t *testing.T) {
+	var content strings.Builder
+	for i := range logStreamTailLines + 5 {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	h := &handler{store: sessionStore{openFile: func(path string) (io.ReadSeekCloser, error) {
+		return nopSeekCloser{strings.NewReader(content.String())}, nil
+	}}}
+
+	offset, err := h.tailOffset("stdout.log")
+	if err != nil {
+		t.Fatalf("tailOffset() error: %v", err)
+	}
+	if got := content.String()[offset:]; !strings.HasPrefix(got, "line 5\n") {
+		t.Fatalf("tail starts with %q, want line 5", got[:min(len(got), 10)])
+	}
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
+
+func TestCompleteRunesKeepsSplitCharacterForNextRead(t *testing.T) {
+	data := []byte("ok ✓")
+	if got := string(completeRunes(data[:len(data)-1])); got != "ok " {
+		t.Fatalf("completeRunes() = %q, want %q", got, "ok ")
+	}
+	if got := string(completeRunes(data)); got != "ok ✓" {
+		t.Fatalf("completeRunes() = %q, want %q", got, "ok ✓")
+	}
+}
+
+func TestGetGraphHighlightsSession(t *testing.T) {
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{
+					ID:           id,
+					Skill:        "orchestrator",
+					ConfigPath:   "/repo/skill-loop.yml",
+					Status:       session.StatusRunning,
+					CurrentSkill: "review",
+					Routes:       []session.RouteStep{{Skill: "impl", Route: "review"}},
+				}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			loadConfig: func(path string, _ config.LoadOptions) (*config.Config, error) {
+				return &config.Config{
+					Name:              "review-loop",
+					DefaultEntrypoint: "impl",
+					Skills: map[string]config.Skill{
+						"impl":   {Next: []config.Route{{ID: "review", Skill: "review"}}},
+						"review": {Next: []config.Route{{ID: "approve", Done: true}}},
+					},
+				}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/run-1/graph", nil)
+	req.SetPathValue("id", "run-1")
+	rec := httptest.NewRecorder()
+
+	h.handleGetGraph(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var got graph.Graph
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Name != "review-loop" || len(got.Nodes) != 4 {
+		t.Fatalf("graph = %+v, want review-loop with start, impl, review and done", got)
+	}
+	for _, node := range got.Nodes {
+		if node.Current != (node.ID == "review") {
+			t.Fatalf("node %s current = %v, want only review current", node.ID, node.Current)
+		}
+	}
+	for _, edge := range got.Edges {
+		if want := edge.Route != "approve"; edge.Traversed != want {
+			t.Fatalf("edge %s->%s traversed = %v, want %v", edge.From, edge.To, edge.Traversed, want)
+		}
+	}
+}
+
+type eventStream struct {
+	events chan [2]string
+}
+
+func newStreamServer(t *testing.T, logPath string) *httptest.Server {
+	t.Helper()
+
+	previous := logStreamPollInterval
+	logStreamPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { logStreamPollInterval = previous })
+
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				return &session.Metadata{
+					ID:         id,
+					Skill:      "orchestrator",
+					Status:     session.StatusRunning,
+					StdoutPath: logPath,
+				}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			readFile:  os.ReadFile,
+			openFile:  openLogFile,
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/sessions/{id}/logs/{stream}/events", h.handleStreamLog)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openEventStream(t *testing.T, url string, lastEventID string) *eventStream {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	stream := &eventStream{events: make(chan [2]string, 16)}
+	go func() {
+		defer func() { _ = resp.Body.Close() }()
+		var event string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				event = value
+			} else if value, ok := strings.CutPrefix(line, "data: "); ok {
+				stream.events <- [2]string{event, value}
+			}
+		}
+		close(stream.events)
+	}()
+	return stream
+}
+
+func (s *eventStream) next(t *testing.T) (string, string) {
+	t.Helper()
+
+	select {
+	case event, ok := <-s.events:
+		if !ok {
+			t.Fatal("event stream closed")
+		}
+		return event[0], event[1]
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return "", ""
+}
+
+func (s *eventStream) nextLog(t *testing.T) logEvent {
+	t.Helper()
+
+	for {
+		event, data := s.next(t)
+		if event != "log" {
+			continue
+		}
+		var got logEvent
+		if err := json.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("decode log event: %v", err)
+		}
+		return got
+	}
+}
+
+func appendFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("append log: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("close log: %v", err)
+	}
+}
+
+func TestListSessionsAppliesQueryFilters(t *testing.T) {
+	now := time.Now().UTC()
+	var listedRepo string
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			list: func(repoRoot string) ([]*session.Metadata, error) {
+				listedRepo = repoRoot
+				return []*session.Metadata{
+					{ID: "run-1", Skill: "orchestrator", RepoRoot: "/repo", Status: session.StatusFailed, StartedAt: now, LastError: "agent timed out"},
+					{ID: "run-2", Skill: "orchestrator", RepoRoot: "/other", Status: session.StatusFailed, StartedAt: now.Add(-48 * time.Hour), LastError: "agent timed out"},
+					{ID: "run-3", Skill: "orchestrator", RepoRoot: "/repo", Status: session.StatusDone, StartedAt: now},
+				}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions?status=failed,stopped&search=TIMED&since=24h&allRepos=true", nil)
+	rec := httptest.NewRecorder()
+	h.handleListSessions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if listedRepo != "" {
+		t.Fatalf("listed repo = %q, want every repo", listedRepo)
+	}
+	var got SessionList
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !got.AllRepos || got.Total != 1 || len(got.Sessions) != 1 || got.Sessions[0].ID != "run-1" {
+		t.Fatalf("response = %+v, want only run-1 across repos", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/sessions?status=finished", nil)
+	rec = httptest.NewRecorder()
+	h.handleListSessions(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSessionOfAnotherRepoIsOnlyViewed(t *testing.T) {
+	deleted := false
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			load: func(repoRoot, id string) (*session.Metadata, error) {
+				if repoRoot != "" {
+					return nil, os.ErrNotExist
+				}
+				return &session.Metadata{ID: id, Skill: "orchestrator", RepoRoot: "/other", Status: session.StatusDone}, nil
+			},
+			reconcile: func(meta *session.Metadata) error { return nil },
+			deleteByID: func(repoRoot, id string) error {
+				deleted = true
+				return nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/run-2?allRepos=true", nil)
+	req.SetPathValue("id", "run-2")
+	rec := httptest.NewRecorder()
+	h.handleGetSession(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get status with allRepos = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/sessions/run-2", nil)
+	req.SetPathValue("id", "run-2")
+	rec = httptest.NewRecorder()
+	h.handleGetSession(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("get status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/sessions/run-2?allRepos=true", nil)
+	req.SetPathValue("id", "run-2")
+	rec = httptest.NewRecorder()
+	h.handleDeleteSession(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if deleted {
+		t.Fatal("deleted a session of another repo")
+	}
+}
+
+func TestListSessionsPagesAndReconcilesActiveSessions(t *testing.T) {
+	now := time.Now().UTC()
+	var reconciled []string
+	h := &handler{
+		repoRoot: "/repo",
+		store: sessionStore{
+			list: func(repoRoot string) ([]*session.Metadata, error) {
+				return []*session.Metadata{
+					{ID: "run-1", Skill: "orchestrator", Status: session.StatusRunning, StartedAt: now},
+					{ID: "run-2", Skill: "orchestrator", Status: session.StatusDone, StartedAt: now},
+					{ID: "run-3", Skill: "orchestrator", Status: session.StatusScheduled, StartedAt: now},
+					{ID: "run-4", Skill: "orchestrator", Status: session.StatusFailed, StartedAt: now},
+				}, nil
+			},
+			reconcile: func(meta *session.Metadata) error {
+				reconciled = append(reconciled, meta.ID)
+				return nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions?offset=1&limit=2", nil)
+	rec := httptest.NewRecorder()
+	h.handleListSessions(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got SessionList
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Total != 4 || len(got.Sessions) != 2 || got.Sessions[0].ID != "run-2" || got.Sessions[1].ID != "run-3" {
+		t.Fatalf("response = %+v, want run-2 and run-3 of 4 sessions", got)
+	}
+	if strings.Join(reconciled, ",") != "run-1,run-3" {
+		t.Fatalf("reconciled = %v, want only the active sessions", reconciled)
+	}
+
+	for _, query := range []string{"offset=-1", "limit=many"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions?"+query, nil)
+		rec := httptest.NewRecorder()
+		h.handleListSessions(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGuardRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		host        string
+		localAddr   string
+		origin      string
+		contentType string
+		want        int
+	}{
+		{name: "get without headers", method: http.MethodGet, want: http.StatusOK},
+		{name: "same-origin json post", method: http.MethodPost, origin: "http://127.0.0.1:8080", contentType: "application/json", want: http.StatusOK},
+		{name: "json post without origin", method: http.MethodPost, contentType: "application/json; charset=utf-8", want: http.StatusOK},
+		{name: "localhost json post", method: http.MethodPost, host: "localhost:8080", origin: "http://localhost:8080", contentType: "application/json", want: http.StatusOK},
+		{name: "ipv6 loopback json post", method: http.MethodPost, host: "[::1]:8080", origin: "http://[::1]:8080", contentType: "application/json", want: http.StatusOK},
+		{name: "bound address json post", method: http.MethodPost, host: "192.168.1.5:8080", localAddr: "192.168.1.5", origin: "http://192.168.1.5:8080", contentType: "application/json", want: http.StatusOK},
+		{name: "cross-origin post", method: http.MethodPost, origin: "http://evil.test", contentType: "application/json", want: http.StatusForbidden},
+		{name: "rebound host post", method: http.MethodPost, host: "evil.test:8080", origin: "http://evil.test:8080", contentType: "application/json", want: http.StatusForbidden},
+		{name: "rebound host get", method: http.MethodGet, host: "evil.test:8080", want: http.StatusForbidden},
+		{name: "other address post", method: http.MethodPost, host: "10.0.0.7:8080", localAddr: "192.168.1.5", origin: "http://10.0.0.7:8080", contentType: "application/json", want: http.StatusForbidden},
+		{name: "form post", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", want: http.StatusUnsupportedMediaType},
+		{name: "plain text post", method: http.MethodPost, origin: "http://127.0.0.1:8080", contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{name: "delete without content type", method: http.MethodDelete, want: http.StatusUnsupportedMediaType},
+	}
+
+	handler := guardRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/runs", strings.NewReader(`{}`))
+			req.Host = "127.0.0.1:8080"
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.localAddr != "" {
+				local := &net.TCPAddr{IP: net.ParseIP(tt.localAddr), Port: 8080}
+				req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, local))
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package sessionui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+const (
+	// logStreamTailLines is how much existing output a new stream starts with.
+	logStreamTailLines = 400
+	// logStreamChunkBytes bounds the content carried by a single log event.
+	logStreamChunkBytes = 64 << 10
+)
+
+var (
+	logStreamPollInterval   = 500 * time.Millisecond
+	logStreamStatusInterval = 2 * time.Second
+	logStreamKeepAlive      = 15 * time.Second
+)
+
+// logEvent is the payload of a "log" server-sent event. Offset is the byte
+// offset just past Content and doubles as the event ID, so a reconnecting
+// EventSource resumes from where it left off via Last-Event-ID. Reset is set
+// when the file was truncated or replaced and the client should discard what
+// it has.
+type logEvent struct {
+	Path    string `json:"path"`
+	Offset  int64  `json:"offset"`
+	Content string `json:"content"`
+	Reset   bool   `json:"reset,omitempty"`
+}
+
+type eventWriter struct {
+	w           io.Writer
+	rc          *http.ResponseController
+	lastWriteAt time.Time
+}
+
+// handleStreamLog follows a session log with server-sent events. "log" events
+// carry new output as it is appended and "status" events carry the session
+// whenever its status or detail changes. The stream starts with the last
+// logStreamTailLines lines unless the client asks for a byte offset with
+// ?offset= or Last-Event-ID.
+func (h *handler) handleStreamLog(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	allRepos, err := parseAllRepos(r.URL.Query())
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	meta, err := h.loadRunSession(id, allRepos)
+	if err != nil {
+		h.writeSessionError(w, err)
+		return
+	}
+
+	logPath, ok := h.resolveLogPath(w, meta, r.PathValue("stream"), r.URL.Query().Get("run"))
+	if !ok {
+		return
+	}
+
+	offset, hasOffset, err := requestedLogOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !hasOffset {
+		offset, err = h.tailOffset(logPath)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	events := &eventWriter{w: w, rc: http.NewResponseController(w)}
+	if err := events.comment("connected"); err != nil {
+		return
+	}
+
+	// Status updates only read the metadata: the processes of the session
+	// keep it current, and reconciling it is left to the other requests of
+	// the dashboard, so that an open stream never writes to the session.
+	var lastStatus []byte
+	sendStatus := func() bool {
+		meta, err := h.findRunSession(id, allRepos)
+		if err != nil {
+			// The session was deleted or became unreadable; let the client
+			// decide whether to reconnect.
+			return false
+		}
+		data, err := json.Marshal(NewSession(meta))
+		if err != nil || bytes.Equal(data, lastStatus) {
+			return err == nil
+		}
+		lastStatus = data
+		return events.send("status", "", data) == nil
+	}
+
+	if !sendStatus() {
+		return
+	}
+
+	poll := time.NewTicker(logStreamPollInterval)
+	defer poll.Stop()
+	status := time.NewTicker(logStreamStatusInterval)
+	defer status.Stop()
+
+	for {
+		offset, err = h.sendLogChunks(events, logPath, offset)
+		if err != nil {
+			return
+		}
+		if time.Since(events.lastWriteAt) >= logStreamKeepAlive {
+			if err := events.comment("keep-alive"); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-status.C:
+			if !sendStatus() {
+				return
+			}
+		case <-poll.C:
+		}
+	}
+}
+
+// sendLogChunks sends everything appended to path since offset and returns the
+// new offset. A missing file is treated as empty.
+func (h *handler) sendLogChunks(events *eventWriter, path string, offset int64) (int64, error) {
+	file, err := h.store.openFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return offset, nil
+		}
+		return offset, err
+	}
+	defer func() { _ = file.Close() }()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return offset, err
+	}
+
+	reset := false
+	if size < offset {
+		offset = 0
+		reset = true
+	}
+	if size == offset && !reset {
+		return offset, nil
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	buf := make([]byte, logStreamChunkBytes)
+	for offset < size || reset {
+		n, err := io.ReadFull(file, buf[:min(int64(len(buf)), size-offset)])
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return offset, err
+		}
+		chunk := completeRunes(buf[:n])
+		if len(chunk) == 0 && !reset {
+			// Only part of a multi-byte character has been written so far.
+			return offset, nil
+		}
+		if n > len(chunk) {
+			if _, err := file.Seek(offset+int64(len(chunk)), io.SeekStart); err != nil {
+				return offset, err
+			}
+		}
+
+		start := offset
+		offset += int64(len(chunk))
+		data, err := json.Marshal(logEvent{Path: path, Offset: offset, Content: string(chunk), Reset: reset})
+		if err != nil {
+			return offset, err
+		}
+		if err := events.send("log", strconv.FormatInt(offset, 10), data); err != nil {
+			return offset, err
+		}
+		reset = false
+		if len(chunk) < n && start+int64(n) == size {
+			break
+		}
+	}
+	return offset, nil
+}
+
+// tailOffset returns the byte offset where the last logStreamTailLines lines
+// of path begin.
+func (h *handler) tailOffset(path string) (int64, error) {
+	file, err := h.store.openFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	return session.TailOffset(file, 0, logStreamTailLines)
+}
+
+func requestedLogOffset(r *http.Request) (int64, bool, error) {
+	value := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if value == "" {
+		value = strings.TrimSpace(r.URL.Query().Get("offset"))
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, false, fmt.Errorf("invalid log offset %q", value)
+	}
+	return offset, true, nil
+}
+
+// completeRunes drops a trailing partial UTF-8 sequence so that a character
+// split across two reads is sent whole with the next event.
+func completeRunes(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if !utf8.FullRune(b[i:]) {
+			return b[:i]
+		}
+		break
+	}
+	return b
+}
+
+func openLogFile(path string) (io.ReadSeekCloser, error) {
+	return os.Open(path)
+}
+
+func (e *eventWriter) send(event string, id string, data []byte) error {
+	var b strings.Builder
+	b.WriteString("event: ")
+	b.WriteString(event)
+	b.WriteString("\n")
+	if id != "" {
+		b.WriteString("id: ")
+		b.WriteString(id)
+		b.WriteString("\n")
+	}
+	b.WriteString("data: ")
+	b.Write(data)
+	b.WriteString("\n\n")
+	return e.write(b.String())
+}
+
+func (e *eventWriter) comment(text string) error {
+	return e.write(": " + text + "\n\n")
+}
+
+func (e *eventWriter) write(s string) error {
+	if _, err := io.WriteString(e.w, s); err != nil {
+		return err
+	}
+	e.lastWriteAt = time.Now()
+	return e.rc.Flush()
+}
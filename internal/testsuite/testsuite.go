@@ -0,0 +1,240 @@
+// Package testsuite runs workflow test cases written in YAML. Each case gives
+// the output of the skill run in every iteration and the route chosen after
+// it, and expects the routes the workflow selects and how it ends, so router
+// criteria and graph changes can be checked in CI without calling agents.
+package testsuite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/cassette"
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+	"github.com/takumiyoshikawa/skill-loop/internal/orchestrator"
+)
+
+// Suite is a test file: the workflow under test and its cases.
+type Suite struct {
+	// Config is the workflow path, relative to the suite file.
+	Config string `yaml:"config"`
+	Tests  []Case `yaml:"tests"`
+}
+
+// Case is one test: how the run starts, what the agents answer in each
+// iteration and the expected outcome.
+type Case struct {
+	Name          string            `yaml:"name"`
+	Prompt        string            `yaml:"prompt,omitempty"`
+	Entrypoint    string            `yaml:"entrypoint,omitempty"`
+	MaxIterations int               `yaml:"max_iterations,omitempty"`
+	Vars          map[string]string `yaml:"vars,omitempty"`
+	Given         []Iteration       `yaml:"given"`
+	Expect        Expect            `yaml:"expect"`
+}
+
+// Iteration mocks one iteration. Skill, when set, is the skill the iteration
+// must run. Output is its stdout, or Error makes it fail. Route and Reason are
+// the router's answer; skills with a single route do not need them.
+type Iteration struct {
+	Skill  string `yaml:"skill,omitempty"`
+	Output string `yaml:"output,omitempty"`
+	Error  string `yaml:"error,omitempty"`
+	Route  string `yaml:"route,omitempty"`
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Expect is the outcome a case must reproduce. Routes are the ids of the
+// selected routes in order and are not checked when omitted. Status is done,
+// blocked or failed and defaults to done. Reason, when set, is the expected
+// blocked reason.
+type Expect struct {
+	Routes []string `yaml:"routes"`
+	Status string   `yaml:"status"`
+	Reason string   `yaml:"reason"`
+}
+
+// Result is the outcome of one case. Failures is empty when the case passed.
+type Result struct {
+	Name     string
+	Failures []string
+}
+
+// Passed reports whether the case reproduced its expectation.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// IsSuite reports whether the YAML file at path is a test suite, that is, has
+// a top-level tests key.
+func IsSuite(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read test file: %w", err)
+	}
+	var keys map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return false, fmt.Errorf("parse test file %s: %w", path, err)
+	}
+	_, ok := keys["tests"]
+	return ok, nil
+}
+
+// Load reads the suite at path.
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read test suite: %w", err)
+	}
+	var suite Suite
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("parse test suite %s: %w", path, err)
+	}
+	if suite.Config == "" {
+		return nil, fmt.Errorf("test suite %s: config is required", path)
+	}
+	for i, tc := range suite.Tests {
+		if strings.TrimSpace(tc.Name) == "" {
+			return nil, fmt.Errorf("test suite %s: tests[%d]: name is required", path, i)
+		}
+		switch tc.Expect.Status {
+		case "", cassette.StatusDone, cassette.StatusBlocked, cassette.StatusFailed:
+		default:
+			return nil, fmt.Errorf("test suite %s: test %q: unknown status %q (want %s, %s or %s)", path, tc.Name, tc.Expect.Status, cassette.StatusDone, cassette.StatusBlocked, cassette.StatusFailed)
+		}
+	}
+	return &suite, nil
+}
+
+// ConfigPath returns the workflow path of a suite loaded from path.
+func (s *Suite) ConfigPath(path string) string {
+	if filepath.IsAbs(s.Config) {
+		return s.Config
+	}
+	return filepath.Join(filepath.Dir(path), s.Config)
+}
+
+// Run runs every case of the suite at path. Tracker context and actions are
+// skipped. Progress is written to out.
+func Run(path string, out io.Writer) ([]*Result, error) {
+	suite, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(suite.Tests))
+	for _, tc := range suite.Tests {
+		result, err := runCase(suite.ConfigPath(path), tc, out)
+		if err != nil {
+			result = &Result{Name: tc.Name, Failures: []string{err.Error()}}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runCase(cfgPath string, tc Case, out io.Writer) (*Result, error) {
+	cfg, err := config.LoadWithOptions(cfgPath, config.LoadOptions{Vars: tc.Vars})
+	if err != nil {
+		return nil, err
+	}
+	cfg.Tracker = nil
+
+	fmt.Fprintf(out, "=== RUN %s\n", tc.Name)
+	exec := &fakeExecutor{given: tc.Given}
+	observer := &routeObserver{}
+	runErr := orchestrator.RunWithOptions(cfg, orchestrator.RunOptions{
+		MaxIterations: tc.MaxIterations,
+		Prompt:        tc.Prompt,
+		Entrypoint:    tc.Entrypoint,
+		Executor:      exec,
+		Observer:      observer,
+		Output:        out,
+	})
+	if runErr != nil {
+		fmt.Fprintf(out, "==> Run ended: %v\n", runErr)
+	}
+
+	got := cassette.Outcome(nil, runErr)
+	want := tc.Expect
+	if want.Status == "" {
+		want.Status = cassette.StatusDone
+	}
+
+	result := &Result{Name: tc.Name}
+	if want.Routes != nil && !slices.Equal(want.Routes, observer.routes) {
+		result.Failures = append(result.Failures, fmt.Sprintf("routes = %s, want %s", formatRoutes(observer.routes), formatRoutes(want.Routes)))
+	}
+	if got.Status != want.Status {
+		detail := ""
+		if got.Error != "" {
+			detail = " (" + got.Error + ")"
+		}
+		result.Failures = append(result.Failures, fmt.Sprintf("status = %s%s, want %s", got.Status, detail, want.Status))
+	} else if want.Reason != "" && got.Reason != want.Reason {
+		result.Failures = append(result.Failures, fmt.Sprintf("blocked reason = %q, want %q", got.Reason, want.Reason))
+	}
+	if unused := len(tc.Given) - exec.next; unused > 0 && result.Passed() {
+		result.Failures = append(result.Failures, fmt.Sprintf("%d given iteration(s) were not used", unused))
+	}
+	return result, nil
+}
+
+func formatRoutes(routes []string) string {
+	if len(routes) == 0 {
+		return "(none)"
+	}
+	return strings.Join(routes, " -> ")
+}
+
+// fakeExecutor answers the orchestrator from the given iterations in order.
+type fakeExecutor struct {
+	given []Iteration
+	next  int
+}
+
+func (e *fakeExecutor) ExecuteSkill(name string, agent config.Agent, input string, opts executor.ExecutionOptions) (*executor.SkillResult, error) {
+	if e.next >= len(e.given) {
+		return nil, fmt.Errorf("no given output for iteration %d", e.next+1)
+	}
+	iteration := e.given[e.next]
+	e.next++
+	if iteration.Skill != "" && iteration.Skill != name {
+		return nil, fmt.Errorf("iteration %d ran skill %q, given expects %q", e.next, name, iteration.Skill)
+	}
+	if iteration.Error != "" {
+		return nil, errors.New(iteration.Error)
+	}
+	return &executor.SkillResult{Stdout: iteration.Output}, nil
+}
+
+func (e *fakeExecutor) RouteSkillOutput(skillName string, router config.Agent, output string, routes []config.Route, opts executor.ExecutionOptions) (*executor.RouterDecision, error) {
+	iteration := e.given[e.next-1]
+	if iteration.Route == "" {
+		return nil, fmt.Errorf("iteration %d: skill %q has several routes, so given needs a route", e.next, skillName)
+	}
+	return &executor.RouterDecision{Route: iteration.Route, Reason: iteration.Reason}, nil
+}
+
+// routeObserver collects the ids of the selected routes.
+type routeObserver struct {
+	routes []string
+}
+
+func (o *routeObserver) IterationStarted(iteration int, maxIterations int, skill string) {}
+
+func (o *routeObserver) SkillCompleted(iteration int, maxIterations int, skill string, stdout string) {
+}
+
+func (o *routeObserver) RouteSelected(iteration int, skill string, route config.Route) {
+	o.routes = append(o.routes, route.ID)
+}
@@ -0,0 +1,168 @@
+package testsuite
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reviewLoop = `default_entrypoint: impl
+router:
+  runtime: codex
+skills:
+  impl:
+    next:
+      - id: review
+        criteria: "Implementation is ready"
+        skill: review
+  review:
+    next:
+      - id: approve
+        criteria: "No blocking issues"
+        done: true
+      - id: rework
+        criteria: "Changes requested"
+        skill: impl
+      - id: escalate
+        criteria: "Needs a product decision"
+        skill: impl
+        blocked: true
+`
+
+func writeSuite(t *testing.T, suite string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "skill-loop.yml"), []byte(reviewLoop), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "skill-loop.test.yml")
+	if err := os.WriteFile(path, []byte(suite), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPassingSuite(t *testing.T) {
+	path := writeSuite(t, `config: skill-loop.yml
+tests:
+  - name: approves after one rework
+    given:
+      - skill: impl
+        output: Added /healthz
+      - skill: review
+        output: Missing tests
+        route: rework
+      - output: Added tests
+      - output: LGTM
+        route: approve
+    expect:
+      routes: [review, rework, review, approve]
+      status: done
+  - name: escalates product questions
+    entrypoint: review
+    given:
+      - output: Should /healthz be public?
+        route: escalate
+        reason: needs a product decision
+    expect:
+      routes: [escalate]
+      status: blocked
+      reason: needs a product decision
+  - name: fails when the skill fails
+    given:
+      - error: agent crashed
+    expect:
+      routes: []
+      status: failed
+`)
+
+	if ok, err := IsSuite(path); err != nil || !ok {
+		t.Fatalf("IsSuite() = %v, %v, want true", ok, err)
+	}
+	results, err := Run(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("len(Run()) = %d, want 3", len(results))
+	}
+	for _, result := range results {
+		if !result.Passed() {
+			t.Fatalf("test %q failures = %q, want none", result.Name, result.Failures)
+		}
+	}
+}
+
+func TestRunReportsFailures(t *testing.T) {
+	path := writeSuite(t, `config: skill-loop.yml
+tests:
+  - name: wrong routes
+    given:
+      - output: Added /healthz
+      - output: LGTM
+        route: approve
+    expect:
+      routes: [review, rework]
+  - name: wrong skill
+    given:
+      - skill: review
+        output: LGTM
+  - name: missing route
+    given:
+      - output: Added /healthz
+      - output: LGTM
+  - name: unused iterations
+    given:
+      - output: Added /healthz
+      - output: LGTM
+        route: approve
+      - output: extra
+  - name: wrong reason
+    entrypoint: review
+    given:
+      - route: escalate
+        reason: waiting for design
+    expect:
+      status: blocked
+      reason: needs a product decision
+`)
+
+	results, err := Run(path, io.Discard)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	want := map[string]string{
+		"wrong routes":      "routes = review -> approve, want review -> rework",
+		"wrong skill":       `status = failed (skill "impl" failed: iteration 1 ran skill "impl", given expects "review"), want done`,
+		"missing route":     `skill "review" has several routes, so given needs a route`,
+		"unused iterations": "1 given iteration(s) were not used",
+		"wrong reason":      `blocked reason = "waiting for design", want "needs a product decision"`,
+	}
+	for _, result := range results {
+		if result.Passed() {
+			t.Fatalf("test %q passed, want a failure", result.Name)
+		}
+		if got := strings.Join(result.Failures, "\n"); !strings.Contains(got, want[result.Name]) {
+			t.Fatalf("test %q failures = %q, want them to contain %q", result.Name, got, want[result.Name])
+		}
+	}
+}
+
+func TestLoadRejectsInvalidSuite(t *testing.T) {
+	for name, tc := range map[string]struct {
+		suite string
+		want  string
+	}{
+		"missing config": {"tests: []\n", "config is required"},
+		"unknown key":    {"config: skill-loop.yml\ntests:\n  - name: a\n    expected: {}\n", "field expected not found"},
+		"unknown status": {"config: skill-loop.yml\ntests:\n  - name: a\n    expect: {status: finished}\n", `unknown status "finished"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(writeSuite(t, tc.suite)); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the REST API of github.com.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubOptions configure a GitHub tracker.
+type GitHubOptions struct {
+	Repo        string
+	APIURL      string
+	Token       string
+	ReadyLabels []string
+	ClaimLabel  string
+	Client      *http.Client
+}
+
+// GitHub is a Tracker backed by the GitHub REST API.
+type GitHub struct {
+	owner       string
+	repo        string
+	apiURL      string
+	token       string
+	readyLabels []string
+	claimLabel  string
+	client      *http.Client
+	login       string
+}
+
+// APIError is an unsuccessful response from the GitHub API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github %s %s: %s (%d)", e.Method, e.Path, e.Message, e.StatusCode)
+}
+
+// NewGitHub returns a tracker for opts.Repo, given as owner/repo.
+func NewGitHub(opts GitHubOptions) (*GitHub, error) {
+	owner, repo, ok := strings.Cut(strings.TrimSpace(opts.Repo), "/")
+	if !ok || owner == "" || repo == "" {
+		return nil, fmt.Errorf("github repo must be owner/repo, got %q", opts.Repo)
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(opts.APIURL), "/")
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &GitHub{
+		owner:       owner,
+		repo:        repo,
+		apiURL:      apiURL,
+		token:       opts.Token,
+		readyLabels: opts.ReadyLabels,
+		claimLabel:  opts.ClaimLabel,
+		client:      client,
+	}, nil
+}
+
+// GitHubToken returns the token for the GitHub API from GITHUB_TOKEN or
+// GH_TOKEN, falling back to the GitHub CLI's login (`gh auth token`).
+func GitHubToken(ctx context.Context) (string, error) {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, nil
+		}
+	}
+	out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output()
+	if err != nil || strings.TrimSpace(string(out)) == "" {
+		return "", fmt.Errorf("no GitHub token: set GITHUB_TOKEN or log in with gh auth login")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+func (i githubIssue) issue() Issue {
+	issue := Issue{Number: i.Number, Title: i.Title, Body: i.Body, URL: i.HTMLURL}
+	for _, label := range i.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	for _, assignee := range i.Assignees {
+		issue.Assignees = append(issue.Assignees, assignee.Login)
+	}
+	return issue
+}
+
+// Ready lists up to limit open, unassigned issues that carry every ready
+// label and not the claim label, oldest first. Pull requests are skipped.
+func (g *GitHub) Ready(ctx context.Context, limit int) ([]Issue, error) {
+	query := url.Values{"assignee": {"none"}}
+	if len(g.readyLabels) > 0 {
+		query.Set("labels", strings.Join(g.readyLabels, ","))
+	}
+	return g.listIssues(ctx, query, limit, func(issue Issue) bool {
+		return !hasLabel(issue.Labels, g.claimLabel)
+	})
+}
+
+// Claimed returns the oldest open issue assigned to the authenticated user
+// that carries the claim label.
+func (g *GitHub) Claimed(ctx context.Context) (*Issue, error) {
+	login, err := g.currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := g.listIssues(ctx, url.Values{"assignee": {login}, "labels": {g.claimLabel}}, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return &issues[0], nil
+}
+
+// Claim assigns the issue to the authenticated user and adds the claim label.
+func (g *GitHub) Claim(ctx context.Context, number int) error {
+	login, err := g.currentLogin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := g.do(ctx, http.MethodPost, g.issuePath(number)+"/assignees", nil, map[string][]string{"assignees": {login}}, nil); err != nil {
+		return err
+	}
+	return g.do(ctx, http.MethodPost, g.issuePath(number)+"/labels", nil, map[string][]string{"labels": {g.claimLabel}}, nil)
+}
+
+// Comment posts body as a comment on the issue.
+func (g *GitHub) Comment(ctx context.Context, number int, body string) error {
+	return g.do(ctx, http.MethodPost, g.issuePath(number)+"/comments", nil, map[string]string{"body": body}, nil)
+}
+
+// Close closes the issue as completed and removes the claim label.
+func (g *GitHub) Close(ctx context.Context, number int) error {
+	if err := g.do(ctx, http.MethodPatch, g.issuePath(number), nil, map[string]string{"state": "closed", "state_reason": "completed"}, nil); err != nil {
+		return err
+	}
+	err := g.do(ctx, http.MethodDelete, g.issuePath(number)+"/labels/"+url.PathEscape(g.claimLabel), nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		// The label was already removed.
+		return nil
+	}
+	return err
+}
+
+// listIssues lists the open issues matching query that keep accepts, oldest
+// first, following the pages of the response until it has limit issues or
+// reaches the last page. A zero limit lists every issue; a nil keep accepts
+// every issue.
+func (g *GitHub) listIssues(ctx context.Context, query url.Values, limit int, keep func(Issue) bool) ([]Issue, error) {
+	query.Set("state", "open")
+	query.Set("sort", "created")
+	query.Set("direction", "asc")
+	query.Set("per_page", "100")
+
+	path := g.repoPath() + "/issues"
+	target := g.apiURL + path + "?" + query.Encode()
+	var issues []Issue
+	for target != "" {
+		var raw []githubIssue
+		header, err := g.send(ctx, http.MethodGet, path, target, nil, &raw)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range raw {
+			if item.PullRequest != nil {
+				continue
+			}
+			issue := item.issue()
+			if keep != nil && !keep(issue) {
+				continue
+			}
+			issues = append(issues, issue)
+			if len(issues) == limit {
+				return issues, nil
+			}
+		}
+		target = nextPage(header.Get("Link"))
+		if target != "" && !strings.HasPrefix(target, g.apiURL+"/") {
+			return nil, fmt.Errorf("github %s %s: next page %s is outside %s", http.MethodGet, path, target, g.apiURL)
+		}
+	}
+	return issues, nil
+}
+
+// nextPage returns the URL of the next page in a Link header, or "" on the
+// last page.
+func nextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
+func (g *GitHub) currentLogin(ctx context.Context) (string, error) {
+	if g.login != "" {
+		return g.login, nil
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/user", nil, nil, &user); err != nil {
+		return "", err
+	}
+	if user.Login == "" {
+		return "", fmt.Errorf("github /user returned no login")
+	}
+	g.login = user.Login
+	return g.login, nil
+}
+
+func (g *GitHub) repoPath() string {
+	return "/repos/" + url.PathEscape(g.owner) + "/" + url.PathEscape(g.repo)
+}
+
+func (g *GitHub) issuePath(number int) string {
+	return g.repoPath() + "/issues/" + strconv.Itoa(number)
+}
+
+func (g *GitHub) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	target := g.apiURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	_, err := g.send(ctx, method, path, target, body, out)
+	return err
+}
+
+// send requests target, the URL of path, and decodes the response into out.
+// It returns the headers of the response.
+func (g *GitHub) send(ctx context.Context, method string, path string, target string, body any, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode github request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("build github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "skill-loop")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+	if out == nil {
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode github response for %s %s: %w", method, path, err)
+	}
+	return resp.Header, nil
+}
+
+func hasLabel(labels []string, name string) bool {
+	for _, label := range labels {
+		if strings.EqualFold(label, name) {
+			return true
+		}
+	}
+	return false
+}
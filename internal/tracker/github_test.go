@@ -0,0 +1,446 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+// fakeGitHub serves the subset of the GitHub issues API used by GitHub.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	login    string
+	issues   map[int]*githubIssue
+	closed   map[int]bool
+	comments map[int][]string
+	requests []string
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *GitHub) {
+	t.Helper()
+
+	fake := &fakeGitHub{
+		login:    "loop-bot",
+		issues:   make(map[int]*githubIssue),
+		closed:   make(map[int]bool),
+		comments: make(map[int][]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeJSON(w, map[string]string{"login": fake.login})
+	})
+	mux.HandleFunc("GET /repos/acme/api/issues", fake.handleList)
+	mux.HandleFunc("POST /repos/acme/api/issues/{number}/assignees", fake.handleAssign)
+	mux.HandleFunc("POST /repos/acme/api/issues/{number}/labels", fake.handleAddLabels)
+	mux.HandleFunc("DELETE /repos/acme/api/issues/{number}/labels/{label}", fake.handleRemoveLabel)
+	mux.HandleFunc("POST /repos/acme/api/issues/{number}/comments", fake.handleComment)
+	mux.HandleFunc("PATCH /repos/acme/api/issues/{number}", fake.handleUpdate)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeFakeJSON(w, map[string]string{"message": "Bad credentials"})
+			return
+		}
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, r.Method+" "+r.URL.Path)
+		fake.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	gh, err := NewGitHub(GitHubOptions{
+		Repo:        "acme/api",
+		APIURL:      server.URL,
+		Token:       "test-token",
+		ReadyLabels: []string{"ready"},
+		ClaimLabel:  "in-progress",
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error: %v", err)
+	}
+	return fake, gh
+}
+
+func (f *fakeGitHub) add(number int, title string, labels []string, assignees []string, pullRequest bool) {
+	issue := &githubIssue{Number: number, Title: title, Body: "Body of " + title, HTMLURL: "https://github.com/acme/api/issues/" + strconv.Itoa(number)}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, struct {
+			Name string `json:"name"`
+		}{label})
+	}
+	for _, login := range assignees {
+		issue.Assignees = append(issue.Assignees, struct {
+			Login string `json:"login"`
+		}{login})
+	}
+	if pullRequest {
+		issue.PullRequest = &struct{}{}
+	}
+	f.issues[number] = issue
+}
+
+func (f *fakeGitHub) handleList(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+	if query.Get("state") != "open" || query.Get("direction") != "asc" {
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+	var wantLabels []string
+	if labels := query.Get("labels"); labels != "" {
+		wantLabels = strings.Split(labels, ",")
+	}
+
+	var numbers []int
+	for number := range f.issues {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+
+	result := []githubIssue{}
+	for _, number := range numbers {
+		issue := f.issues[number]
+		if f.closed[number] {
+			continue
+		}
+		labels := issue.issue().Labels
+		assignees := issue.issue().Assignees
+		switch assignee := query.Get("assignee"); {
+		case assignee == "none" && len(assignees) > 0:
+			continue
+		case assignee != "" && assignee != "none" && !slices.Contains(assignees, assignee):
+			continue
+		}
+		matches := true
+		for _, label := range wantLabels {
+			if !slices.Contains(labels, label) {
+				matches = false
+			}
+		}
+		if matches {
+			result = append(result, *issue)
+		}
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	page := 1
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	start := min((page-1)*perPage, len(result))
+	end := min(start+perPage, len(result))
+	if end < len(result) {
+		query.Set("page", strconv.Itoa(page+1))
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, query.Encode()))
+	}
+	writeFakeJSON(w, result[start:end])
+}
+
+func (f *fakeGitHub) handleAssign(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Assignees []string `json:"assignees"`
+	}
+	issue, ok := f.decode(w, r, &body)
+	if !ok {
+		return
+	}
+	for _, login := range body.Assignees {
+		issue.Assignees = append(issue.Assignees, struct {
+			Login string `json:"login"`
+		}{login})
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeFakeJSON(w, issue)
+}
+
+func (f *fakeGitHub) handleAddLabels(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Labels []string `json:"labels"`
+	}
+	issue, ok := f.decode(w, r, &body)
+	if !ok {
+		return
+	}
+	for _, label := range body.Labels {
+		issue.Labels = append(issue.Labels, struct {
+			Name string `json:"name"`
+		}{label})
+	}
+	writeFakeJSON(w, issue.Labels)
+}
+
+func (f *fakeGitHub) handleRemoveLabel(w http.ResponseWriter, r *http.Request) {
+	issue, ok := f.decode(w, r, nil)
+	if !ok {
+		return
+	}
+	label := r.PathValue("label")
+	before := len(issue.Labels)
+	issue.Labels = slices.DeleteFunc(issue.Labels, func(l struct {
+		Name string `json:"name"`
+	}) bool {
+		return l.Name == label
+	})
+	if len(issue.Labels) == before {
+		w.WriteHeader(http.StatusNotFound)
+		writeFakeJSON(w, map[string]string{"message": "Label does not exist"})
+		return
+	}
+	writeFakeJSON(w, issue.Labels)
+}
+
+func (f *fakeGitHub) handleComment(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Body string `json:"body"`
+	}
+	issue, ok := f.decode(w, r, &body)
+	if !ok {
+		return
+	}
+	f.comments[issue.Number] = append(f.comments[issue.Number], body.Body)
+	w.WriteHeader(http.StatusCreated)
+	writeFakeJSON(w, map[string]string{"body": body.Body})
+}
+
+func (f *fakeGitHub) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		State string `json:"state"`
+	}
+	issue, ok := f.decode(w, r, &body)
+	if !ok {
+		return
+	}
+	f.closed[issue.Number] = body.State == "closed"
+	writeFakeJSON(w, issue)
+}
+
+// decode resolves the issue in the request path and decodes the request body
+// into body when it is not nil.
+func (f *fakeGitHub) decode(w http.ResponseWriter, r *http.Request, body any) (*githubIssue, bool) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	issue, ok := f.issues[number]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		writeFakeJSON(w, map[string]string{"message": "Not Found"})
+		return nil, false
+	}
+	if body != nil {
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return issue, true
+}
+
+func writeFakeJSON(w http.ResponseWriter, value any) {
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func issueNumbers(issues []Issue) []int {
+	numbers := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		numbers = append(numbers, issue.Number)
+	}
+	return numbers
+}
+
+func TestGitHubReadySkipsAssignedClaimedAndPullRequests(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	fake.add(1, "Unlabelled", nil, nil, false)
+	fake.add(2, "Ready bug", []string{"ready", "bug"}, nil, false)
+	fake.add(3, "Someone else's", []string{"ready"}, []string{"alice"}, false)
+	fake.add(4, "Ready PR", []string{"ready"}, nil, true)
+	fake.add(5, "Claimed without assignee", []string{"ready", "in-progress"}, nil, false)
+	fake.add(6, "Ready docs", []string{"ready"}, nil, false)
+
+	ready, err := gh.Ready(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Ready() error: %v", err)
+	}
+	if got, want := issueNumbers(ready), []int{2, 6}; !slices.Equal(got, want) {
+		t.Fatalf("Ready() = %v, want %v", got, want)
+	}
+	if ready[0].URL != "https://github.com/acme/api/issues/2" || strings.Join(ready[0].Labels, ",") != "ready,bug" {
+		t.Fatalf("Ready()[0] = %+v", ready[0])
+	}
+}
+
+func TestGitHubReadyFollowsPages(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	for number := 1; number <= 250; number++ {
+		fake.add(number, "Issue "+strconv.Itoa(number), []string{"ready"}, nil, false)
+	}
+	fake.add(251, "Claimed", []string{"ready", "in-progress"}, []string{"loop-bot"}, false)
+
+	ready, err := gh.Ready(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Ready() error: %v", err)
+	}
+	if len(ready) != 250 || ready[0].Number != 1 || ready[249].Number != 250 {
+		t.Fatalf("Ready() = %d issues (%v...), want all 250 in order", len(ready), issueNumbers(ready[:min(len(ready), 3)]))
+	}
+	claimed, err := gh.Claimed(context.Background())
+	if err != nil {
+		t.Fatalf("Claimed() error: %v", err)
+	}
+	if claimed == nil || claimed.Number != 251 {
+		t.Fatalf("Claimed() = %+v, want #251", claimed)
+	}
+	if got := strings.Count(strings.Join(fake.requests, "\n"), "GET /repos/acme/api/issues"); got != 4 {
+		t.Fatalf("issue list requests = %d, want 3 pages and the claimed list", got)
+	}
+}
+
+func TestGitHubReadyStopsAtLimit(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	fake.add(1, "Claimed", []string{"ready", "in-progress"}, nil, false)
+	for number := 2; number <= 250; number++ {
+		fake.add(number, "Issue "+strconv.Itoa(number), []string{"ready"}, nil, false)
+	}
+
+	ready, err := gh.Ready(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Ready() error: %v", err)
+	}
+	if got, want := issueNumbers(ready), []int{2, 3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("Ready() = %v, want %v", got, want)
+	}
+	if got := strings.Count(strings.Join(fake.requests, "\n"), "GET /repos/acme/api/issues"); got != 1 {
+		t.Fatalf("issue list requests = %d, want only the first page", got)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"`
+	if got := nextPage(link); got != "https://api.github.com/repositories/1/issues?page=2" {
+		t.Fatalf("nextPage() = %q, want page 2", got)
+	}
+	if got := nextPage(`<https://api.github.com/repositories/1/issues?page=1>; rel="prev"`); got != "" {
+		t.Fatalf("nextPage() = %q, want none on the last page", got)
+	}
+}
+
+func TestGitHubClaimCommentAndClose(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	fake.add(7, "Fix login", []string{"ready"}, nil, false)
+	ctx := context.Background()
+
+	if claimed, err := gh.Claimed(ctx); err != nil || claimed != nil {
+		t.Fatalf("Claimed() = %v, %v, want nothing claimed", claimed, err)
+	}
+	if err := gh.Claim(ctx, 7); err != nil {
+		t.Fatalf("Claim() error: %v", err)
+	}
+	claimed, err := gh.Claimed(ctx)
+	if err != nil || claimed == nil || claimed.Number != 7 {
+		t.Fatalf("Claimed() = %+v, %v, want #7", claimed, err)
+	}
+	if ready, _ := gh.Ready(ctx, 0); len(ready) != 0 {
+		t.Fatalf("Ready() = %v, want the claimed issue excluded", issueNumbers(ready))
+	}
+
+	if err := gh.Comment(ctx, 7, "Planned."); err != nil {
+		t.Fatalf("Comment() error: %v", err)
+	}
+	if err := gh.Close(ctx, 7); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	// Closing again must tolerate the missing claim label.
+	if err := gh.Close(ctx, 7); err != nil {
+		t.Fatalf("second Close() error: %v", err)
+	}
+
+	if got := fake.comments[7]; len(got) != 1 || got[0] != "Planned." {
+		t.Fatalf("comments = %q, want one comment", got)
+	}
+	if !fake.closed[7] {
+		t.Fatal("issue #7 was not closed")
+	}
+	if labels := fake.issues[7].issue().Labels; slices.Contains(labels, "in-progress") {
+		t.Fatalf("labels = %v, want the claim label removed", labels)
+	}
+	if count := strings.Count(strings.Join(fake.requests, "\n"), "GET /user"); count != 1 {
+		t.Fatalf("GET /user requested %d times, want the login cached", count)
+	}
+}
+
+func TestGitHubReportsAPIErrors(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+	gh.token = "wrong"
+
+	_, err := gh.Ready(context.Background(), 0)
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials (401)") {
+		t.Fatalf("Ready() error = %v, want the API message", err)
+	}
+}
+
+func TestApplyClaimsFirstReadyIssueAndComments(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	fake.add(3, "Add search", []string{"ready"}, nil, false)
+	fake.add(4, "Add filters", []string{"ready"}, nil, false)
+	ctx := context.Background()
+	cfg := &config.Tracker{Repo: "acme/api"}
+
+	description, err := Context(ctx, gh, cfg)
+	if err != nil {
+		t.Fatalf("Context() error: %v", err)
+	}
+	if !strings.Contains(description, "Ready issues, in the order a claim takes them:\n- #3 Add search [ready] https://github.com/acme/api/issues/3\n- #4") {
+		t.Fatalf("Context() = %q, want the ready issues", description)
+	}
+
+	issue, err := Apply(ctx, gh, &config.TrackerAction{Claim: true, Comment: "Picked up by {{.Skill}} via {{.Route}} for #{{.Issue.Number}}"}, ActionData{Skill: "issue-check", Route: "start-plan"})
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if issue == nil || issue.Number != 3 {
+		t.Fatalf("Apply() issue = %+v, want #3", issue)
+	}
+	if got := fake.comments[3]; len(got) != 1 || got[0] != "Picked up by issue-check via start-plan for #3" {
+		t.Fatalf("comments = %q", got)
+	}
+
+	// A second claim keeps the claimed issue instead of taking another.
+	if issue, err := Apply(ctx, gh, &config.TrackerAction{Claim: true}, ActionData{}); err != nil || issue.Number != 3 {
+		t.Fatalf("Apply() = %+v, %v, want #3 kept", issue, err)
+	}
+
+	description, err = Context(ctx, gh, cfg)
+	if err != nil {
+		t.Fatalf("Context() error: %v", err)
+	}
+	if !strings.Contains(description, "Claimed issue: #3 Add search\n") || !strings.Contains(description, "Body of Add search") {
+		t.Fatalf("Context() = %q, want the claimed issue", description)
+	}
+}
+
+func TestApplyWithoutReadyIssue(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+	ctx := context.Background()
+
+	if _, err := Apply(ctx, gh, &config.TrackerAction{Claim: true}, ActionData{}); err != ErrNoReadyIssue {
+		t.Fatalf("Apply(claim) error = %v, want ErrNoReadyIssue", err)
+	}
+	if _, err := Apply(ctx, gh, &config.TrackerAction{Close: true}, ActionData{}); err == nil {
+		t.Fatal("Apply(close) expected error without a claimed issue")
+	}
+}
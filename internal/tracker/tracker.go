@@ -0,0 +1,211 @@
+// Package tracker connects workflows to an issue tracker. Skills receive the
+// claimed issue, or the issues that are ready to be claimed, as context, and
+// routes claim, comment on and close issues as deterministic actions.
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+// ErrNoReadyIssue is returned when a claim finds no issue to claim.
+var ErrNoReadyIssue = errors.New("no ready issue to claim")
+
+// Issue is an open or closed issue in the tracker.
+type Issue struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body,omitempty"`
+	URL       string   `json:"url"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+// Tracker is an issue tracker that workflows pick work from. An issue is
+// claimed when it is assigned to the authenticated user and carries the claim
+// label, so the claimed issue survives restarts and resumed sessions.
+type Tracker interface {
+	// Ready lists up to limit open, unclaimed issues in the order claims
+	// take them, or all of them when limit is 0.
+	Ready(ctx context.Context, limit int) ([]Issue, error)
+	// Claimed returns the issue claimed by the authenticated user, or nil.
+	Claimed(ctx context.Context) (*Issue, error)
+	// Claim assigns the issue to the authenticated user and labels it.
+	Claim(ctx context.Context, number int) error
+	// Comment posts a comment on the issue.
+	Comment(ctx context.Context, number int, body string) error
+	// Close closes the issue and removes the claim label.
+	Close(ctx context.Context, number int) error
+}
+
+// New returns the tracker configured by cfg.
+func New(cfg *config.Tracker) (Tracker, error) {
+	switch cfg.Provider {
+	case "", config.TrackerGitHub:
+		token, err := GitHubToken(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return NewGitHub(GitHubOptions{
+			Repo:        cfg.Repo,
+			APIURL:      cfg.APIURL,
+			Token:       token,
+			ReadyLabels: cfg.Labels,
+			ClaimLabel:  cfg.EffectiveClaimLabel(),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported tracker provider %q", cfg.Provider)
+	}
+}
+
+// Cached wraps t so that a run lists the ready issues once instead of on
+// every iteration. The list is kept until a claim or close changes which
+// issues are ready.
+func Cached(t Tracker) Tracker {
+	return &cached{Tracker: t}
+}
+
+type cached struct {
+	Tracker
+	ready  []Issue
+	limit  int
+	listed bool
+}
+
+func (c *cached) Ready(ctx context.Context, limit int) ([]Issue, error) {
+	// A list shorter than its limit holds every ready issue.
+	complete := c.limit == 0 || len(c.ready) < c.limit
+	if !c.listed || (!complete && (limit == 0 || limit > c.limit)) {
+		ready, err := c.Tracker.Ready(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		c.ready, c.limit, c.listed = ready, limit, true
+	}
+	if limit > 0 && len(c.ready) > limit {
+		return c.ready[:limit:limit], nil
+	}
+	return c.ready, nil
+}
+
+func (c *cached) Claim(ctx context.Context, number int) error {
+	c.listed = false
+	return c.Tracker.Claim(ctx, number)
+}
+
+func (c *cached) Close(ctx context.Context, number int) error {
+	c.listed = false
+	return c.Tracker.Close(ctx, number)
+}
+
+// Context describes the tracker state for a skill: the claimed issue with its
+// body, or the first limit ready issues when none is claimed.
+func Context(ctx context.Context, t Tracker, cfg *config.Tracker) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Issue tracker: %s\n", cfg.Repo)
+
+	claimed, err := t.Claimed(ctx)
+	if err != nil {
+		return "", err
+	}
+	if claimed != nil {
+		fmt.Fprintf(&b, "Claimed issue: #%d %s\n", claimed.Number, claimed.Title)
+		fmt.Fprintf(&b, "URL: %s\n", claimed.URL)
+		if len(claimed.Labels) > 0 {
+			fmt.Fprintf(&b, "Labels: %s\n", strings.Join(claimed.Labels, ", "))
+		}
+		if body := strings.TrimSpace(claimed.Body); body != "" {
+			b.WriteString("\n")
+			b.WriteString(body)
+			b.WriteString("\n")
+		}
+		return b.String(), nil
+	}
+
+	// One issue past the limit tells whether more are ready.
+	limit := cfg.EffectiveReadyLimit()
+	ready, err := t.Ready(ctx, limit+1)
+	if err != nil {
+		return "", err
+	}
+	if len(ready) == 0 {
+		b.WriteString("No issue is claimed and no issue is ready.\n")
+		return b.String(), nil
+	}
+	b.WriteString("No issue is claimed. Ready issues, in the order a claim takes them:\n")
+	for i, issue := range ready {
+		if i == limit {
+			b.WriteString("... and more\n")
+			break
+		}
+		fmt.Fprintf(&b, "- #%d %s", issue.Number, issue.Title)
+		if len(issue.Labels) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(issue.Labels, ", "))
+		}
+		fmt.Fprintf(&b, " %s\n", issue.URL)
+	}
+	return b.String(), nil
+}
+
+// ActionData is available to comment templates.
+type ActionData struct {
+	Skill  string
+	Route  string
+	Reason string
+	Output string
+	Issue  *Issue
+}
+
+// Apply runs the tracker actions of a selected route: it claims the first
+// ready issue unless one is already claimed, comments on the claimed issue and
+// closes it. It returns the issue the actions applied to, if any.
+func Apply(ctx context.Context, t Tracker, action *config.TrackerAction, data ActionData) (*Issue, error) {
+	issue, err := t.Claimed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if action.Claim && issue == nil {
+		ready, err := t.Ready(ctx, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(ready) == 0 {
+			return nil, ErrNoReadyIssue
+		}
+		issue = &ready[0]
+		if err := t.Claim(ctx, issue.Number); err != nil {
+			return nil, fmt.Errorf("claim #%d: %w", issue.Number, err)
+		}
+	}
+
+	if (action.Comment != "" || action.Close) && issue == nil {
+		return nil, fmt.Errorf("no claimed issue to comment on or close")
+	}
+
+	tmpl, err := action.CommentTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil {
+		data.Issue = issue
+		var body strings.Builder
+		if err := tmpl.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("render comment: %w", err)
+		}
+		if err := t.Comment(ctx, issue.Number, body.String()); err != nil {
+			return nil, fmt.Errorf("comment on #%d: %w", issue.Number, err)
+		}
+	}
+
+	if action.Close {
+		if err := t.Close(ctx, issue.Number); err != nil {
+			return nil, fmt.Errorf("close #%d: %w", issue.Number, err)
+		}
+	}
+	return issue, nil
+}
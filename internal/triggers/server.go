@@ -0,0 +1,245 @@
+// Package triggers serves the HTTP endpoint used by CI systems and git hooks
+// to start workflows that opt in with trigger.webhook.
+package triggers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+// maxPayloadBytes bounds the size of a webhook request body.
+const maxPayloadBytes = 1 << 20
+
+// Workflow is a webhook-enabled workflow served by the trigger server.
+type Workflow struct {
+	Name       string
+	ConfigPath string
+	Config     *config.Config
+	prompt     *template.Template
+}
+
+type handler struct {
+	token     string
+	workflows map[string]*Workflow
+	start     func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error)
+}
+
+type workflowDTO struct {
+	Name       string `json:"name"`
+	ConfigPath string `json:"configPath"`
+}
+
+type workflowsResponse struct {
+	Workflows []workflowDTO `json:"workflows"`
+}
+
+type triggerResponse struct {
+	Workflow  string `json:"workflow"`
+	SessionID string `json:"sessionId"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type promptData struct {
+	Workflow string
+	Payload  any
+}
+
+// LoadWorkflows loads the given configs and returns those that enable
+// trigger.webhook, sorted by workflow name.
+func LoadWorkflows(configPaths []string) ([]*Workflow, error) {
+	return loadWorkflows(configPaths, nil)
+}
+
+// DiscoverWorkflows loads the configs config.Discover finds under repoRoot
+// like LoadWorkflows. A discovered config that fails to load, such as the
+// partial base of an extends, is reported to warn and skipped.
+func DiscoverWorkflows(repoRoot string, warn io.Writer) ([]*Workflow, error) {
+	configPaths, err := config.Discover(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return loadWorkflows(configPaths, warn)
+}
+
+// loadWorkflows loads the webhook-enabled workflows of configPaths. With warn
+// set, configs that fail to load are reported to it instead of failing.
+func loadWorkflows(configPaths []string, warn io.Writer) ([]*Workflow, error) {
+	var workflows []*Workflow
+	seen := map[string]string{}
+	for _, cfgPath := range configPaths {
+		workflow, err := loadWorkflow(cfgPath)
+		if err != nil {
+			if warn == nil {
+				return nil, fmt.Errorf("%s: %w", cfgPath, err)
+			}
+			fmt.Fprintf(warn, "warn: skipping %s: %v\n", cfgPath, err)
+			continue
+		}
+		if workflow == nil {
+			continue
+		}
+
+		if previous, ok := seen[workflow.Name]; ok {
+			return nil, fmt.Errorf("workflow name %q is used by both %s and %s", workflow.Name, previous, cfgPath)
+		}
+		seen[workflow.Name] = cfgPath
+		workflows = append(workflows, workflow)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].Name < workflows[j].Name
+	})
+	return workflows, nil
+}
+
+// loadWorkflow loads the config at cfgPath. It returns nil when the config
+// does not enable trigger.webhook.
+func loadWorkflow(cfgPath string) (*Workflow, error) {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.WebhookEnabled() {
+		return nil, nil
+	}
+	prompt, err := cfg.WebhookPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return &Workflow{Name: cfg.EffectiveName(cfgPath), ConfigPath: cfgPath, Config: cfg, prompt: prompt}, nil
+}
+
+// NewHandler returns the trigger server. Every request must carry
+// "Authorization: Bearer <token>".
+func NewHandler(token string, workflows []*Workflow) (http.Handler, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("a trigger token is required")
+	}
+
+	h := &handler{
+		token:     token,
+		workflows: make(map[string]*Workflow, len(workflows)),
+		start:     launch.Once,
+	}
+	for _, workflow := range workflows {
+		h.workflows[workflow.Name] = workflow
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /workflows", h.authenticated(h.handleListWorkflows))
+	mux.HandleFunc("POST /workflows/{name}", h.authenticated(h.handleTrigger))
+
+	return mux, nil
+}
+
+func (h *handler) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="skill-loop"`)
+			writeErrorMessage(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *handler) handleListWorkflows(w http.ResponseWriter, r *http.Request) {
+	response := workflowsResponse{Workflows: make([]workflowDTO, 0, len(h.workflows))}
+	for _, workflow := range h.workflows {
+		response.Workflows = append(response.Workflows, workflowDTO{Name: workflow.Name, ConfigPath: workflow.ConfigPath})
+	}
+	sort.Slice(response.Workflows, func(i, j int) bool {
+		return response.Workflows[i].Name < response.Workflows[j].Name
+	})
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	workflow, ok := h.workflows[r.PathValue("name")]
+	if !ok {
+		writeErrorMessage(w, http.StatusNotFound, "workflow not found or webhook trigger not enabled")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErrorMessage(w, http.StatusRequestEntityTooLarge, "payload too large")
+			return
+		}
+		writeErrorMessage(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var payload any
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeErrorMessage(w, http.StatusBadRequest, "request body must be JSON")
+			return
+		}
+	}
+
+	prompt, err := renderPrompt(workflow, payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	meta, err := h.start(workflow.Config, workflow.ConfigPath, launch.Options{Prompt: prompt})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, triggerResponse{Workflow: workflow.Name, SessionID: meta.ID})
+}
+
+func renderPrompt(workflow *Workflow, payload any) (string, error) {
+	if workflow.prompt == nil {
+		if payload == nil {
+			return "", nil
+		}
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("format payload: %w", err)
+		}
+		return "Webhook payload:\n" + string(data), nil
+	}
+
+	var b strings.Builder
+	if err := workflow.prompt.Execute(&b, promptData{Workflow: workflow.Name, Payload: payload}); err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeErrorMessage(w, status, err.Error())
+}
+
+func writeErrorMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
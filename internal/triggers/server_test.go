@@ -0,0 +1,178 @@
+package triggers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/launch"
+	"github.com/takumiyoshikawa/skill-loop/internal/session"
+)
+
+const webhookConfig = `name: ci-fix
+default_entrypoint: impl
+trigger:
+  webhook:
+    prompt: "Fix the failing build on {{ .Payload.ref }} ({{ .Workflow }})."
+skills:
+  impl:
+    next:
+      - id: finish
+        done: true
+`
+
+func TestLoadWorkflowsKeepsWebhookEnabledConfigs(t *testing.T) {
+	dir := t.TempDir()
+	webhookPath := writeConfig(t, dir, "skill-loop-ci.yml", webhookConfig)
+	writeConfig(t, dir, "skill-loop.yml", "default_entrypoint: impl\nskills:\n  impl:\n    next:\n      - id: finish\n        done: true\n")
+
+	paths, err := config.Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Discover() = %v, want 2 configs", paths)
+	}
+
+	workflows, err := LoadWorkflows(paths)
+	if err != nil {
+		t.Fatalf("LoadWorkflows() error: %v", err)
+	}
+	if len(workflows) != 1 || workflows[0].Name != "ci-fix" || workflows[0].ConfigPath != webhookPath {
+		t.Fatalf("workflows = %+v, want only ci-fix", workflows)
+	}
+}
+
+func TestDiscoverWorkflowsSkipsBrokenConfigs(t *testing.T) {
+	dir := t.TempDir()
+	webhookPath := writeConfig(t, dir, "skill-loop-ci.yml", webhookConfig)
+	brokenPath := writeConfig(t, dir, "skill-loop-base.yml", "skills:\n  impl:\n    agent:\n      runtime: claude\n")
+
+	var warn strings.Builder
+	workflows, err := DiscoverWorkflows(dir, &warn)
+	if err != nil {
+		t.Fatalf("DiscoverWorkflows() error: %v", err)
+	}
+	if len(workflows) != 1 || workflows[0].ConfigPath != webhookPath {
+		t.Fatalf("workflows = %+v, want only ci-fix", workflows)
+	}
+	if !strings.Contains(warn.String(), "warn: skipping "+brokenPath+": ") {
+		t.Fatalf("warnings = %q, want the broken config skipped", warn.String())
+	}
+
+	if _, err := LoadWorkflows([]string{webhookPath, brokenPath}); err == nil || !strings.HasPrefix(err.Error(), brokenPath+": ") {
+		t.Fatalf("LoadWorkflows() error = %v, want the broken config to fail", err)
+	}
+}
+
+func TestTriggerStartsWorkflowWithRenderedPrompt(t *testing.T) {
+	h := newTestHandler(t)
+	var gotOpts launch.Options
+	h.start = func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error) {
+		gotOpts = opts
+		return &session.Metadata{ID: "20260307T090000Z-ab"}, nil
+	}
+
+	rec := serveTrigger(h, "ci-fix", "secret", `{"ref":"main"}`)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	if gotOpts.Prompt != "Fix the failing build on main (ci-fix)." {
+		t.Fatalf("prompt = %q, want rendered template", gotOpts.Prompt)
+	}
+
+	var got triggerResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.SessionID != "20260307T090000Z-ab" {
+		t.Fatalf("sessionId = %q, want started session", got.SessionID)
+	}
+}
+
+func TestTriggerRejectsRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		token    string
+		body     string
+		want     int
+	}{
+		{name: "missing token", workflow: "ci-fix", body: `{"ref":"main"}`, want: http.StatusUnauthorized},
+		{name: "wrong token", workflow: "ci-fix", token: "guess", body: `{"ref":"main"}`, want: http.StatusUnauthorized},
+		{name: "unknown workflow", workflow: "other", token: "secret", body: `{}`, want: http.StatusNotFound},
+		{name: "invalid json", workflow: "ci-fix", token: "secret", body: `ref=main`, want: http.StatusBadRequest},
+		{name: "missing template key", workflow: "ci-fix", token: "secret", body: `{"branch":"main"}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			h.start = func(cfg *config.Config, cfgPath string, opts launch.Options) (*session.Metadata, error) {
+				t.Fatal("workflow should not start")
+				return nil, nil
+			}
+
+			rec := serveTrigger(h, tt.workflow, tt.token, tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRenderPromptDefaultsToPayload(t *testing.T) {
+	got, err := renderPrompt(&Workflow{Name: "plain"}, map[string]any{"ref": "main"})
+	if err != nil {
+		t.Fatalf("renderPrompt() error: %v", err)
+	}
+	want := "Webhook payload:\n{\n  \"ref\": \"main\"\n}"
+	if got != want {
+		t.Fatalf("renderPrompt() = %q, want %q", got, want)
+	}
+}
+
+func TestNewHandlerRequiresToken(t *testing.T) {
+	if _, err := NewHandler(" ", nil); err == nil {
+		t.Fatal("NewHandler() error = nil, want token error")
+	}
+}
+
+func newTestHandler(t *testing.T) *handler {
+	t.Helper()
+
+	workflows, err := LoadWorkflows([]string{writeConfig(t, t.TempDir(), "skill-loop.yml", webhookConfig)})
+	if err != nil {
+		t.Fatalf("LoadWorkflows() error: %v", err)
+	}
+	return &handler{
+		token:     "secret",
+		workflows: map[string]*Workflow{workflows[0].Name: workflows[0]},
+	}
+}
+
+func serveTrigger(h *handler, workflow string, token string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/workflows/"+workflow, strings.NewReader(body))
+	req.SetPathValue("name", workflow)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.authenticated(h.handleTrigger)(rec, req)
+	return rec
+}
+
+func writeConfig(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	return path
+}
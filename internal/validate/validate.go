@@ -0,0 +1,449 @@
+// Package validate runs static checks on a workflow beyond what config.Load
+// enforces: the shape of the skill/route graph and the files and binaries the
+// workflow needs at run time.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+	"github.com/takumiyoshikawa/skill-loop/internal/executor"
+)
+
+// Severities. Errors make a workflow invalid; warnings point at something
+// that is likely, but not certainly, a mistake.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Checks that produce findings.
+const (
+	CheckConfig         = "config"
+	CheckUnreachable    = "unreachable"
+	CheckNoDone         = "no-done-path"
+	CheckEndlessCycle   = "cycle-without-exit"
+	CheckSkillFile      = "missing-skill-file"
+	CheckRuntimeMissing = "runtime-not-found"
+)
+
+// SkillsDir is where skills are looked up, relative to the config directory
+// or one of its parents up to the repository root.
+const SkillsDir = ".agents/skills"
+
+// Finding is one problem found in a workflow. Skill is empty for findings
+// about the workflow as a whole. Config findings carry the file, line and
+// column of the problem when they are known.
+type Finding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Skill    string `json:"skill,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Location returns the position of the finding as file:line:column, or ""
+// when it has none.
+func (f Finding) Location() string {
+	switch {
+	case f.File == "":
+		return ""
+	case f.Line > 0 && f.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	default:
+		return f.File
+	}
+}
+
+// Report holds the findings for one config file, ordered by check and skill.
+type Report struct {
+	Config   string    `json:"config"`
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+}
+
+// Errors returns the number of findings with error severity.
+func (r *Report) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of findings with warning severity.
+func (r *Report) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+func (r *Report) count(severity string) int {
+	n := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Options replace the environment lookups made by File, for tests.
+type Options struct {
+	LookPath func(file string) (string, error)
+}
+
+// File loads the config at path and checks it. A config that fails to load
+// yields a config finding for each problem, in source order, and no further
+// checks.
+func File(path string, opts Options) *Report {
+	report := &Report{Config: path, Findings: []Finding{}}
+	cfg, err := config.Load(path)
+	if err != nil {
+		for _, e := range config.AsErrors(err, path) {
+			report.Findings = append(report.Findings, Finding{
+				Severity: SeverityError,
+				Check:    CheckConfig,
+				File:     e.File,
+				Line:     e.Line,
+				Column:   e.Column,
+				Message:  e.Message,
+			})
+		}
+		return report
+	}
+
+	report.Findings = append(report.Findings, Graph(cfg)...)
+	report.Findings = append(report.Findings, SkillFiles(cfg, filepath.Dir(path))...)
+	report.Findings = append(report.Findings, Runtimes(cfg, opts.LookPath)...)
+	report.Valid = report.Errors() == 0
+	return report
+}
+
+// Graph checks the skill/route graph of a loaded config starting at its
+// default entrypoint. It reports skills that can never run, skills from which
+// no done route can be reached, and cycles that have no route out of them.
+// A skill in an endless cycle is reported once, for the cycle.
+func Graph(cfg *config.Config) []Finding {
+	names := skillNames(cfg)
+	outgoing := make(map[string][]string, len(names))
+	hasDone := make(map[string]bool, len(names))
+	for _, name := range names {
+		for _, route := range cfg.Skills[name].Next {
+			if route.Done {
+				hasDone[name] = true
+				continue
+			}
+			outgoing[name] = append(outgoing[name], route.Skill)
+		}
+	}
+
+	reachable := reach([]string{cfg.DefaultEntrypoint}, outgoing)
+
+	var findings []Finding
+	for _, name := range names {
+		if !reachable[name] {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Check:    CheckUnreachable,
+				Skill:    name,
+				Message:  fmt.Sprintf("skill %q cannot be reached from default_entrypoint %q", name, cfg.DefaultEntrypoint),
+			})
+		}
+	}
+
+	incoming := make(map[string][]string, len(names))
+	var doneSkills []string
+	for _, name := range names {
+		for _, next := range outgoing[name] {
+			incoming[next] = append(incoming[next], name)
+		}
+		if hasDone[name] {
+			doneSkills = append(doneSkills, name)
+		}
+	}
+	finishes := reach(doneSkills, incoming)
+
+	inEndlessCycle := make(map[string]bool)
+	for _, component := range components(names, outgoing) {
+		if !isCycle(component, outgoing) || !reachable[component[0]] || hasExit(component, outgoing, hasDone) {
+			continue
+		}
+		for _, name := range component {
+			inEndlessCycle[name] = true
+		}
+		message := fmt.Sprintf("skills %s loop with no route out of the cycle; only max_iterations stops them", quoteAll(component))
+		if len(component) == 1 {
+			message = fmt.Sprintf("skill %q only routes to itself; only max_iterations stops it", component[0])
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Check:    CheckEndlessCycle,
+			Skill:    component[0],
+			Message:  message,
+		})
+	}
+
+	if len(doneSkills) == 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Check:    CheckNoDone,
+			Message:  "no skill has a done route; the workflow can only stop at max_iterations",
+		})
+	} else {
+		for _, name := range names {
+			if reachable[name] && !finishes[name] && !inEndlessCycle[name] {
+				findings = append(findings, Finding{
+					Severity: SeverityError,
+					Check:    CheckNoDone,
+					Skill:    name,
+					Message:  fmt.Sprintf("skill %q has no path to a done route", name),
+				})
+			}
+		}
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// SkillFiles reports skills without a SKILL.md under SkillsDir. Skills are
+// looked up in dir and then in each parent directory up to and including the
+// repository root, the first directory that contains .git.
+func SkillFiles(cfg *config.Config, dir string) []Finding {
+	roots := skillRoots(dir)
+
+	var findings []Finding
+	for _, name := range skillNames(cfg) {
+		if hasSkillFile(roots, name) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Check:    CheckSkillFile,
+			Skill:    name,
+			Message:  fmt.Sprintf("skill %q has no %s", name, filepath.ToSlash(filepath.Join(SkillsDir, name, "SKILL.md"))),
+		})
+	}
+	return findings
+}
+
+// Runtimes reports agent runtimes whose CLI is not on PATH. The router is
+// only checked when it is configured. A missing binary is a warning because
+// validation often runs on machines, such as CI, that never run agents.
+func Runtimes(cfg *config.Config, lookPath func(string) (string, error)) []Finding {
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+
+	users := make(map[string][]string)
+	for _, name := range skillNames(cfg) {
+		runtime := runtimeName(cfg.Skills[name].Agent.Runtime)
+		users[runtime] = append(users[runtime], fmt.Sprintf("skill %q", name))
+	}
+	if cfg.Router.Runtime != "" || cfg.Router.Model != "" || len(cfg.Router.Args) > 0 {
+		runtime := runtimeName(cfg.Router.Runtime)
+		users[runtime] = append(users[runtime], "router")
+	}
+
+	runtimes := make([]string, 0, len(users))
+	for runtime := range users {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	var findings []Finding
+	for _, runtime := range runtimes {
+		binary, err := executor.RuntimeBinary(runtime)
+		if err != nil {
+			continue
+		}
+		if _, err := lookPath(binary); err == nil {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Check:    CheckRuntimeMissing,
+			Message:  fmt.Sprintf("runtime %s needs %q on PATH (used by %s)", runtime, binary, strings.Join(users[runtime], ", ")),
+		})
+	}
+	return findings
+}
+
+func skillNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Skills))
+	for name := range cfg.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runtimeName(runtime string) string {
+	if runtime == "" {
+		return "claude"
+	}
+	return runtime
+}
+
+// reach returns every node reachable from roots, including the roots.
+func reach(roots []string, edges map[string][]string) map[string]bool {
+	seen := make(map[string]bool)
+	queue := append([]string(nil), roots...)
+	for _, root := range roots {
+		seen[root] = true
+	}
+	for ; len(queue) > 0; queue = queue[1:] {
+		for _, next := range edges[queue[0]] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
+
+// components returns the strongly connected components of the graph using
+// Tarjan's algorithm. Each component is sorted by name.
+func components(names []string, edges map[string][]string) [][]string {
+	index := make(map[string]int, len(names))
+	low := make(map[string]int, len(names))
+	onStack := make(map[string]bool, len(names))
+	var stack []string
+	var result [][]string
+
+	var connect func(string)
+	connect = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, next := range edges[name] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				low[name] = min(low[name], low[next])
+			} else if onStack[next] {
+				low[name] = min(low[name], index[next])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		sort.Strings(component)
+		result = append(result, component)
+	}
+
+	for _, name := range names {
+		if _, visited := index[name]; !visited {
+			connect(name)
+		}
+	}
+	return result
+}
+
+// isCycle reports whether a component loops: it has more than one skill or a
+// skill that routes to itself.
+func isCycle(component []string, edges map[string][]string) bool {
+	if len(component) > 1 {
+		return true
+	}
+	for _, next := range edges[component[0]] {
+		if next == component[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExit reports whether any skill in the component has a done route or a
+// route to a skill outside the component.
+func hasExit(component []string, edges map[string][]string, hasDone map[string]bool) bool {
+	members := make(map[string]bool, len(component))
+	for _, name := range component {
+		members[name] = true
+	}
+	for _, name := range component {
+		if hasDone[name] {
+			return true
+		}
+		for _, next := range edges[name] {
+			if !members[next] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func skillRoots(dir string) []string {
+	roots := []string{dir}
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return roots
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Not inside a repository: only the config directory counts.
+			return roots[:1]
+		}
+		current = parent
+		roots = append(roots, current)
+	}
+}
+
+func hasSkillFile(roots []string, name string) bool {
+	for _, root := range roots {
+		info, err := os.Stat(filepath.Join(root, SkillsDir, name, "SKILL.md"))
+		if err == nil && !info.IsDir() {
+			return true
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			// Unreadable paths are left for the agent to report.
+			return true
+		}
+	}
+	return false
+}
+
+var checkOrder = map[string]int{
+	CheckConfig:         0,
+	CheckEndlessCycle:   1,
+	CheckNoDone:         2,
+	CheckUnreachable:    3,
+	CheckSkillFile:      4,
+	CheckRuntimeMissing: 5,
+}
+
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Check != findings[j].Check {
+			return checkOrder[findings[i].Check] < checkOrder[findings[j].Check]
+		}
+		return findings[i].Skill < findings[j].Skill
+	})
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,177 @@
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takumiyoshikawa/skill-loop/internal/config"
+)
+
+func summarize(findings []Finding) string {
+	var parts []string
+	for _, finding := range findings {
+		parts = append(parts, finding.Severity+":"+finding.Check+":"+finding.Skill)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestGraphReportsStructuralProblems(t *testing.T) {
+	cfg := &config.Config{
+		DefaultEntrypoint: "plan",
+		Skills: map[string]config.Skill{
+			"plan": {Next: []config.Route{
+				{ID: "implement", Criteria: "Plan is ready", Skill: "implement"},
+				{ID: "explore", Criteria: "Needs research", Skill: "explore"},
+				{ID: "stuck", Criteria: "Cannot plan", Skill: "triage"},
+			}},
+			"implement": {Next: []config.Route{
+				{ID: "approve", Criteria: "Tests pass", Done: true},
+				{ID: "retry", Criteria: "Tests fail", Skill: "implement"},
+			}},
+			"explore":  {Next: []config.Route{{ID: "dig", Skill: "research"}}},
+			"research": {Next: []config.Route{{ID: "back", Skill: "explore"}}},
+			"triage":   {Next: []config.Route{{ID: "wait", Skill: "escalate", Blocked: true}}},
+			"escalate": {Next: []config.Route{{ID: "again", Skill: "escalate"}}},
+			"orphan":   {Next: []config.Route{{ID: "finish", Done: true}}},
+		},
+	}
+
+	got := summarize(Graph(cfg))
+	want := "error:cycle-without-exit:escalate,error:cycle-without-exit:explore,error:no-done-path:triage,warning:unreachable:orphan"
+	if got != want {
+		t.Fatalf("Graph() = %q, want %q", got, want)
+	}
+}
+
+func TestGraphReportsWorkflowWithoutDone(t *testing.T) {
+	cfg := &config.Config{
+		DefaultEntrypoint: "a",
+		Skills: map[string]config.Skill{
+			"a": {Next: []config.Route{{ID: "next", Skill: "b"}}},
+			"b": {Next: []config.Route{{ID: "next", Skill: "c"}}},
+			"c": {Next: []config.Route{{ID: "next", Skill: "b"}}},
+		},
+	}
+
+	findings := Graph(cfg)
+	got := summarize(findings)
+	want := "error:cycle-without-exit:b,error:no-done-path:"
+	if got != want {
+		t.Fatalf("Graph() = %q, want %q", got, want)
+	}
+	if !strings.Contains(findings[0].Message, `"b", "c"`) {
+		t.Fatalf("cycle message = %q, want both skills", findings[0].Message)
+	}
+}
+
+func TestGraphAcceptsLoopWithExit(t *testing.T) {
+	cfg := &config.Config{
+		DefaultEntrypoint: "impl",
+		Skills: map[string]config.Skill{
+			"impl": {Next: []config.Route{{ID: "review", Skill: "review"}}},
+			"review": {Next: []config.Route{
+				{ID: "approve", Criteria: "Done", Done: true},
+				{ID: "rework", Criteria: "Needs work", Skill: "impl"},
+			}},
+		},
+	}
+
+	if got := Graph(cfg); len(got) != 0 {
+		t.Fatalf("Graph() = %q, want no findings", summarize(got))
+	}
+}
+
+func TestSkillFilesSearchesUpToRepositoryRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "workflows")
+	writeSkill(t, root, "plan")
+	writeSkill(t, dir, "review")
+
+	cfg := &config.Config{Skills: map[string]config.Skill{
+		"plan":   {},
+		"review": {},
+		"deploy": {},
+	}}
+
+	findings := SkillFiles(cfg, dir)
+	if got, want := summarize(findings), "error:missing-skill-file:deploy"; got != want {
+		t.Fatalf("SkillFiles() = %q, want %q", got, want)
+	}
+	if !strings.Contains(findings[0].Message, ".agents/skills/deploy/SKILL.md") {
+		t.Fatalf("message = %q, want the expected path", findings[0].Message)
+	}
+}
+
+func TestRuntimesReportsMissingBinaries(t *testing.T) {
+	cfg := &config.Config{
+		Router: config.Agent{Runtime: "codex"},
+		Skills: map[string]config.Skill{
+			"plan":   {},
+			"edit":   {Agent: config.Agent{Runtime: "cursor-cli"}},
+			"review": {Agent: config.Agent{Runtime: "codex"}},
+		},
+	}
+	var looked []string
+	lookPath := func(file string) (string, error) {
+		looked = append(looked, file)
+		if file == "claude" {
+			return "/usr/bin/claude", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	findings := Runtimes(cfg, lookPath)
+	if got, want := strings.Join(looked, ","), "claude,codex,agent"; got != want {
+		t.Fatalf("looked up %q, want %q", got, want)
+	}
+	if got, want := summarize(findings), "warning:runtime-not-found:,warning:runtime-not-found:"; got != want {
+		t.Fatalf("Runtimes() = %q, want %q", got, want)
+	}
+	if got, want := findings[0].Message, `runtime codex needs "codex" on PATH (used by skill "review", router)`; got != want {
+		t.Fatalf("message = %q, want %q", got, want)
+	}
+}
+
+func TestFileReportsLoadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skill-loop.yml")
+	if err := os.WriteFile(path, []byte("max_restarts: -1\nskills: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report := File(path, Options{})
+	if report.Valid {
+		t.Fatal("File() valid = true, want false")
+	}
+	if got, want := summarize(report.Findings), "error:config:,error:config:,error:config:"; got != want {
+		t.Fatalf("File() = %q, want %q", got, want)
+	}
+	var locations []string
+	for _, finding := range report.Findings {
+		locations = append(locations, finding.Location()+" "+finding.Message)
+	}
+	want := []string{
+		path + " default_entrypoint is required",
+		path + ":1:1 max_restarts must be >= 0",
+		path + ":2:1 at least one skill is required",
+	}
+	if strings.Join(locations, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("File() findings =\n%s\nwant\n%s", strings.Join(locations, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func writeSkill(t *testing.T, root string, name string) {
+	t.Helper()
+	dir := filepath.Join(root, SkillsDir, name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("# "+name+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}